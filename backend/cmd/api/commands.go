@@ -0,0 +1,558 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/rod1kutzyy/OnTrack/internal/config"
+	"github.com/rod1kutzyy/OnTrack/internal/domain"
+	"github.com/rod1kutzyy/OnTrack/internal/event"
+	"github.com/rod1kutzyy/OnTrack/internal/infrastructure/database"
+	"github.com/rod1kutzyy/OnTrack/internal/logger"
+	"github.com/rod1kutzyy/OnTrack/internal/repository"
+	"github.com/rod1kutzyy/OnTrack/internal/repository/memory"
+	"github.com/rod1kutzyy/OnTrack/internal/repository/sqlstore"
+	"github.com/rod1kutzyy/OnTrack/internal/usecase"
+)
+
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+// Set at build time with -ldflags "-X main.version=... -X main.commit=... -X main.buildDate=...".
+var (
+	version   = "dev"
+	commit    = ""
+	buildDate = ""
+)
+
+type command struct {
+	flags   *flag.FlagSet
+	summary string
+	// skipConfig commands run without configuration, logger or database.
+	skipConfig bool
+	// checksConfig commands get the configuration before it is validated and
+	// report problems themselves.
+	checksConfig bool
+	// needsDB commands get a connected database, all others a nil one.
+	needsDB bool
+	// inMemory commands also run with DB_DRIVER=memory, where they get a nil
+	// database. The others need one that outlives the process.
+	inMemory bool
+	// logsStdout keeps logs on stdout; other commands log to stderr so that
+	// their output can be piped.
+	logsStdout bool
+	run        func(ctx context.Context, cfg *config.Config, db *database.DB) error
+}
+
+type commandEntry struct {
+	name       string
+	newCommand func() *command
+}
+
+// commands lists the commands in the order they are shown in the help output.
+var commands = []commandEntry{
+	{"serve", newServeCommand},
+	{"migrate up", newMigrateUpCommand},
+	{"migrate down", newMigrateDownCommand},
+	{"migrate status", newMigrateStatusCommand},
+	{"seed", newSeedCommand},
+	{"export", newExportCommand},
+	{"import", newImportCommand},
+	{"config validate", newConfigValidateCommand},
+	{"config print", newConfigPrintCommand},
+	{"version", newVersionCommand},
+}
+
+const defaultCommand = "serve"
+
+// runCommand runs the command named by args and returns the process exit code.
+// Without arguments the server is started.
+func runCommand(args []string) int {
+	if len(args) == 0 {
+		args = []string{defaultCommand}
+	}
+
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		if len(args) > 1 {
+			return runCommand(append(args[1:], "-h"))
+		}
+		printUsage(os.Stdout)
+		return exitOK
+	}
+
+	name, cmd, args := lookupCommand(args)
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		printUsage(os.Stderr)
+		return exitUsage
+	}
+
+	var configOptions config.Options
+	if !cmd.skipConfig {
+		cmd.flags.StringVar(&configOptions.File, "config", "", "YAML or TOML config `file` (default $CONFIG_FILE)")
+		cmd.flags.Func("set", "override a setting as `key=value`, e.g. server.port=8081; repeatable", func(value string) error {
+			configOptions.Overrides = append(configOptions.Overrides, value)
+			return nil
+		})
+	}
+
+	cmd.flags.Usage = func() {
+		fmt.Fprintf(cmd.flags.Output(), "Usage: api %s [flags]\n\n%s\n", name, cmd.summary)
+		if hasFlags(cmd.flags) {
+			fmt.Fprintln(cmd.flags.Output(), "\nFlags:")
+			cmd.flags.PrintDefaults()
+		}
+	}
+
+	if err := cmd.flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	if cmd.flags.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "unexpected arguments: %v\n\n", cmd.flags.Args())
+		cmd.flags.Usage()
+		return exitUsage
+	}
+
+	if cmd.skipConfig {
+		if err := cmd.run(context.Background(), nil, nil); err != nil {
+			fmt.Fprintf(os.Stderr, "Command %s failed: %v\n", name, err)
+			return exitError
+		}
+		return exitOK
+	}
+
+	cfg, err := config.Load(configOptions)
+	if err == nil && !cmd.checksConfig {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		printConfigProblems(err)
+		return exitError
+	}
+
+	logConfig := cfg.Logger
+	if !cmd.logsStdout && logConfig.Output == config.LogOutputStdout {
+		logConfig.Output = config.LogOutputStderr
+	}
+
+	// Commands without database access print their own results; only warnings
+	// and errors are worth logging for them.
+	if !cmd.needsDB {
+		logConfig.Level = "warn"
+		logConfig.PackageLevels = nil
+	}
+
+	if err := logger.Configure(logConfig); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
+		return exitError
+	}
+	defer logger.Close()
+
+	var db *database.DB
+	if cmd.needsDB && cfg.Database.Driver == config.DriverMemory && !cmd.inMemory {
+		logger.Logger.Errorf("Command %s needs a database, but DB_DRIVER is %s", name, config.DriverMemory)
+		return exitError
+	}
+
+	if cmd.needsDB && cfg.Database.Driver != config.DriverMemory {
+		db, err = database.New(cfg)
+		if err != nil {
+			logger.Logger.Errorf("Failed to initialize database: %v", err)
+			return exitError
+		}
+		defer db.Close()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := cmd.run(ctx, cfg, db); err != nil {
+		logger.Logger.WithError(err).Errorf("Command %s failed", name)
+		return exitError
+	}
+
+	return exitOK
+}
+
+// lookupCommand resolves one- and two-word command names such as "seed" and
+// "migrate up" and returns the remaining arguments.
+func lookupCommand(args []string) (string, *command, []string) {
+	for _, entry := range commands {
+		words := strings.Fields(entry.name)
+		if len(args) >= len(words) && slices.Equal(args[:len(words)], words) {
+			return entry.name, entry.newCommand(), args[len(words):]
+		}
+	}
+
+	if len(args) > 1 && !strings.HasPrefix(args[1], "-") {
+		return args[0] + " " + args[1], nil, nil
+	}
+
+	return args[0], nil, nil
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: api [command] [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	for _, entry := range commands {
+		fmt.Fprintf(tw, "  %s\t%s\n", entry.name, entry.newCommand().summary)
+	}
+	tw.Flush()
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Without a command, %q is run.\n", defaultCommand)
+	fmt.Fprintln(w, `Run "api help <command>" for the flags of a command.`)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Exit codes: 0 success, 1 failure, 2 invalid usage.")
+}
+
+func hasFlags(flags *flag.FlagSet) bool {
+	found := false
+	flags.VisitAll(func(*flag.Flag) {
+		found = true
+	})
+
+	return found
+}
+
+// newTodoRepository returns the repository for the configured driver and the
+// transactor for it. db is nil for the memory driver.
+func newTodoRepository(cfg *config.Config, db *database.DB) (repository.TodoRepository, repository.Transactor) {
+	if cfg.Database.Driver == config.DriverMemory {
+		repo := memory.NewTodoRepository()
+		return repo, memory.NewTransactor(repo)
+	}
+
+	return sqlstore.NewTodoRepository(db.GetDB()), sqlstore.NewTransactor(db.GetDB())
+}
+
+func newBackupUseCase(db *database.DB) usecase.BackupUseCase {
+	return usecase.NewBackupUseCase(sqlstore.NewTodoRepository(db.GetDB()), sqlstore.NewTransactor(db.GetDB()))
+}
+
+func newExportCommand() *command {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	output := flags.String("o", "-", "backup file to write, - for stdout")
+
+	run := func(ctx context.Context, cfg *config.Config, db *database.DB) error {
+		backupUseCase := newBackupUseCase(db)
+
+		if *output == "-" {
+			count, err := backupUseCase.Backup(ctx, os.Stdout)
+			if err != nil {
+				return err
+			}
+
+			logger.Logger.Infof("Exported %d todos", count)
+			return nil
+		}
+
+		file, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("failed to create backup file: %w", err)
+		}
+
+		count, err := backupUseCase.Backup(ctx, file)
+		if err != nil {
+			file.Close()
+			return err
+		}
+
+		if err := file.Close(); err != nil {
+			return fmt.Errorf("failed to write backup file: %w", err)
+		}
+
+		logger.Logger.Infof("Exported %d todos to %s", count, *output)
+		return nil
+	}
+
+	return &command{flags: flags, summary: "Write a JSON backup of all todos", needsDB: true, run: run}
+}
+
+func newImportCommand() *command {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	input := flags.String("i", "-", "backup file to restore, - for stdin")
+	remapIDs := flags.Bool("remap-ids", false, "assign new IDs so that the backup can be restored into a non-empty database")
+
+	run := func(ctx context.Context, cfg *config.Config, db *database.DB) error {
+		// Restoring into a fresh database is the common case, so make sure the schema exists.
+		if err := db.Migrate(ctx); err != nil {
+			return err
+		}
+
+		var r io.Reader = os.Stdin
+		if *input != "-" {
+			file, err := os.Open(*input)
+			if err != nil {
+				return fmt.Errorf("failed to open backup file: %w", err)
+			}
+			defer file.Close()
+			r = file
+		}
+
+		result, err := newBackupUseCase(db).Restore(ctx, r, *remapIDs)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotEmpty) {
+				return fmt.Errorf("%w; use -remap-ids to restore with new IDs", err)
+			}
+			return err
+		}
+
+		logger.Logger.Infof("Restored %d todos", result.Restored)
+		return nil
+	}
+
+	return &command{flags: flags, summary: "Restore a JSON backup created by export", needsDB: true, run: run}
+}
+
+func newMigrateUpCommand() *command {
+	flags := flag.NewFlagSet("migrate up", flag.ContinueOnError)
+
+	run := func(ctx context.Context, cfg *config.Config, db *database.DB) error {
+		migrator, err := db.Migrator()
+		if err != nil {
+			return err
+		}
+
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			fmt.Printf("applied %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(applied) == 0 {
+			fmt.Println("database is up to date")
+		}
+		return nil
+	}
+
+	return &command{flags: flags, summary: "Apply all pending database migrations", needsDB: true, run: run}
+}
+
+func newMigrateDownCommand() *command {
+	flags := flag.NewFlagSet("migrate down", flag.ContinueOnError)
+	steps := flags.Int("steps", 1, "number of migrations to roll back")
+
+	run := func(ctx context.Context, cfg *config.Config, db *database.DB) error {
+		if *steps < 1 {
+			return fmt.Errorf("steps must be at least 1, got %d", *steps)
+		}
+
+		migrator, err := db.Migrator()
+		if err != nil {
+			return err
+		}
+
+		reverted, err := migrator.Down(ctx, *steps)
+		for _, migration := range reverted {
+			fmt.Printf("rolled back %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(reverted) == 0 {
+			fmt.Println("no migrations to roll back")
+		}
+		return nil
+	}
+
+	return &command{flags: flags, summary: "Roll back the most recent database migrations", needsDB: true, run: run}
+}
+
+func newMigrateStatusCommand() *command {
+	flags := flag.NewFlagSet("migrate status", flag.ContinueOnError)
+
+	run := func(ctx context.Context, cfg *config.Config, db *database.DB) error {
+		migrator, err := db.Migrator()
+		if err != nil {
+			return err
+		}
+
+		statuses, err := migrator.Status(ctx)
+		if statuses != nil {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+
+			for _, status := range statuses {
+				state, appliedAt := "pending", "-"
+				if status.Applied {
+					state, appliedAt = "applied", status.AppliedAt.UTC().Format("2006-01-02 15:04:05")
+				}
+				fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+			}
+
+			w.Flush()
+		}
+
+		return err
+	}
+
+	return &command{flags: flags, summary: "List database migrations and whether they are applied", needsDB: true, run: run}
+}
+
+var seedTodos = []struct {
+	title       string
+	description string
+	completed   bool
+}{
+	{"Set up the development environment", "Install Go, Node.js and Docker", true},
+	{"Read the API documentation", "Available at /docs/index.html", true},
+	{"Create the first todo", "", true},
+	{"Try the GraphQL endpoint", "POST a query to /graphql", false},
+	{"Export todos as CSV", "GET /api/v1/todos/export?format=csv", false},
+	{"Subscribe to the calendar feed", "Add /api/v1/todos/calendar.ics to a calendar app", false},
+	{"Import meeting notes", "Upload a Markdown task list to /api/v1/todos/import?format=markdown", false},
+	{"Plan the next sprint", "", false},
+	{"Review open pull requests", "", false},
+	{"Back up the database", "api export -o backup.json", false},
+}
+
+func newSeedCommand() *command {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	count := flags.Int("n", len(seedTodos), "number of todos to create")
+	force := flags.Bool("force", false, "seed even if the database already contains todos")
+
+	run := func(ctx context.Context, cfg *config.Config, db *database.DB) error {
+		if *count < 1 {
+			return fmt.Errorf("n must be at least 1, got %d", *count)
+		}
+
+		if err := db.Migrate(ctx); err != nil {
+			return err
+		}
+
+		todoRepo, transactor := newTodoRepository(cfg, db)
+
+		existing, err := todoRepo.Count(ctx, domain.TodoFilter{})
+		if err != nil {
+			return err
+		}
+
+		if existing > 0 && !*force {
+			logger.Logger.Infof("Database already contains %d todos, skipping seed (use -force to seed anyway)", existing)
+			return nil
+		}
+
+		todoUseCase := usecase.NewTodoUseCase(todoRepo, transactor, event.NewBroker())
+
+		for i := 0; i < *count; i++ {
+			seed := seedTodos[i%len(seedTodos)]
+
+			todo := domain.Todo{
+				Title:     seed.title,
+				Completed: seed.completed,
+			}
+			if i >= len(seedTodos) {
+				todo.Title = fmt.Sprintf("%s (%d)", seed.title, i/len(seedTodos)+1)
+			}
+			if seed.description != "" {
+				description := seed.description
+				todo.Description = &description
+			}
+
+			if _, _, err := todoUseCase.ImportTodo(ctx, todo); err != nil {
+				return err
+			}
+		}
+
+		logger.Logger.Infof("Seeded %d todos", *count)
+		return nil
+	}
+
+	return &command{flags: flags, summary: "Fill an empty database with sample todos", needsDB: true, run: run}
+}
+
+func newConfigValidateCommand() *command {
+	flags := flag.NewFlagSet("config validate", flag.ContinueOnError)
+
+	run := func(ctx context.Context, cfg *config.Config, db *database.DB) error {
+		if err := cfg.Validate(); err != nil {
+			printConfigProblems(err)
+			return errors.New("configuration is invalid")
+		}
+
+		fmt.Println("configuration is valid")
+		return nil
+	}
+
+	return &command{flags: flags, summary: "Check the configuration without starting anything", checksConfig: true, run: run}
+}
+
+func newConfigPrintCommand() *command {
+	flags := flag.NewFlagSet("config print", flag.ContinueOnError)
+
+	run := func(ctx context.Context, cfg *config.Config, db *database.DB) error {
+		return cfg.Print(os.Stdout)
+	}
+
+	return &command{flags: flags, summary: "Print the effective configuration with secrets masked", checksConfig: true, run: run}
+}
+
+// printConfigProblems lists the problems joined in err, one per line.
+func printConfigProblems(err error) {
+	fmt.Fprintln(os.Stderr, "configuration is invalid:")
+	for _, problem := range strings.Split(err.Error(), "\n") {
+		fmt.Fprintf(os.Stderr, "  - %s\n", problem)
+	}
+}
+
+func newVersionCommand() *command {
+	flags := flag.NewFlagSet("version", flag.ContinueOnError)
+
+	run := func(ctx context.Context, cfg *config.Config, db *database.DB) error {
+		revision, builtAt := commit, buildDate
+
+		// Fall back to the VCS information stamped by the Go toolchain.
+		if info, ok := debug.ReadBuildInfo(); ok {
+			for _, setting := range info.Settings {
+				switch {
+				case setting.Key == "vcs.revision" && revision == "":
+					revision = setting.Value
+				case setting.Key == "vcs.time" && builtAt == "":
+					builtAt = setting.Value
+				}
+			}
+		}
+
+		fmt.Printf("version:    %s\n", version)
+		fmt.Printf("commit:     %s\n", valueOrUnknown(revision))
+		fmt.Printf("built:      %s\n", valueOrUnknown(builtAt))
+		fmt.Printf("go version: %s\n", runtime.Version())
+		return nil
+	}
+
+	return &command{flags: flags, summary: "Print version information", skipConfig: true, run: run}
+}
+
+func valueOrUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+
+	return value
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/rod1kutzyy/OnTrack/internal/config"
+)
+
+// systemdFirstFD is the first file descriptor passed by systemd socket
+// activation; LISTEN_FDS tells how many follow.
+const systemdFirstFD = 3
+
+// listen opens the listener selected by cfg.Listen.
+func listen(cfg config.ServerConfig) (net.Listener, error) {
+	switch {
+	case cfg.Listen == config.ListenSystemd:
+		return listenSystemd()
+	case strings.HasPrefix(cfg.Listen, config.ListenUnixPrefix):
+		return listenUnix(strings.TrimPrefix(cfg.Listen, config.ListenUnixPrefix))
+	default:
+		return net.Listen("tcp", net.JoinHostPort(cfg.Host, cfg.Port))
+	}
+}
+
+// listenUnix replaces a socket left behind by a process that did not shut
+// down cleanly, but not the socket of a server that is still running. The
+// socket is removed when the listener is closed.
+func listenUnix(path string) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode().Type() != fs.ModeSocket {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+
+		// Connecting to a stale socket is refused; anything else means
+		// another process may still be listening on it.
+		conn, err := net.DialTimeout("unix", path, time.Second)
+		if err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s is in use by another server", path)
+		}
+		if !errors.Is(err, syscall.ECONNREFUSED) {
+			return nil, fmt.Errorf("failed to check whether %s is in use: %w", path, err)
+		}
+
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	return net.Listen("unix", path)
+}
+
+func listenSystemd() (net.Listener, error) {
+	if pid, _ := strconv.Atoi(os.Getenv("LISTEN_PID")); pid != os.Getpid() {
+		return nil, errors.New("no socket passed by systemd: LISTEN_PID is not set to this process")
+	}
+	if count, _ := strconv.Atoi(os.Getenv("LISTEN_FDS")); count < 1 {
+		return nil, errors.New("no socket passed by systemd: LISTEN_FDS is not set")
+	}
+
+	// Child processes must not inherit the socket.
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+	syscall.CloseOnExec(systemdFirstFD)
+
+	file := os.NewFile(systemdFirstFD, "systemd-socket")
+	defer file.Close()
+
+	return net.FileListener(file)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestListenUnix(t *testing.T) {
+	t.Run("new socket", func(t *testing.T) {
+		socket := filepath.Join(t.TempDir(), "api.sock")
+
+		listener, err := listenUnix(socket)
+		if err != nil {
+			t.Fatalf("listenUnix failed: %v", err)
+		}
+		listener.Close()
+
+		if _, err := os.Lstat(socket); !os.IsNotExist(err) {
+			t.Errorf("socket still exists after closing the listener: %v", err)
+		}
+	})
+
+	t.Run("stale socket", func(t *testing.T) {
+		socket := filepath.Join(t.TempDir(), "api.sock")
+
+		// A process that did not shut down cleanly leaves its socket behind.
+		stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: socket, Net: "unix"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		stale.SetUnlinkOnClose(false)
+		stale.Close()
+
+		listener, err := listenUnix(socket)
+		if err != nil {
+			t.Fatalf("listenUnix failed: %v", err)
+		}
+		listener.Close()
+	})
+
+	t.Run("socket in use", func(t *testing.T) {
+		socket := filepath.Join(t.TempDir(), "api.sock")
+
+		running, err := net.Listen("unix", socket)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer running.Close()
+
+		if listener, err := listenUnix(socket); err == nil || !strings.Contains(err.Error(), "is in use by another server") {
+			if listener != nil {
+				listener.Close()
+			}
+			t.Fatalf("listenUnix() error = %v, want the socket in use", err)
+		}
+
+		// The running server keeps its socket.
+		conn, err := net.Dial("unix", socket)
+		if err != nil {
+			t.Fatalf("running server is no longer reachable: %v", err)
+		}
+		conn.Close()
+	})
+
+	t.Run("not a socket", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "api.sock")
+		if err := os.WriteFile(path, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := listenUnix(path); err == nil || !strings.Contains(err.Error(), "is not a socket") {
+			t.Errorf("listenUnix() error = %v, want the file rejected", err)
+		}
+	})
+}
@@ -2,18 +2,18 @@ package main
 
 import (
 	"net/http"
-	"time"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/rod1kutzyy/OnTrack/internal/config"
+	"github.com/rod1kutzyy/OnTrack/internal/graphql"
 	"github.com/rod1kutzyy/OnTrack/internal/handler"
+	"github.com/rod1kutzyy/OnTrack/internal/metrics"
 	"github.com/rod1kutzyy/OnTrack/internal/middleware"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func SetupRouter(cfg *config.Config, todoHandler *handler.TodoHandler) *gin.Engine {
+func SetupRouter(cfg *config.Config, corsMiddleware *middleware.CORS, appMetrics *metrics.Metrics, todoHandler *handler.TodoHandler, adminHandler *handler.AdminHandler, graphqlHandler *graphql.Handler, healthHandler *handler.HealthHandler) *gin.Engine {
 	if cfg.Logger.Level == "debug" || cfg.Logger.Level == "trace" {
 		gin.SetMode(gin.DebugMode)
 	} else {
@@ -22,30 +22,21 @@ func SetupRouter(cfg *config.Config, todoHandler *handler.TodoHandler) *gin.Engi
 
 	router := gin.New()
 
+	router.Use(middleware.Tracing())
+	router.Use(middleware.RequestID())
 	router.Use(middleware.Recovery())
 	router.Use(middleware.Logger())
+	router.Use(middleware.Metrics(appMetrics))
 
-	router.Use(cors.New(cors.Config{
-		AllowOrigins:  cfg.Server.FrontedURLs,
-		AllowMethods:  []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"},
-		AllowHeaders:  []string{"Origin", "Content-Type"},
-		ExposeHeaders: []string{"Content-Length"},
-		MaxAge:        12 * time.Hour,
-	}))
+	router.Use(corsMiddleware.Handler())
 
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status": "ok",
-			"time":   time.Now().UTC(),
-		})
-	})
+	router.GET("/health", healthHandler.Live)
+	router.GET("/ready", healthHandler.Ready)
 
-	router.GET("/ready", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status": "ready",
-			"time":   time.Now().UTC(),
-		})
-	})
+	router.GET("/metrics", gin.WrapH(appMetrics.Handler()))
+
+	router.POST("/graphql", graphqlHandler.Serve)
+	router.GET("/graphql", graphqlHandler.Serve)
 
 	v1 := router.Group("/api/v1")
 	{
@@ -53,29 +44,42 @@ func SetupRouter(cfg *config.Config, todoHandler *handler.TodoHandler) *gin.Engi
 		{
 			todos.POST("", todoHandler.CreateTodo)
 			todos.GET("", todoHandler.GetAllTodos)
+			todos.GET("/export", todoHandler.ExportTodos)
+			todos.GET("/calendar.ics", todoHandler.GetCalendarFeed)
+			todos.POST("/import", todoHandler.ImportTodos)
 			todos.GET("/:id", todoHandler.GetTodoByID)
 			todos.PUT("/:id", todoHandler.UpdateTodo)
 			todos.DELETE("/:id", todoHandler.DeleteTodo)
 			todos.PATCH("/:id/toggle", todoHandler.ToggleTodoComplete)
 		}
+
+		admin := v1.Group("/admin", middleware.AdminAuth(cfg.Admin.Token))
+		{
+			admin.GET("/backup", adminHandler.Backup)
+			admin.POST("/restore", adminHandler.Restore)
+			admin.GET("/log-level", adminHandler.GetLogLevel)
+			admin.PUT("/log-level", adminHandler.SetLogLevel)
+		}
 	}
 
 	router.NoRoute(func(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"error":   "Not found",
-			"message": "The requested endpoint does not exist",
-			"path":    c.Request.URL.Path,
+			"success":    false,
+			"error":      "Not found",
+			"message":    "The requested endpoint does not exist",
+			"path":       c.Request.URL.Path,
+			"request_id": middleware.GetRequestID(c),
 		})
 	})
 
 	router.NoMethod(func(c *gin.Context) {
 		c.JSON(http.StatusMethodNotAllowed, gin.H{
-			"success": false,
-			"error":   "Method Not Allowed",
-			"message": "The HTTP method is not supported for this endpoint",
-			"method":  c.Request.Method,
-			"path":    c.Request.URL.Path,
+			"success":    false,
+			"error":      "Method Not Allowed",
+			"message":    "The HTTP method is not supported for this endpoint",
+			"method":     c.Request.Method,
+			"path":       c.Request.URL.Path,
+			"request_id": middleware.GetRequestID(c),
 		})
 	})
 
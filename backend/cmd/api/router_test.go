@@ -0,0 +1,777 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rod1kutzyy/OnTrack/internal/config"
+	"github.com/rod1kutzyy/OnTrack/internal/dto"
+	"github.com/rod1kutzyy/OnTrack/internal/event"
+	"github.com/rod1kutzyy/OnTrack/internal/graphql"
+	"github.com/rod1kutzyy/OnTrack/internal/handler"
+	"github.com/rod1kutzyy/OnTrack/internal/health"
+	"github.com/rod1kutzyy/OnTrack/internal/logger"
+	"github.com/rod1kutzyy/OnTrack/internal/metrics"
+	"github.com/rod1kutzyy/OnTrack/internal/middleware"
+	"github.com/rod1kutzyy/OnTrack/internal/repository/memory"
+	"github.com/rod1kutzyy/OnTrack/internal/usecase"
+	"github.com/rod1kutzyy/OnTrack/internal/validator"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+const testAdminToken = "s3cret"
+
+func TestMain(m *testing.M) {
+	if err := logger.InitWithOutput("error", io.Discard); err != nil {
+		panic(err)
+	}
+
+	os.Exit(m.Run())
+}
+
+// newTestRouter wires the real router to an in-memory repository holding
+// the given todos, the way the serve command does.
+func newTestRouter(t *testing.T, titles ...string) http.Handler {
+	t.Helper()
+
+	cfg := &config.Config{
+		Server:  config.ServerConfig{FrontedURLs: []string{"http://localhost:3000"}},
+		Logger:  config.LoggerConfig{Level: "error"},
+		GraphQL: config.GraphQLConfig{MaxDepth: 10, MaxComplexity: 200},
+		Admin:   config.AdminConfig{Token: testAdminToken},
+	}
+
+	eventBroker := event.NewBroker()
+	todoRepo := memory.NewTodoRepository()
+	transactor := memory.NewTransactor(todoRepo)
+	todoUseCase := usecase.NewTracingTodoUseCase(usecase.NewTodoUseCase(todoRepo, transactor, eventBroker))
+	todoValidator := validator.NewTodoValidator()
+
+	for _, title := range titles {
+		if _, err := todoUseCase.CreateTodo(context.Background(), dto.CreateTodoRequest{Title: title}); err != nil {
+			t.Fatalf("failed to seed todo: %v", err)
+		}
+	}
+
+	schema, err := graphql.NewSchema(todoUseCase, todoValidator, eventBroker)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	appMetrics := metrics.New()
+	if err := appMetrics.RegisterTodoCounts(todoRepo); err != nil {
+		t.Fatal(err)
+	}
+
+	return SetupRouter(cfg, middleware.NewCORS(cfg.Server.FrontedURLs), appMetrics,
+		handler.NewTodoHandler(todoUseCase, todoValidator),
+		handler.NewAdminHandler(usecase.NewBackupUseCase(todoRepo, transactor)),
+		graphql.NewHandler(schema, graphql.Limits{MaxDepth: cfg.GraphQL.MaxDepth, MaxComplexity: cfg.GraphQL.MaxComplexity}),
+		handler.NewHealthHandler(health.NewRegistry()),
+	)
+}
+
+type envelope struct {
+	Success   bool            `json:"success"`
+	Data      json.RawMessage `json:"data"`
+	Code      string          `json:"code"`
+	RequestID string          `json:"request_id"`
+	Details   []struct {
+		Field string `json:"field"`
+		Tag   string `json:"tag"`
+	} `json:"details"`
+}
+
+func serve(t *testing.T, router http.Handler, method, path, body string, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func decode[T any](t *testing.T, data []byte) T {
+	t.Helper()
+
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatalf("failed to decode %s: %v", data, err)
+	}
+	return v
+}
+
+func TestTodoRoutes(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantCode   string
+		check      func(t *testing.T, data json.RawMessage)
+	}{
+		{
+			name:       "create",
+			method:     http.MethodPost,
+			path:       "/api/v1/todos",
+			body:       `{"title":"  Walk the dog ","description":"Around the park"}`,
+			wantStatus: http.StatusCreated,
+			check: func(t *testing.T, data json.RawMessage) {
+				todo := decode[dto.TodoResponse](t, data)
+				if todo.ID != 3 || todo.Title != "Walk the dog" || todo.Description != "Around the park" || todo.Completed {
+					t.Errorf("created %+v", todo)
+				}
+			},
+		},
+		{
+			name:       "create with malformed JSON",
+			method:     http.MethodPost,
+			path:       "/api/v1/todos",
+			body:       `{"title":`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "INVALID_JSON",
+		},
+		{
+			name:       "create without title",
+			method:     http.MethodPost,
+			path:       "/api/v1/todos",
+			body:       `{"description":"No title"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "INVALID_JSON",
+		},
+		{
+			name:       "create with blank title",
+			method:     http.MethodPost,
+			path:       "/api/v1/todos",
+			body:       `{"title":"   "}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "VALIDATION_ERROR",
+		},
+		{
+			name:       "create with digits only",
+			method:     http.MethodPost,
+			path:       "/api/v1/todos",
+			body:       `{"title":"12345"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "VALIDATION_ERROR",
+		},
+		{
+			name:       "get",
+			method:     http.MethodGet,
+			path:       "/api/v1/todos/1",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, data json.RawMessage) {
+				if todo := decode[dto.TodoResponse](t, data); todo.ID != 1 || todo.Title != "Buy milk" {
+					t.Errorf("got %+v", todo)
+				}
+			},
+		},
+		{
+			name:       "get missing",
+			method:     http.MethodGet,
+			path:       "/api/v1/todos/42",
+			wantStatus: http.StatusNotFound,
+			wantCode:   "TODO_NOT_FOUND",
+		},
+		{
+			name:       "get with invalid id",
+			method:     http.MethodGet,
+			path:       "/api/v1/todos/abc",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "INVALID_ID",
+		},
+		{
+			name:       "list",
+			method:     http.MethodGet,
+			path:       "/api/v1/todos?page=1&limit=1",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, data json.RawMessage) {
+				list := decode[dto.TodoListResponse](t, data)
+				if len(list.Items) != 1 || list.Items[0].ID != 2 {
+					t.Errorf("items = %+v, want the newest todo", list.Items)
+				}
+				want := dto.PaginationResponse{Total: 2, TotalPages: 2, CurrentPage: 1, PerPage: 1, HasNext: true}
+				if list.Pagination != want {
+					t.Errorf("pagination = %+v, want %+v", list.Pagination, want)
+				}
+			},
+		},
+		{
+			name:       "list with search",
+			method:     http.MethodGet,
+			path:       "/api/v1/todos?page=1&limit=10&search=BREAD",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, data json.RawMessage) {
+				list := decode[dto.TodoListResponse](t, data)
+				if len(list.Items) != 1 || list.Items[0].Title != "Bake bread" {
+					t.Errorf("items = %+v, want the bread todo", list.Items)
+				}
+			},
+		},
+		{
+			name:       "list without page",
+			method:     http.MethodGet,
+			path:       "/api/v1/todos?limit=10",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "VALIDATION_ERROR",
+		},
+		{
+			name:       "list with limit too large",
+			method:     http.MethodGet,
+			path:       "/api/v1/todos?page=1&limit=500",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "update",
+			method:     http.MethodPut,
+			path:       "/api/v1/todos/1",
+			body:       `{"title":"Buy oat milk","completed":true}`,
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, data json.RawMessage) {
+				if todo := decode[dto.TodoResponse](t, data); todo.Title != "Buy oat milk" || !todo.Completed {
+					t.Errorf("updated %+v", todo)
+				}
+			},
+		},
+		{
+			name:       "update with blank title",
+			method:     http.MethodPut,
+			path:       "/api/v1/todos/1",
+			body:       `{"title":" "}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "VALIDATION_ERROR",
+		},
+		{
+			name:       "update missing",
+			method:     http.MethodPut,
+			path:       "/api/v1/todos/42",
+			body:       `{"completed":true}`,
+			wantStatus: http.StatusNotFound,
+			wantCode:   "TODO_NOT_FOUND",
+		},
+		{
+			name:       "delete",
+			method:     http.MethodDelete,
+			path:       "/api/v1/todos/1",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "delete missing",
+			method:     http.MethodDelete,
+			path:       "/api/v1/todos/42",
+			wantStatus: http.StatusNotFound,
+			wantCode:   "TODO_NOT_FOUND",
+		},
+		{
+			name:       "toggle",
+			method:     http.MethodPatch,
+			path:       "/api/v1/todos/2/toggle",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, data json.RawMessage) {
+				if todo := decode[dto.TodoResponse](t, data); todo.ID != 2 || !todo.Completed {
+					t.Errorf("toggled %+v", todo)
+				}
+			},
+		},
+		{
+			name:       "toggle with invalid id",
+			method:     http.MethodPatch,
+			path:       "/api/v1/todos/-1/toggle",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "INVALID_ID",
+		},
+		{
+			name:       "unknown route",
+			method:     http.MethodGet,
+			path:       "/api/v1/nothing",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(t, "Buy milk", "Bake bread")
+
+			rec := serve(t, router, tt.method, tt.path, tt.body, nil)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+
+			if rec.Code == http.StatusNoContent {
+				return
+			}
+
+			resp := decode[envelope](t, rec.Body.Bytes())
+			if resp.Success != (rec.Code < http.StatusBadRequest) {
+				t.Errorf("success = %t for status %d", resp.Success, rec.Code)
+			}
+			if resp.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", resp.Code, tt.wantCode)
+			}
+			if tt.check != nil {
+				tt.check(t, resp.Data)
+			}
+		})
+	}
+}
+
+func TestDeleteRemovesTodo(t *testing.T) {
+	router := newTestRouter(t, "Buy milk")
+
+	if rec := serve(t, router, http.MethodDelete, "/api/v1/todos/1", "", nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete status = %d", rec.Code)
+	}
+	if rec := serve(t, router, http.MethodGet, "/api/v1/todos/1", "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("get after delete status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestValidationErrorDetails(t *testing.T) {
+	router := newTestRouter(t)
+
+	rec := serve(t, router, http.MethodPost, "/api/v1/todos", `{"title":"Buy milk","description":"Buy milk"}`, nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	resp := decode[envelope](t, rec.Body.Bytes())
+	if len(resp.Details) != 1 || resp.Details[0].Field != "description" || resp.Details[0].Tag != "unique_content" {
+		t.Errorf("details = %+v, want one unique_content error on description", resp.Details)
+	}
+}
+
+func TestImportAndExport(t *testing.T) {
+	router := newTestRouter(t, "Buy milk")
+
+	const csvDoc = "Task,Notes,Done\n" +
+		"Call mom,,yes\n" +
+		",,\n" +
+		"=HYPERLINK(\"http://example.com\"),Pay rent,maybe\n" +
+		",Notes without a title,no\n"
+
+	wantRows := []dto.ImportRowResult{
+		{Row: 2, Status: dto.ImportStatusCreated},
+		{Row: 3, Status: dto.ImportStatusSkipped},
+		{Row: 4, Status: dto.ImportStatusFailed},
+		{Row: 5, Status: dto.ImportStatusFailed},
+	}
+
+	for _, dryRun := range []bool{true, false} {
+		path := "/api/v1/todos/import?format=csv"
+		if dryRun {
+			path += "&dry_run=true"
+		}
+
+		rec := serve(t, router, http.MethodPost, path, csvDoc, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("import (dry run %v) status = %d: %s", dryRun, rec.Code, rec.Body)
+		}
+
+		summary := decode[dto.ImportSummaryResponse](t, decode[envelope](t, rec.Body.Bytes()).Data)
+		if summary.DryRun != dryRun || summary.Total != 4 || summary.Created != 1 || summary.Skipped != 1 || summary.Failed != 2 {
+			t.Errorf("import (dry run %v) summary = %+v", dryRun, summary)
+		}
+		for i, want := range wantRows {
+			if i >= len(summary.Rows) || summary.Rows[i].Row != want.Row || summary.Rows[i].Status != want.Status {
+				t.Errorf("import (dry run %v) rows = %+v, want %+v", dryRun, summary.Rows, wantRows)
+				break
+			}
+		}
+
+		rec = serve(t, router, http.MethodGet, "/api/v1/todos?page=1&limit=10", "", nil)
+		wantTodos := 2
+		if dryRun {
+			wantTodos = 1
+		}
+		if todos := decode[dto.TodoListResponse](t, decode[envelope](t, rec.Body.Bytes()).Data); todos.Pagination.Total != int64(wantTodos) {
+			t.Errorf("after import (dry run %v) %d todos exist, want %d", dryRun, todos.Pagination.Total, wantTodos)
+		}
+	}
+
+	rec := serve(t, router, http.MethodGet, "/api/v1/todos/export?format=csv&completed=true", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("export status = %d: %s", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get("Content-Disposition"); got != `attachment; filename="todos.csv"` {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if len(lines) != 2 || lines[0] != "id,title,description,completed,created_at,updated_at" || !strings.HasPrefix(lines[1], "2,Call mom,,true,") {
+		t.Errorf("export =\n%s\nwant the header and Call mom", rec.Body)
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		wantCode string
+	}{
+		{name: "unsupported export format", method: http.MethodGet, path: "/api/v1/todos/export?format=xlsx", wantCode: "UNSUPPORTED_FORMAT"},
+		{name: "unsupported import format", method: http.MethodPost, path: "/api/v1/todos/import?format=xlsx", body: "title\nBuy milk\n", wantCode: "UNSUPPORTED_FORMAT"},
+		{name: "empty import", method: http.MethodPost, path: "/api/v1/todos/import", wantCode: "INVALID_FILE"},
+		{name: "csv without title", method: http.MethodPost, path: "/api/v1/todos/import", body: "notes\nx\n", wantCode: "INVALID_FILE"},
+		{name: "unknown mapping", method: http.MethodPost, path: "/api/v1/todos/import?mapping[priority]=title", body: "title\nBuy milk\n", wantCode: "INVALID_FILE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(t, router, tt.method, tt.path, tt.body, nil)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusBadRequest, rec.Body)
+			}
+			if code := decode[envelope](t, rec.Body.Bytes()).Code; code != tt.wantCode {
+				t.Errorf("code = %q, want %q", code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestImportCalendarUpdatesByUID(t *testing.T) {
+	router := newTestRouter(t)
+
+	calendar := func(summary, status string) string {
+		return "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//test//EN\r\n" +
+			"BEGIN:VTODO\r\nUID:milk@example.com\r\nSUMMARY:" + summary + "\r\nSTATUS:" + status + "\r\nEND:VTODO\r\n" +
+			"END:VCALENDAR\r\n"
+	}
+
+	for _, step := range []struct {
+		doc        string
+		wantStatus string
+	}{
+		{doc: calendar("Buy milk", "NEEDS-ACTION"), wantStatus: dto.ImportStatusCreated},
+		{doc: calendar("Buy oat milk", "COMPLETED"), wantStatus: dto.ImportStatusUpdated},
+	} {
+		rec := serve(t, router, http.MethodPost, "/api/v1/todos/import?format=ics", step.doc, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("import status = %d: %s", rec.Code, rec.Body)
+		}
+		summary := decode[dto.ImportSummaryResponse](t, decode[envelope](t, rec.Body.Bytes()).Data)
+		if len(summary.Rows) != 1 || summary.Rows[0].Status != step.wantStatus || summary.Rows[0].TodoID != 1 {
+			t.Errorf("import rows = %+v, want todo 1 %s", summary.Rows, step.wantStatus)
+		}
+	}
+
+	rec := serve(t, router, http.MethodGet, "/api/v1/todos/1", "", nil)
+	if todo := decode[dto.TodoResponse](t, decode[envelope](t, rec.Body.Bytes()).Data); todo.Title != "Buy oat milk" || !todo.Completed {
+		t.Errorf("todo = %+v, want the second import applied", todo)
+	}
+}
+
+func TestAdminRoutesRequireToken(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     http.Header
+		wantStatus int
+	}{
+		{name: "no token", wantStatus: http.StatusUnauthorized},
+		{name: "wrong token", header: http.Header{"Authorization": {"Bearer nope"}}, wantStatus: http.StatusUnauthorized},
+		{name: "valid token", header: http.Header{"Authorization": {"Bearer " + testAdminToken}}, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(t, "Buy milk")
+
+			rec := serve(t, router, http.MethodGet, "/api/v1/admin/backup", "", tt.header)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	header := http.Header{"Authorization": {"Bearer " + testAdminToken}}
+
+	source := newTestRouter(t, "Buy milk", "Call mom")
+	serve(t, source, http.MethodDelete, "/api/v1/todos/1", "", nil)
+
+	rec := serve(t, source, http.MethodGet, "/api/v1/admin/backup", "", header)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("backup status = %d: %s", rec.Code, rec.Body)
+	}
+	backupDoc := rec.Body.String()
+
+	// Into an empty database the todos keep their IDs.
+	target := newTestRouter(t)
+	rec = serve(t, target, http.MethodPost, "/api/v1/admin/restore", backupDoc, header)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("restore status = %d: %s", rec.Code, rec.Body)
+	}
+	restored := decode[dto.RestoreResponse](t, decode[envelope](t, rec.Body.Bytes()).Data)
+	if restored.SchemaVersion != 1 || restored.Restored != 1 || restored.IDs[2] != 2 {
+		t.Errorf("restore returned %+v, want todo 2 restored with its ID", restored)
+	}
+	rec = serve(t, target, http.MethodGet, "/api/v1/todos/2", "", nil)
+	if todo := decode[dto.TodoResponse](t, decode[envelope](t, rec.Body.Bytes()).Data); todo.Title != "Call mom" {
+		t.Errorf("restored todo = %+v, want Call mom", todo)
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "into non-empty database", body: backupDoc, wantStatus: http.StatusConflict, wantCode: "DATABASE_NOT_EMPTY"},
+		{name: "unsupported version", body: `{"schema_version": 9, "todos": [], "todo_count": 0}`, wantStatus: http.StatusBadRequest, wantCode: "INVALID_BACKUP"},
+		{name: "truncated", body: backupDoc[:len(backupDoc)/2], wantStatus: http.StatusBadRequest, wantCode: "INVALID_BACKUP"},
+		{name: "invalid remap_ids", query: "?remap_ids=maybe", body: backupDoc, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(t, target, http.MethodPost, "/api/v1/admin/restore"+tt.query, tt.body, header)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if code := decode[envelope](t, rec.Body.Bytes()).Code; code != tt.wantCode {
+				t.Errorf("code = %q, want %q", code, tt.wantCode)
+			}
+		})
+	}
+
+	rec = serve(t, target, http.MethodPost, "/api/v1/admin/restore?remap_ids=true", backupDoc, header)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("restore with remap_ids status = %d: %s", rec.Code, rec.Body)
+	}
+	remapped := decode[dto.RestoreResponse](t, decode[envelope](t, rec.Body.Bytes()).Data)
+	if remapped.IDs[2] == 0 || remapped.IDs[2] == 2 {
+		t.Errorf("restore with remap_ids returned %+v, want a new ID for todo 2", remapped)
+	}
+}
+
+func TestRestoreUIDConflict(t *testing.T) {
+	header := http.Header{"Authorization": {"Bearer " + testAdminToken}}
+	const backupDoc = `{"schema_version": 1, "todos": [{"id": 1, "uid": "a@example.com", "title": "Buy milk"}], "todo_count": 1}`
+
+	router := newTestRouter(t)
+	if rec := serve(t, router, http.MethodPost, "/api/v1/admin/restore", backupDoc, header); rec.Code != http.StatusOK {
+		t.Fatalf("restore status = %d: %s", rec.Code, rec.Body)
+	}
+
+	rec := serve(t, router, http.MethodPost, "/api/v1/admin/restore?remap_ids=true", backupDoc, header)
+	if rec.Code != http.StatusConflict || decode[envelope](t, rec.Body.Bytes()).Code != "UID_CONFLICT" {
+		t.Errorf("status = %d, want %d with UID_CONFLICT: %s", rec.Code, http.StatusConflict, rec.Body)
+	}
+}
+
+func TestProbes(t *testing.T) {
+	router := newTestRouter(t)
+
+	for _, path := range []string{"/health", "/ready"} {
+		if rec := serve(t, router, http.MethodGet, path, "", nil); rec.Code != http.StatusOK {
+			t.Errorf("GET %s status = %d, want %d", path, rec.Code, http.StatusOK)
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	router := newTestRouter(t, "Buy milk", "Write report")
+
+	serve(t, router, http.MethodPatch, "/api/v1/todos/2/toggle", "", nil)
+	serve(t, router, http.MethodGet, "/api/v1/todos/1", "", nil)
+	serve(t, router, http.MethodGet, "/api/v1/todos/42", "", nil)
+	serve(t, router, http.MethodGet, "/wp-login.php", "", nil)
+
+	rec := serve(t, router, http.MethodGet, "/metrics", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /metrics status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	for _, want := range []string{
+		`ontrack_http_requests_total{method="PATCH",route="/api/v1/todos/:id/toggle",status="200"} 1`,
+		`ontrack_http_requests_total{method="GET",route="/api/v1/todos/:id",status="200"} 1`,
+		`ontrack_http_requests_total{method="GET",route="/api/v1/todos/:id",status="404"} 1`,
+		`ontrack_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`ontrack_http_request_duration_seconds_count{method="GET",route="/api/v1/todos/:id",status="200"} 1`,
+		`ontrack_todos{status="completed"} 1`,
+		`ontrack_todos{status="open"} 1`,
+	} {
+		if !strings.Contains(rec.Body.String(), want+"\n") {
+			t.Errorf("metrics do not contain %s", want)
+		}
+	}
+}
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	router := newTestRouter(t, "Buy milk")
+
+	const traceID, parentID = "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"
+	serve(t, router, http.MethodGet, "/api/v1/todos/42", "", http.Header{
+		"Traceparent": {"00-" + traceID + "-" + parentID + "-01"},
+	})
+
+	var spans []sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.SpanContext().TraceID().String() == traceID {
+			spans = append(spans, span)
+		}
+	}
+	if len(spans) != 2 {
+		t.Fatalf("recorded %d spans in the trace from traceparent, want a server and a use case span", len(spans))
+	}
+	useCaseSpan, serverSpan := spans[0], spans[1]
+
+	if serverSpan.Name() != "GET /api/v1/todos/:id" {
+		t.Errorf("server span is named %q", serverSpan.Name())
+	}
+	if got := serverSpan.Parent().SpanID().String(); got != parentID {
+		t.Errorf("server span has parent %s, want %s", got, parentID)
+	}
+
+	if useCaseSpan.Name() != "TodoUseCase.GetTodoByID" {
+		t.Errorf("use case span is named %q", useCaseSpan.Name())
+	}
+	if useCaseSpan.Parent().SpanID() != serverSpan.SpanContext().SpanID() {
+		t.Error("use case span is not a child of the server span")
+	}
+	if len(useCaseSpan.Events()) != 1 || useCaseSpan.Events()[0].Name != "exception" {
+		t.Errorf("use case span did not record the not found error, events: %v", useCaseSpan.Events())
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	router := newTestRouter(t)
+
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{name: "generated", header: ""},
+		{name: "accepted", header: "edge-7f3a:42", want: "edge-7f3a:42"},
+		{name: "invalid replaced", header: "<script>", want: ""},
+		{name: "too long replaced", header: strings.Repeat("a", 129), want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.header != "" {
+				header.Set(middleware.RequestIDHeader, tt.header)
+			}
+
+			rec := serve(t, router, http.MethodGet, "/api/v1/todos/42", "", header)
+
+			requestID := rec.Header().Get(middleware.RequestIDHeader)
+			if tt.want != "" && requestID != tt.want {
+				t.Errorf("%s = %q, want %q", middleware.RequestIDHeader, requestID, tt.want)
+			}
+			if tt.want == "" && (requestID == "" || requestID == tt.header) {
+				t.Errorf("%s = %q, want a generated ID", middleware.RequestIDHeader, requestID)
+			}
+
+			if body := decode[envelope](t, rec.Body.Bytes()); body.RequestID != requestID {
+				t.Errorf("error response has request_id %q, want %q", body.RequestID, requestID)
+			}
+		})
+	}
+}
+
+func TestRequestLogsShareRequestID(t *testing.T) {
+	var logs bytes.Buffer
+	logger.Logger.SetOutput(&logs)
+	logger.Logger.SetLevel(logrus.DebugLevel)
+	logger.Logger.SetFormatter(&logrus.JSONFormatter{})
+	t.Cleanup(func() {
+		logger.Logger.SetOutput(io.Discard)
+		logger.Logger.SetLevel(logrus.ErrorLevel)
+	})
+
+	router := newTestRouter(t)
+	logs.Reset()
+
+	header := http.Header{}
+	header.Set(middleware.RequestIDHeader, "req-1")
+	serve(t, router, http.MethodGet, "/api/v1/todos/42", "", header)
+
+	var messages []string
+	for line := range strings.Lines(logs.String()) {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		if entry["request_id"] != "req-1" {
+			t.Errorf("log line %q has no request_id", line)
+		}
+		messages = append(messages, entry["msg"].(string))
+	}
+
+	// The use case and the request log.
+	if !slices.Contains(messages, "Todo not found") || !slices.Contains(messages, "Client error") {
+		t.Errorf("logged %q, want the use case and request log lines", messages)
+	}
+}
+
+func TestLogLevel(t *testing.T) {
+	t.Cleanup(func() {
+		if err := logger.SetLevels("error", nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	router := newTestRouter(t)
+	header := http.Header{"Authorization": {"Bearer " + testAdminToken}}
+
+	rec := serve(t, router, http.MethodPut, "/api/v1/admin/log-level", `{"level":"warn","packages":{"usecase":"debug"}}`, header)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+
+	// Without packages the overrides are kept.
+	rec = serve(t, router, http.MethodPut, "/api/v1/admin/log-level", `{"level":"info"}`, header)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+
+	rec = serve(t, router, http.MethodGet, "/api/v1/admin/log-level", "", header)
+	levels := decode[dto.LogLevelResponse](t, decode[envelope](t, rec.Body.Bytes()).Data)
+	if levels.Level != "info" || levels.Packages["usecase"] != "debug" {
+		t.Errorf("got levels %+v, want info with usecase at debug", levels)
+	}
+
+	rec = serve(t, router, http.MethodPut, "/api/v1/admin/log-level", `{"level":"verbose"}`, header)
+	if rec.Code != http.StatusBadRequest || decode[envelope](t, rec.Body.Bytes()).Code != "INVALID_LOG_LEVEL" {
+		t.Errorf("status = %d, want %d with INVALID_LOG_LEVEL: %s", rec.Code, http.StatusBadRequest, rec.Body)
+	}
+}
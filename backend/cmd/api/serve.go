@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/rod1kutzyy/OnTrack/internal/config"
+	"github.com/rod1kutzyy/OnTrack/internal/event"
+	"github.com/rod1kutzyy/OnTrack/internal/graphql"
+	"github.com/rod1kutzyy/OnTrack/internal/grpc"
+	"github.com/rod1kutzyy/OnTrack/internal/handler"
+	"github.com/rod1kutzyy/OnTrack/internal/health"
+	"github.com/rod1kutzyy/OnTrack/internal/infrastructure/database"
+	"github.com/rod1kutzyy/OnTrack/internal/logger"
+	"github.com/rod1kutzyy/OnTrack/internal/metrics"
+	"github.com/rod1kutzyy/OnTrack/internal/middleware"
+	"github.com/rod1kutzyy/OnTrack/internal/tracing"
+	"github.com/rod1kutzyy/OnTrack/internal/usecase"
+	"github.com/rod1kutzyy/OnTrack/internal/validator"
+)
+
+// readinessCheckTimeout bounds each check run by /ready.
+const readinessCheckTimeout = 2 * time.Second
+
+func newServeCommand() *command {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	skipMigrations := flags.Bool("skip-migrations", false, "do not apply pending database migrations at startup")
+
+	run := func(ctx context.Context, cfg *config.Config, db *database.DB) error {
+		logger.Logger.Info("=== Starting Application ===")
+		logger.Logger.Infof("Environment: %s", cfg.Logger.Level)
+
+		if db == nil {
+			logger.Logger.Warn("Using the in-memory repository, todos are lost on shutdown")
+		} else if !*skipMigrations {
+			if err := db.Migrate(ctx); err != nil {
+				return err
+			}
+		}
+
+		shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+		if err != nil {
+			return err
+		}
+		if db != nil {
+			if err := tracing.InstrumentDB(db.GetDB()); err != nil {
+				return err
+			}
+		}
+
+		eventBroker := event.NewBroker()
+
+		todoRepo, transactor := newTodoRepository(cfg, db)
+		todoUseCase := usecase.NewTracingTodoUseCase(usecase.NewTodoUseCase(todoRepo, transactor, eventBroker))
+		todoValidator := validator.NewTodoValidator()
+		todoHandler := handler.NewTodoHandler(todoUseCase, todoValidator)
+
+		backupUseCase := usecase.NewBackupUseCase(todoRepo, transactor)
+		adminHandler := handler.NewAdminHandler(backupUseCase)
+
+		graphqlSchema, err := graphql.NewSchema(todoUseCase, todoValidator, eventBroker)
+		if err != nil {
+			return err
+		}
+		graphqlHandler := graphql.NewHandler(graphqlSchema, graphql.Limits{
+			MaxDepth:      cfg.GraphQL.MaxDepth,
+			MaxComplexity: cfg.GraphQL.MaxComplexity,
+		})
+
+		grpcServer, grpcHealthServer := grpc.NewServer(grpc.NewTodoServer(todoUseCase, todoValidator))
+
+		appMetrics := metrics.New()
+		if db != nil {
+			if err := appMetrics.InstrumentDB(db.GetDB()); err != nil {
+				return err
+			}
+		}
+		if err := appMetrics.RegisterTodoCounts(todoRepo); err != nil {
+			return err
+		}
+
+		healthRegistry := health.NewRegistry()
+		if db != nil {
+			healthRegistry.Register("database", readinessCheckTimeout, db.Ping)
+			healthRegistry.Register("migrations", readinessCheckTimeout, db.CheckMigrations)
+		}
+		healthHandler := handler.NewHealthHandler(healthRegistry)
+
+		corsMiddleware := middleware.NewCORS(cfg.Server.FrontedURLs)
+
+		reloader := config.NewReloader(cfg)
+		reloader.Subscribe("logger", func(cfg *config.Config) error {
+			return logger.Configure(cfg.Logger)
+		})
+		reloader.Subscribe("cors", func(cfg *config.Config) error {
+			corsMiddleware.SetOrigins(cfg.Server.FrontedURLs)
+			return nil
+		})
+
+		router := SetupRouter(cfg, corsMiddleware, appMetrics, todoHandler, adminHandler, graphqlHandler, healthHandler)
+		srv, err := NewServer(cfg, router, grpcServer, grpcHealthServer)
+		if err != nil {
+			return err
+		}
+		reloader.Subscribe("tls", func(*config.Config) error {
+			return srv.ReloadCertificate()
+		})
+		srv.RegisterOnShutdown(graphqlHandler.Shutdown)
+
+		errChan := srv.Start()
+
+		go func() {
+			if err := <-errChan; err != nil {
+				logger.Logger.Fatalf("Server failed to start: %v", err)
+			}
+		}()
+
+		srv.WaitForShutdownSignal(func() {
+			reloadConfig(reloader)
+		})
+
+		// Report unready before the listeners close, so that load balancers
+		// stop sending requests that would be refused.
+		healthRegistry.SetShuttingDown()
+		grpcHealthServer.Shutdown()
+		if drainDelay := reloader.Current().Server.DrainDelay; drainDelay > 0 {
+			logger.Logger.Infof("Draining for %s before shutting down", drainDelay)
+			time.Sleep(drainDelay)
+		}
+
+		// The database is closed by runCommand once the servers have stopped.
+		err = srv.GracefulShutdown(func() error {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if err := shutdownTracing(ctx); err != nil {
+				return fmt.Errorf("failed to flush traces: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		logger.Logger.Info("=== Application Exited Successfully ===")
+		return nil
+	}
+
+	return &command{
+		flags:      flags,
+		summary:    "Start the HTTP and gRPC servers (default)",
+		needsDB:    true,
+		inMemory:   true,
+		logsStdout: true,
+		run:        run,
+	}
+}
+
+// reloadConfig applies a changed configuration without dropping connections.
+// An invalid configuration is logged and the current one kept.
+func reloadConfig(reloader *config.Reloader) {
+	result, err := reloader.Reload()
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to reload configuration")
+		return
+	}
+
+	if len(result.NeedsRestart) > 0 {
+		logger.Logger.WithField("settings", result.NeedsRestart).Warn("Changed settings take effect after a restart")
+	}
+	logger.Logger.WithField("changed", result.Changed).Info("Configuration reloaded")
+}
@@ -2,51 +2,135 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rod1kutzyy/OnTrack/internal/config"
 	"github.com/rod1kutzyy/OnTrack/internal/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
 )
 
 type Server struct {
-	httpServer *http.Server
-	config     *config.Config
+	httpServer   *http.Server
+	grpcServer   *grpc.Server
+	healthServer *health.Server
+	grpcAddr     string
+	config       *config.Config
+	// certificate is nil unless the HTTP server serves HTTPS.
+	certificate *certificate
 }
 
-func NewServer(cfg *config.Config, router *gin.Engine) *Server {
+func NewServer(cfg *config.Config, router *gin.Engine, grpcServer *grpc.Server, healthServer *health.Server) (*Server, error) {
 	httpServer := &http.Server{
-		Addr:           fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port),
 		Handler:        router,
-		ReadTimeout:    10 * time.Second,
-		WriteTimeout:   10 * time.Second,
-		IdleTimeout:    60 * time.Second,
+		ReadTimeout:    cfg.Server.ReadTimeout,
+		WriteTimeout:   cfg.Server.WriteTimeout,
+		IdleTimeout:    cfg.Server.IdleTimeout,
 		MaxHeaderBytes: 1 << 20,
 	}
 
-	return &Server{
-		httpServer: httpServer,
-		config:     cfg,
+	s := &Server{
+		httpServer:   httpServer,
+		grpcServer:   grpcServer,
+		healthServer: healthServer,
+		grpcAddr:     net.JoinHostPort(cfg.Server.Host, cfg.Server.GRPCPort),
+		config:       cfg,
 	}
+
+	if cfg.Server.TLS.Enabled() {
+		cert, err := loadCertificate(cfg.Server.TLS.CertFile, cfg.Server.TLS.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		s.certificate = cert
+		httpServer.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: cert.GetCertificate,
+		}
+	}
+
+	if cfg.Server.H2C {
+		var protocols http.Protocols
+		protocols.SetHTTP1(true)
+		protocols.SetUnencryptedHTTP2(true)
+		httpServer.Protocols = &protocols
+	}
+
+	return s, nil
+}
+
+// ReloadCertificate reads the TLS certificate files again. It does nothing
+// when the server does not serve HTTPS.
+func (s *Server) ReloadCertificate() error {
+	if s.certificate == nil {
+		return nil
+	}
+
+	return s.certificate.Reload()
+}
+
+// RegisterOnShutdown registers f to be called when the HTTP server starts
+// shutting down, to end requests that would otherwise keep running.
+func (s *Server) RegisterOnShutdown(f func()) {
+	s.httpServer.RegisterOnShutdown(f)
 }
 
 func (s *Server) Start() <-chan error {
-	errChan := make(chan error, 1)
+	errChan := make(chan error, 2)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
 
 	go func() {
-		defer close(errChan)
-		logger.Logger.Infof("Starting server on %s", s.httpServer.Addr)
+		defer wg.Done()
+
+		listener, err := listen(s.config.Server)
+		if err != nil {
+			errChan <- fmt.Errorf("failed to listen: %w", err)
+			return
+		}
 
-		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if s.certificate != nil {
+			logger.Logger.Infof("Starting HTTPS server on %s", listener.Addr())
+			err = s.httpServer.ServeTLS(listener, "", "")
+		} else {
+			logger.Logger.Infof("Starting server on %s", listener.Addr())
+			err = s.httpServer.Serve(listener)
+		}
+
+		if err != nil && err != http.ErrServerClosed {
 			errChan <- fmt.Errorf("failed to start server: %w", err)
 		}
 	}()
 
+	go func() {
+		defer wg.Done()
+		logger.Logger.Infof("Starting gRPC server on %s", s.grpcAddr)
+
+		listener, err := net.Listen("tcp", s.grpcAddr)
+		if err != nil {
+			errChan <- fmt.Errorf("failed to listen for gRPC: %w", err)
+			return
+		}
+
+		if err := s.grpcServer.Serve(listener); err != nil {
+			errChan <- fmt.Errorf("failed to start gRPC server: %w", err)
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(errChan)
+	}()
+
 	return errChan
 }
 
@@ -61,20 +145,68 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	return nil
 }
 
-func (s *Server) WaitForShutdownSignal() {
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	sig := <-quit
-	logger.Logger.Infof("Received shutdown signal: %v", sig)
+func (s *Server) ShutdownGRPC(ctx context.Context) error {
+	logger.Logger.Info("Shutting down gRPC server gracefully...")
+
+	s.healthServer.Shutdown()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		logger.Logger.Info("gRPC server stopped gracefully")
+		return nil
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+		return fmt.Errorf("gRPC server forced to shutdown: %w", ctx.Err())
+	}
+}
+
+// WaitForShutdownSignal returns on SIGINT or SIGTERM and calls reload for
+// every SIGHUP received until then.
+func (s *Server) WaitForShutdownSignal(reload func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	for sig := range signals {
+		if sig == syscall.SIGHUP {
+			logger.Logger.Info("Received SIGHUP, reloading configuration")
+			reload()
+			continue
+		}
+
+		logger.Logger.Infof("Received shutdown signal: %v", sig)
+		return
+	}
 }
 
 func (s *Server) GracefulShutdown(cleanup func() error) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Server.ShutdownTimeout)
 	defer cancel()
 
-	if err := s.Shutdown(ctx); err != nil {
-		logger.Logger.Errorf("Error during server shutdown: %v", err)
-	}
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		if err := s.Shutdown(ctx); err != nil {
+			logger.Logger.Errorf("Error during server shutdown: %v", err)
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		if err := s.ShutdownGRPC(ctx); err != nil {
+			logger.Logger.Errorf("Error during gRPC server shutdown: %v", err)
+		}
+	}()
+
+	wg.Wait()
 
 	if cleanup != nil {
 		if err := cleanup(); err != nil {
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rod1kutzyy/OnTrack/internal/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+)
+
+// startServer serves a router with a single /health route on a unix socket
+// and returns a client connected to it.
+func startServer(t *testing.T, serverConfig config.ServerConfig, transport *http.Transport) (*Server, *http.Client) {
+	t.Helper()
+
+	socket := filepath.Join(t.TempDir(), "api.sock")
+	serverConfig.Host = "127.0.0.1"
+	serverConfig.GRPCPort = "0"
+	serverConfig.Listen = config.ListenUnixPrefix + socket
+	serverConfig.ShutdownTimeout = time.Second
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/health", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	srv, err := NewServer(&config.Config{Server: serverConfig}, router, grpc.NewServer(), health.NewServer())
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+
+	errChan := srv.Start()
+	t.Cleanup(func() {
+		if err := srv.GracefulShutdown(nil); err != nil {
+			t.Errorf("GracefulShutdown failed: %v", err)
+		}
+		if err := <-errChan; err != nil {
+			t.Errorf("server failed: %v", err)
+		}
+	})
+
+	dial := func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+	}
+	transport.DialContext = dial
+	transport.DialTLSContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		return tls.Client(conn, transport.TLSClientConfig), nil
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		conn, err := net.Dial("unix", socket)
+		if err == nil {
+			conn.Close()
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server did not start listening: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return srv, &http.Client{Transport: transport, Timeout: 2 * time.Second}
+}
+
+func TestServerH2COnUnixSocket(t *testing.T) {
+	var protocols http.Protocols
+	protocols.SetUnencryptedHTTP2(true)
+
+	_, client := startServer(t, config.ServerConfig{H2C: true}, &http.Transport{Protocols: &protocols})
+
+	resp, err := client.Get("http://ontrack/health")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || resp.ProtoMajor != 2 {
+		t.Errorf("got %s over %s, want 200 over HTTP/2", resp.Status, resp.Proto)
+	}
+}
+
+func TestServerReloadsCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeCertificate(t, certFile, keyFile, 1)
+
+	srv, client := startServer(t, config.ServerConfig{
+		TLS: config.TLSConfig{CertFile: certFile, KeyFile: keyFile},
+	}, &http.Transport{
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+		DisableKeepAlives: true,
+	})
+
+	serial := func() int64 {
+		t.Helper()
+		resp, err := client.Get("https://ontrack/health")
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp.TLS.PeerCertificates[0].SerialNumber.Int64()
+	}
+
+	if got := serial(); got != 1 {
+		t.Fatalf("served certificate %d, want 1", got)
+	}
+
+	writeCertificate(t, certFile, keyFile, 2)
+	if err := srv.ReloadCertificate(); err != nil {
+		t.Fatalf("ReloadCertificate failed: %v", err)
+	}
+	if got := serial(); got != 2 {
+		t.Errorf("served certificate %d after reload, want 2", got)
+	}
+
+	// A broken renewal keeps the working certificate.
+	if err := os.WriteFile(certFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.ReloadCertificate(); err == nil {
+		t.Error("ReloadCertificate accepted an invalid certificate")
+	}
+	if got := serial(); got != 2 {
+		t.Errorf("served certificate %d after a failed reload, want 2", got)
+	}
+}
+
+func writeCertificate(t *testing.T, certFile, keyFile string, serial int64) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		DNSNames:     []string{"ontrack"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
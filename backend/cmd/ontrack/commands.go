@@ -0,0 +1,494 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rod1kutzyy/OnTrack/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultListLimit = 20
+	completionLimit  = 100
+	completionWait   = 2 * time.Second
+)
+
+type app struct {
+	stdout io.Writer
+	stderr io.Writer
+
+	configPath string
+	server     string
+	token      string
+	output     string
+
+	cfg    cliConfig
+	client *client.Client
+}
+
+func newRootCommand(stdout, stderr io.Writer) *cobra.Command {
+	a := &app{stdout: stdout, stderr: stderr}
+
+	root := &cobra.Command{
+		Use:           "ontrack",
+		Short:         "Manage OnTrack todos from the terminal",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// Completion requests do not parse flags themselves; completeTodoIDs
+			// sets up once the flags of the completed command are known.
+			if cmd.Name() == cobra.ShellCompRequestCmd || cmd.Name() == cobra.ShellCompNoDescRequestCmd {
+				return nil
+			}
+
+			return a.setup(cmd)
+		},
+	}
+
+	root.SetOut(stdout)
+	root.SetErr(stderr)
+
+	flags := root.PersistentFlags()
+	flags.StringVar(&a.configPath, "config", defaultConfigPath(), "config file")
+	flags.StringVar(&a.server, "server", "", "OnTrack server URL (default from config, $"+envServer+" or "+defaultServer+")")
+	flags.StringVar(&a.token, "token", "", "API token (default from config or $"+envToken+")")
+	flags.StringVarP(&a.output, "output", "o", "", "output format: table or json")
+
+	root.RegisterFlagCompletionFunc("output", cobra.FixedCompletions([]string{outputTable, outputJSON}, cobra.ShellCompDirectiveNoFileComp))
+
+	root.AddCommand(
+		a.newAddCommand(),
+		a.newListCommand(),
+		a.newSearchCommand(),
+		a.newDoneCommand(),
+		a.newEditCommand(),
+		a.newRemoveCommand(),
+		a.newConfigCommand(),
+	)
+
+	return root
+}
+
+// setup resolves the configuration with the precedence flags, environment,
+// config file, defaults.
+func (a *app) setup(cmd *cobra.Command) error {
+	cfg, err := loadConfig(a.configPath)
+	if err != nil {
+		return err
+	}
+
+	flags := cmd.Flags()
+	if flags.Changed("server") {
+		cfg.Server = a.server
+	}
+	if flags.Changed("token") {
+		cfg.Token = a.token
+	}
+	if flags.Changed("output") {
+		cfg.Output = a.output
+	}
+
+	if !validOutput(cfg.Output) {
+		return fmt.Errorf("invalid output format %q, use %s or %s", cfg.Output, outputTable, outputJSON)
+	}
+
+	c, err := client.New(cfg.Server, client.WithToken(cfg.Token), client.WithUserAgent("ontrack-cli"))
+	if err != nil {
+		return err
+	}
+
+	a.cfg = cfg
+	a.client = c
+
+	return nil
+}
+
+func (a *app) newAddCommand() *cobra.Command {
+	var description string
+
+	cmd := &cobra.Command{
+		Use:   "add <title>...",
+		Short: "Add a todo",
+		Example: `  ontrack add Buy milk
+  ontrack add "Write report" -d "Quarterly numbers"`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			req := client.CreateTodoRequest{Title: strings.Join(args, " ")}
+			if cmd.Flags().Changed("description") {
+				req.Description = &description
+			}
+
+			todo, err := a.client.CreateTodo(cmd.Context(), req)
+			if err != nil {
+				return err
+			}
+
+			return printTodo(a.stdout, a.cfg.Output, todo)
+		},
+	}
+
+	cmd.Flags().StringVarP(&description, "description", "d", "", "todo description")
+
+	return cmd
+}
+
+type listFlags struct {
+	done   bool
+	open   bool
+	search string
+	page   int
+	limit  int
+	all    bool
+}
+
+func (f *listFlags) register(cmd *cobra.Command, withSearch bool) {
+	flags := cmd.Flags()
+	flags.BoolVar(&f.done, "done", false, "only completed todos")
+	flags.BoolVar(&f.open, "open", false, "only open todos")
+	flags.IntVar(&f.page, "page", 1, "page to show")
+	flags.IntVarP(&f.limit, "limit", "n", defaultListLimit, "todos per page (at most 100)")
+	flags.BoolVarP(&f.all, "all", "a", false, "show all pages")
+
+	if withSearch {
+		flags.StringVarP(&f.search, "search", "s", "", "only todos whose title or description contains the text")
+	}
+
+	cmd.MarkFlagsMutuallyExclusive("done", "open")
+	cmd.MarkFlagsMutuallyExclusive("page", "all")
+}
+
+func (a *app) newListCommand() *cobra.Command {
+	var flags listFlags
+
+	cmd := &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List todos",
+		Example: `  ontrack ls --open
+  ontrack ls --done -s report -o json
+  ontrack ls --all`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return a.list(cmd.Context(), flags)
+		},
+	}
+
+	flags.register(cmd, true)
+
+	return cmd
+}
+
+func (a *app) newSearchCommand() *cobra.Command {
+	var flags listFlags
+
+	cmd := &cobra.Command{
+		Use:     "search <text>...",
+		Short:   "Search todos by title and description",
+		Example: `  ontrack search invoice --open`,
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flags.search = strings.Join(args, " ")
+			return a.list(cmd.Context(), flags)
+		},
+	}
+
+	flags.register(cmd, false)
+
+	return cmd
+}
+
+func (a *app) list(ctx context.Context, flags listFlags) error {
+	if flags.limit < 1 || flags.limit > client.MaxPageSize {
+		return fmt.Errorf("limit must be between 1 and %d", client.MaxPageSize)
+	}
+
+	opts := client.ListOptions{
+		Search: flags.search,
+		Page:   flags.page,
+		Limit:  flags.limit,
+	}
+
+	switch {
+	case flags.done:
+		opts.Completed = boolPtr(true)
+	case flags.open:
+		opts.Completed = boolPtr(false)
+	}
+
+	if !flags.all {
+		list, err := a.client.ListTodos(ctx, opts)
+		if err != nil {
+			return err
+		}
+
+		if err := printTodos(a.stdout, a.cfg.Output, list.Items); err != nil {
+			return err
+		}
+
+		if a.cfg.Output == outputTable {
+			pagination := list.Pagination
+			fmt.Fprintf(a.stderr, "Page %d of %d, %d todos\n", pagination.CurrentPage, pagination.TotalPages, pagination.Total)
+		}
+		return nil
+	}
+
+	var todos []client.Todo
+
+	opts.Page, opts.Limit = 1, client.MaxPageSize
+	for todo, err := range a.client.Todos(ctx, opts) {
+		if err != nil {
+			return err
+		}
+
+		todos = append(todos, todo)
+	}
+
+	return printTodos(a.stdout, a.cfg.Output, todos)
+}
+
+func (a *app) newDoneCommand() *cobra.Command {
+	var undo bool
+
+	cmd := &cobra.Command{
+		Use:   "done <id>...",
+		Short: "Mark todos as completed",
+		Example: `  ontrack done 3 4
+  ontrack done 3 --undo`,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: a.completeTodoIDs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, err := parseIDs(args)
+			if err != nil {
+				return err
+			}
+
+			completed := !undo
+
+			var todos []client.Todo
+			for _, id := range ids {
+				todo, err := a.client.UpdateTodo(cmd.Context(), id, client.UpdateTodoRequest{Completed: &completed})
+				if err != nil {
+					return fmt.Errorf("todo %d: %w", id, err)
+				}
+				todos = append(todos, *todo)
+			}
+
+			return printTodos(a.stdout, a.cfg.Output, todos)
+		},
+	}
+
+	cmd.Flags().BoolVar(&undo, "undo", false, "mark the todos as open again")
+
+	return cmd
+}
+
+func (a *app) newEditCommand() *cobra.Command {
+	var (
+		title       string
+		description string
+		done        bool
+		open        bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "edit <id>",
+		Short: "Change the title, description or status of a todo",
+		Example: `  ontrack edit 3 --title "Buy oat milk"
+  ontrack edit 3 -d "" --open`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: a.completeTodoIDs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, err := parseIDs(args)
+			if err != nil {
+				return err
+			}
+
+			var req client.UpdateTodoRequest
+			flags := cmd.Flags()
+
+			if flags.Changed("title") {
+				req.Title = &title
+			}
+			if flags.Changed("description") {
+				req.Description = &description
+			}
+			if done || open {
+				req.Completed = boolPtr(done)
+			}
+
+			if req.Title == nil && req.Description == nil && req.Completed == nil {
+				return errors.New("nothing to change, use --title, --description, --done or --open")
+			}
+
+			todo, err := a.client.UpdateTodo(cmd.Context(), ids[0], req)
+			if err != nil {
+				return err
+			}
+
+			return printTodo(a.stdout, a.cfg.Output, todo)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&title, "title", "t", "", "new title")
+	flags.StringVarP(&description, "description", "d", "", "new description")
+	flags.BoolVar(&done, "done", false, "mark as completed")
+	flags.BoolVar(&open, "open", false, "mark as open")
+	cmd.MarkFlagsMutuallyExclusive("done", "open")
+
+	return cmd
+}
+
+func (a *app) newRemoveCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "rm <id>...",
+		Aliases:           []string{"remove", "delete"},
+		Short:             "Delete todos",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: a.completeTodoIDs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, err := parseIDs(args)
+			if err != nil {
+				return err
+			}
+
+			deleted := []uint{}
+			for _, id := range ids {
+				if err := a.client.DeleteTodo(cmd.Context(), id); err != nil {
+					return fmt.Errorf("todo %d: %w", id, err)
+				}
+				deleted = append(deleted, id)
+
+				if a.cfg.Output == outputTable {
+					fmt.Fprintf(a.stdout, "Deleted todo %d\n", id)
+				}
+			}
+
+			if a.cfg.Output == outputJSON {
+				return printJSON(a.stdout, map[string][]uint{"deleted": deleted})
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func (a *app) newConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Show or change the CLI configuration",
+	}
+
+	show := &cobra.Command{
+		Use:   "show",
+		Short: "Show the effective configuration",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			token := "(not set)"
+			if a.cfg.Token != "" {
+				token = "(set)"
+			}
+
+			fmt.Fprintf(a.stdout, "config file: %s\n", a.configPath)
+			fmt.Fprintf(a.stdout, "server:      %s\n", a.cfg.Server)
+			fmt.Fprintf(a.stdout, "token:       %s\n", token)
+			fmt.Fprintf(a.stdout, "output:      %s\n", a.cfg.Output)
+			return nil
+		},
+	}
+
+	keys := []string{"server", "token", "output"}
+
+	set := &cobra.Command{
+		Use:       "set <key> <value>",
+		Short:     "Store a setting in the config file",
+		Example:   `  ontrack config set server https://ontrack.example.com`,
+		Args:      cobra.ExactArgs(2),
+		ValidArgs: keys,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Start from the file alone so that environment overrides are not persisted.
+			cfg, err := readConfigFile(a.configPath)
+			if err != nil {
+				return err
+			}
+
+			switch key, value := args[0], args[1]; key {
+			case "server":
+				cfg.Server = value
+			case "token":
+				cfg.Token = value
+			case "output":
+				if !validOutput(value) {
+					return fmt.Errorf("invalid output format %q, use %s or %s", value, outputTable, outputJSON)
+				}
+				cfg.Output = value
+			default:
+				return fmt.Errorf("unknown key %q, use one of %s", key, strings.Join(keys, ", "))
+			}
+
+			if err := saveConfig(a.configPath, cfg); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(a.stdout, "Saved %s to %s\n", args[0], a.configPath)
+			return nil
+		},
+	}
+
+	cmd.AddCommand(show, set)
+
+	return cmd
+}
+
+// completeTodoIDs offers the IDs of open todos, with their titles as
+// descriptions, for shell completion.
+func (a *app) completeTodoIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if a.client == nil {
+		if err := a.setup(cmd); err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), completionWait)
+	defer cancel()
+
+	list, err := a.client.ListTodos(ctx, client.ListOptions{Completed: boolPtr(false), Page: 1, Limit: completionLimit})
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var completions []string
+	for _, todo := range list.Items {
+		id := strconv.FormatUint(uint64(todo.ID), 10)
+		if strings.HasPrefix(id, toComplete) {
+			completions = append(completions, id+"\t"+truncate(todo.Title, maxTitleWidth))
+		}
+	}
+
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+func parseIDs(args []string) ([]uint, error) {
+	ids := make([]uint, len(args))
+
+	for i, arg := range args {
+		id, err := strconv.ParseUint(arg, 10, 32)
+		if err != nil || id == 0 {
+			return nil, fmt.Errorf("invalid todo id %q", arg)
+		}
+		ids[i] = uint(id)
+	}
+
+	return ids, nil
+}
+
+func boolPtr(value bool) *bool {
+	return &value
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rod1kutzyy/OnTrack/internal/dto"
+	"github.com/rod1kutzyy/OnTrack/internal/event"
+	"github.com/rod1kutzyy/OnTrack/internal/handler"
+	"github.com/rod1kutzyy/OnTrack/internal/logger"
+	"github.com/rod1kutzyy/OnTrack/internal/repository/memory"
+	"github.com/rod1kutzyy/OnTrack/internal/usecase"
+	"github.com/rod1kutzyy/OnTrack/internal/validator"
+	"github.com/rod1kutzyy/OnTrack/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+func TestMain(m *testing.M) {
+	if err := logger.InitWithOutput("error", io.Discard); err != nil {
+		panic(err)
+	}
+	gin.SetMode(gin.ReleaseMode)
+
+	os.Exit(m.Run())
+}
+
+// newTestServer serves the todo routes of the API from an in-memory
+// repository holding the given todos.
+func newTestServer(t *testing.T, titles ...string) string {
+	t.Helper()
+
+	todoRepo := memory.NewTodoRepository()
+	todoUseCase := usecase.NewTodoUseCase(todoRepo, memory.NewTransactor(todoRepo), event.NewBroker())
+	for _, title := range titles {
+		if _, err := todoUseCase.CreateTodo(context.Background(), dto.CreateTodoRequest{Title: title}); err != nil {
+			t.Fatalf("failed to seed todo: %v", err)
+		}
+	}
+
+	todoHandler := handler.NewTodoHandler(todoUseCase, validator.NewTodoValidator())
+
+	router := gin.New()
+	todos := router.Group("/api/v1/todos")
+	todos.POST("", todoHandler.CreateTodo)
+	todos.GET("", todoHandler.GetAllTodos)
+	todos.GET("/:id", todoHandler.GetTodoByID)
+	todos.PUT("/:id", todoHandler.UpdateTodo)
+	todos.DELETE("/:id", todoHandler.DeleteTodo)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	return server.URL
+}
+
+// run executes ontrack against server with a config file in a temporary
+// directory and returns what it printed.
+func run(t *testing.T, server string, args ...string) (stdout, stderr string, err error) {
+	t.Helper()
+
+	t.Setenv(envServer, "")
+	t.Setenv(envToken, "")
+
+	// Shells pass the global flags after __complete.
+	var complete []string
+	if len(args) > 0 && args[0] == cobra.ShellCompRequestCmd {
+		complete, args = args[:1], args[1:]
+	}
+
+	var out, errOut bytes.Buffer
+	cmd := newRootCommand(&out, &errOut)
+	cmd.SetArgs(slices.Concat(complete, []string{"--config", filepath.Join(t.TempDir(), "config.yaml"), "--server", server}, args))
+
+	err = cmd.ExecuteContext(context.Background())
+	if err != nil {
+		printError(&errOut, err)
+	}
+
+	return out.String(), errOut.String(), err
+}
+
+func decodeTodos(t *testing.T, stdout string) []client.Todo {
+	t.Helper()
+
+	var todos []client.Todo
+	if err := json.Unmarshal([]byte(stdout), &todos); err != nil {
+		t.Fatalf("failed to decode %q: %v", stdout, err)
+	}
+	return todos
+}
+
+func titles(todos []client.Todo) string {
+	var titles []string
+	for _, todo := range todos {
+		titles = append(titles, todo.Title)
+	}
+	return strings.Join(titles, ", ")
+}
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantErr    string
+		wantTitles string
+		wantStdout string
+	}{
+		{
+			name:       "ls",
+			args:       []string{"ls", "-o", "json"},
+			wantTitles: "Pay rent, Bake bread, Buy milk",
+		},
+		{
+			name:       "ls paged",
+			args:       []string{"ls", "-o", "json", "--page", "2", "-n", "2"},
+			wantTitles: "Buy milk",
+		},
+		{
+			name:       "ls all pages",
+			args:       []string{"ls", "-o", "json", "--all"},
+			wantTitles: "Pay rent, Bake bread, Buy milk",
+		},
+		{
+			name:       "ls open",
+			args:       []string{"ls", "-o", "json", "--open"},
+			wantTitles: "Pay rent, Bake bread, Buy milk",
+		},
+		{
+			name:       "ls done",
+			args:       []string{"ls", "-o", "json", "--done"},
+			wantTitles: "",
+		},
+		{
+			name:       "search",
+			args:       []string{"search", "-o", "json", "BREAD"},
+			wantTitles: "Bake bread",
+		},
+		{
+			name:       "ls table",
+			args:       []string{"ls", "-s", "milk"},
+			wantStdout: "ID  DONE  TITLE     CREATED",
+		},
+		{
+			name:    "ls with invalid limit",
+			args:    []string{"ls", "-n", "500"},
+			wantErr: "limit must be between 1 and 100",
+		},
+		{
+			name:       "add",
+			args:       []string{"add", "-o", "json", "Walk", "the", "dog"},
+			wantStdout: `"title": "Walk the dog"`,
+		},
+		{
+			name:    "add with invalid title",
+			args:    []string{"add", "12345"},
+			wantErr: "Request validation failed\n  title: Title cannot contain only digits",
+		},
+		{
+			name:       "done",
+			args:       []string{"done", "-o", "json", "1", "2"},
+			wantTitles: "Buy milk, Bake bread",
+		},
+		{
+			name:    "done missing",
+			args:    []string{"done", "1", "42"},
+			wantErr: "todo 42: Todo with ID 42 not found",
+		},
+		{
+			name:    "done with invalid id",
+			args:    []string{"done", "one"},
+			wantErr: `invalid todo id "one"`,
+		},
+		{
+			name:       "edit",
+			args:       []string{"edit", "-o", "json", "1", "--title", "Buy oat milk", "--done"},
+			wantStdout: `"title": "Buy oat milk"`,
+		},
+		{
+			name:    "edit without changes",
+			args:    []string{"edit", "1"},
+			wantErr: "nothing to change",
+		},
+		{
+			name:       "rm",
+			args:       []string{"rm", "1", "3"},
+			wantStdout: "Deleted todo 1\nDeleted todo 3\n",
+		},
+		{
+			name:    "rm missing",
+			args:    []string{"rm", "42"},
+			wantErr: "todo 42: Todo with ID 42 not found",
+		},
+		{
+			name:    "invalid output",
+			args:    []string{"ls", "-o", "yaml"},
+			wantErr: `invalid output format "yaml"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t, "Buy milk", "Bake bread", "Pay rent")
+
+			stdout, stderr, err := run(t, server, tt.args...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(stderr, tt.wantErr) {
+					t.Fatalf("error = %v, stderr %q, want %q", err, stderr, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ontrack %s failed: %v", strings.Join(tt.args, " "), err)
+			}
+
+			if tt.wantStdout != "" && !strings.Contains(stdout, tt.wantStdout) {
+				t.Errorf("stdout = %q, want it to contain %q", stdout, tt.wantStdout)
+			}
+			if tt.wantStdout == "" {
+				if got := titles(decodeTodos(t, stdout)); got != tt.wantTitles {
+					t.Errorf("todos = %q, want %q", got, tt.wantTitles)
+				}
+			}
+		})
+	}
+}
+
+func TestDoneAndUndo(t *testing.T) {
+	server := newTestServer(t, "Buy milk", "Bake bread")
+
+	if _, _, err := run(t, server, "done", "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, _, err := run(t, server, "ls", "-o", "json", "--done")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := titles(decodeTodos(t, stdout)); got != "Buy milk" {
+		t.Errorf("completed todos = %q, want %q", got, "Buy milk")
+	}
+
+	if _, _, err := run(t, server, "done", "--undo", "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, _, err = run(t, server, "ls", "-o", "json", "--done")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if todos := decodeTodos(t, stdout); len(todos) != 0 {
+		t.Errorf("completed todos after undo = %q, want none", titles(todos))
+	}
+}
+
+func TestCompleteTodoIDs(t *testing.T) {
+	server := newTestServer(t, "Buy milk", "Bake bread", "Pay rent")
+
+	if _, _, err := run(t, server, "done", "2"); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, _, err := run(t, server, cobra.ShellCompRequestCmd, "rm", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "3\tPay rent\n1\tBuy milk\n:4\n"
+	if !strings.HasPrefix(stdout, want) {
+		t.Errorf("completions = %q, want %q", stdout, want)
+	}
+}
+
+func TestConfigSet(t *testing.T) {
+	t.Setenv(envServer, "")
+	t.Setenv(envToken, "")
+
+	path := filepath.Join(t.TempDir(), "ontrack", "config.yaml")
+
+	execute := func(args ...string) string {
+		t.Helper()
+
+		var stdout bytes.Buffer
+		cmd := newRootCommand(&stdout, io.Discard)
+		cmd.SetArgs(append([]string{"--config", path}, args...))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("ontrack %s failed: %v", strings.Join(args, " "), err)
+		}
+		return stdout.String()
+	}
+
+	execute("config", "set", "server", "https://ontrack.example.com")
+	execute("config", "set", "token", "s3cret")
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("config file mode = %v, want 0600", perm)
+	}
+
+	show := execute("config", "show")
+	for _, want := range []string{"server:      https://ontrack.example.com", "token:       (set)", "output:      table"} {
+		if !strings.Contains(show, want) {
+			t.Errorf("config show = %q, want it to contain %q", show, want)
+		}
+	}
+	if strings.Contains(show, "s3cret") {
+		t.Errorf("config show printed the token: %q", show)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultServer = "http://localhost:8080"
+
+	envServer = "ONTRACK_SERVER"
+	envToken  = "ONTRACK_TOKEN"
+	envConfig = "ONTRACK_CONFIG"
+)
+
+// cliConfig is stored as YAML, by default in ~/.config/ontrack/config.yaml:
+//
+//	server: https://ontrack.example.com
+//	token: secret
+//	output: table
+type cliConfig struct {
+	Server string `yaml:"server,omitempty"`
+	Token  string `yaml:"token,omitempty"`
+	Output string `yaml:"output,omitempty"`
+}
+
+func defaultConfigPath() string {
+	if path := os.Getenv(envConfig); path != "" {
+		return path
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "ontrack", "config.yaml")
+}
+
+// loadConfig reads the config file, if any, and applies environment
+// overrides and defaults. Flags are applied on top by the caller.
+func loadConfig(path string) (cliConfig, error) {
+	cfg, err := readConfigFile(path)
+	if err != nil {
+		return cfg, err
+	}
+
+	if server := os.Getenv(envServer); server != "" {
+		cfg.Server = server
+	}
+
+	if token := os.Getenv(envToken); token != "" {
+		cfg.Token = token
+	}
+
+	if cfg.Server == "" {
+		cfg.Server = defaultServer
+	}
+
+	if cfg.Output == "" {
+		cfg.Output = outputTable
+	}
+
+	return cfg, nil
+}
+
+// readConfigFile reads the config file as is. A missing file is not an error.
+func readConfigFile(path string) (cliConfig, error) {
+	var cfg cliConfig
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+		case err != nil:
+			return cfg, fmt.Errorf("failed to read config file: %w", err)
+		default:
+			if err := yaml.Unmarshal(data, &cfg); err != nil {
+				return cfg, fmt.Errorf("invalid config file %s: %w", path, err)
+			}
+		}
+	}
+
+	return cfg, nil
+}
+
+func saveConfig(path string, cfg cliConfig) error {
+	if path == "" {
+		return errors.New("no config file location, use --config")
+	}
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	// The file may hold a token, so keep it private.
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	return nil
+}
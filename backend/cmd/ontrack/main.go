@@ -0,0 +1,40 @@
+// Command ontrack is a command-line client for the OnTrack REST API.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rod1kutzyy/OnTrack/pkg/client"
+)
+
+func main() {
+	cmd := newRootCommand(os.Stdout, os.Stderr)
+
+	if err := cmd.Execute(); err != nil {
+		printError(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// printError prints the message of API errors without the status and code,
+// followed by the invalid fields, one per line.
+func printError(w io.Writer, err error) {
+	message := err.Error()
+
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) {
+		message = strings.Replace(message, apiErr.Error(), apiErr.Message, 1)
+	}
+
+	fmt.Fprintln(w, "Error:", message)
+
+	if apiErr != nil {
+		for _, detail := range apiErr.Details {
+			fmt.Fprintf(w, "  %s: %s\n", detail.Field, detail.Message)
+		}
+	}
+}
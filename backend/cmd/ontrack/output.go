@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"github.com/rod1kutzyy/OnTrack/pkg/client"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+
+	maxTitleWidth = 60
+)
+
+func validOutput(output string) bool {
+	return output == outputTable || output == outputJSON
+}
+
+func printTodos(w io.Writer, output string, todos []client.Todo) error {
+	if output == outputJSON {
+		if todos == nil {
+			todos = []client.Todo{}
+		}
+		return printJSON(w, todos)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tDONE\tTITLE\tCREATED")
+
+	for _, todo := range todos {
+		done := " "
+		if todo.Completed {
+			done = "x"
+		}
+
+		fmt.Fprintf(tw, "%d\t[%s]\t%s\t%s\n", todo.ID, done, truncate(todo.Title, maxTitleWidth), todo.CreatedAt.Local().Format("2006-01-02 15:04"))
+	}
+
+	return tw.Flush()
+}
+
+func printTodo(w io.Writer, output string, todo *client.Todo) error {
+	if output == outputJSON {
+		return printJSON(w, todo)
+	}
+
+	return printTodos(w, output, []client.Todo{*todo})
+}
+
+func printJSON(w io.Writer, value any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+func truncate(s string, width int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+
+	return string([]rune(s)[:width-1]) + "…"
+}
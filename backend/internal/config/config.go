@@ -1,27 +1,102 @@
 package config
 
 import (
+	"cmp"
+	"errors"
 	"fmt"
+	"io/fs"
+	"maps"
+	"net/url"
 	"os"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
-	"sync"
+	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/sirupsen/logrus"
 )
 
 type Config struct {
 	Server   ServerConfig
 	Database DatabaseConfig
 	Logger   LoggerConfig
+	GraphQL  GraphQLConfig
+	Admin    AdminConfig
+	Tracing  TracingConfig
+
+	// options and sources record where the settings were taken from, for
+	// reloading and printing.
+	options Options
+	sources map[string]string
 }
 
 type ServerConfig struct {
 	Host        string
 	Port        string
+	GRPCPort    string
 	FrontedURLs []string
+	// DrainDelay is how long /ready reports shutting down before the servers
+	// stop accepting requests, so that load balancers can take the instance
+	// out of rotation first.
+	DrainDelay time.Duration
+	// ShutdownTimeout bounds how long the servers wait for requests in
+	// flight when shutting down.
+	ShutdownTimeout time.Duration
+
+	// ReadTimeout, WriteTimeout and IdleTimeout configure the HTTP server;
+	// zero means no timeout.
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+
+	// Listen selects where the HTTP server accepts connections: tcp on
+	// Host:Port, unix:<path> for a unix domain socket, or systemd for the
+	// first socket passed by systemd socket activation.
+	Listen string
+	TLS    TLSConfig
+	// H2C serves HTTP/2 without TLS, e.g. behind a proxy that terminates
+	// TLS and speaks HTTP/2 to its backends.
+	H2C bool
 }
 
+// Values of SERVER_LISTEN besides unix:<path>.
+const (
+	ListenTCP     = "tcp"
+	ListenSystemd = "systemd"
+
+	ListenUnixPrefix = "unix:"
+)
+
+// TLSConfig enables HTTPS when either file is set; validation then requires
+// the other one too. The files are read again on reload, so that renewed
+// certificates need no restart.
+type TLSConfig struct {
+	CertFile string
+	KeyFile  string
+}
+
+// Enabled reports whether the HTTP server serves HTTPS.
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != ""
+}
+
+// Database drivers selectable with DB_DRIVER.
+const (
+	DriverPostgres = "postgres"
+	// DriverSQLite stores todos in the file at DB_PATH, for single-user
+	// deployments without a database server.
+	DriverSQLite = "sqlite"
+	// DriverMemory keeps todos in memory for tests and demos; nothing is
+	// persisted.
+	DriverMemory = "memory"
+)
+
 type DatabaseConfig struct {
+	Driver string
+	// Path is the database file of the SQLite driver.
+	Path     string
 	Host     string
 	Port     string
 	User     string
@@ -30,42 +105,426 @@ type DatabaseConfig struct {
 	SSLMode  string
 }
 
+// Log formats selectable with LOG_FORMAT.
+const (
+	// LogFormatJSON writes every entry as an indented JSON object.
+	LogFormatJSON = "json"
+	// LogFormatCompact writes one JSON object per line, for log shippers.
+	LogFormatCompact = "compact"
+	// LogFormatText writes human-readable lines, colored on terminals.
+	LogFormatText = "text"
+)
+
+// Log outputs selectable with LOG_OUTPUT.
+const (
+	LogOutputStdout = "stdout"
+	LogOutputStderr = "stderr"
+	// LogOutputFile writes to LOG_FILE_PATH and rotates the file.
+	LogOutputFile = "file"
+)
+
 type LoggerConfig struct {
-	Level string
+	Level  string
+	Format string
+	Output string
+	File   LogFileConfig
+	// ReportCaller adds the calling function and file to every entry.
+	ReportCaller bool
+	// PackageLevels overrides Level for the packages whose import path ends
+	// with the key, e.g. "usecase" or "repository/sqlstore".
+	PackageLevels map[string]string
+	Redaction     RedactionConfig
 }
 
-var (
-	config *Config
-	once   sync.Once
+// Redaction modes selectable with LOG_REDACT_MODE.
+const (
+	RedactOff = "off"
+	// RedactDenylist masks sensitive fields and pattern matches in messages
+	// and field values.
+	RedactDenylist = "denylist"
+	// RedactAllowlist additionally masks every field that is not explicitly
+	// allowed, for strict environments.
+	RedactAllowlist = "allowlist"
 )
 
-func Load() (*Config, error) {
-	var err error
+// RedactionConfig masks secrets and personal data before log entries are
+// written. The settings extend the built-in rules of the logger package.
+type RedactionConfig struct {
+	Mode string
+	// Fields are masked when their name contains one of these, ignoring case.
+	Fields []string
+	// Patterns are regular expressions masked in messages and string values.
+	// When a pattern has groups, only the groups are masked.
+	Patterns []string
+	// AllowedFields are the fields kept in allowlist mode.
+	AllowedFields []string
+}
 
-	once.Do(func() {
-		err = godotenv.Load()
+// LogFileConfig configures the log file written with LOG_OUTPUT=file.
+type LogFileConfig struct {
+	Path string
+	// MaxSizeMB is the size at which the file is rotated.
+	MaxSizeMB int
+	// MaxAgeDays and MaxBackups limit the rotated files that are kept; zero
+	// keeps them all.
+	MaxAgeDays int
+	MaxBackups int
+	// Compress gzips rotated files.
+	Compress bool
+}
 
-		config = &Config{
-			Server: ServerConfig{
-				Host:        getEnv("SERVER_HOST", "0.0.0.0"),
-				Port:        getEnv("SERVER_PORT", "8080"),
-				FrontedURLs: strings.Split(getEnv("FRONTEND_URLS", "http://localhost:5173,http://127.0.0.1:5173"), ","),
-			},
-			Database: DatabaseConfig{
-				Host:     getEnv("DB_HOST", "db"),
-				Port:     getEnv("DB_PORT", "5432"),
-				User:     getEnv("DB_USER", "postgres"),
-				Password: getEnv("DB_PASSWORD", "password"),
-				Name:     getEnv("DB_NAME", "ontrack"),
-				SSLMode:  getEnv("DB_SSLMODE", "disable"),
-			},
-			Logger: LoggerConfig{
-				Level: getEnv("LOG_LEVEL", "info"),
+type GraphQLConfig struct {
+	MaxDepth      int
+	MaxComplexity int
+}
+
+// AdminConfig protects the /api/v1/admin endpoints. They are disabled while
+// Token is empty.
+type AdminConfig struct {
+	Token string
+}
+
+// Trace exporters selectable with TRACING_EXPORTER.
+const (
+	ExporterNone = "none"
+	// ExporterStdout prints spans as JSON, for local runs.
+	ExporterStdout = "stdout"
+	// ExporterOTLP sends spans over OTLP/HTTP to TRACING_OTLP_ENDPOINT.
+	ExporterOTLP = "otlp"
+)
+
+// TracingConfig configures OpenTelemetry tracing. Spans are only recorded
+// when an exporter other than none is selected.
+type TracingConfig struct {
+	Exporter     string
+	OTLPEndpoint string
+	ServiceName  string
+	// SampleRatio is the fraction of new traces that are recorded. Requests
+	// that carry a sampled traceparent are always recorded.
+	SampleRatio float64
+}
+
+// Options select the configuration sources besides the environment.
+type Options struct {
+	// File is a YAML or TOML config file. CONFIG_FILE is used when it is
+	// empty.
+	File string
+	// Overrides are key=value pairs from the command line, e.g.
+	// "server.port=8081".
+	Overrides []string
+}
+
+// Load builds the configuration from, in increasing precedence, the
+// defaults, the config file, the environment including .env, and the
+// overrides. It reports all malformed values at once; Validate checks the
+// values themselves.
+func Load(opts Options) (*Config, error) {
+	// .env is optional, e.g. when the binary runs outside backend/. It is
+	// read instead of loaded into the environment, so that a reload sees
+	// changes to it. Variables set in the environment win.
+	dotenv, err := godotenv.Read()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read .env: %w", err)
+	}
+	lookupEnv := func(key string) (string, string) {
+		if value := os.Getenv(key); value != "" {
+			return value, "env " + key
+		}
+		return dotenv[key], ".env " + key
+	}
+
+	cfg := defaults()
+	cfg.options = opts
+	cfg.sources = map[string]string{}
+
+	byKey := map[string]setting{}
+	for _, s := range settings(cfg) {
+		byKey[s.key] = s
+	}
+
+	var errs []error
+
+	configFile, _ := lookupEnv("CONFIG_FILE")
+	if file := cmp.Or(opts.File, configFile); file != "" {
+		values, err := readFile(file, byKey)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range slices.Sorted(maps.Keys(values)) {
+			errs = append(errs, cfg.set(byKey[key], values[key], "file "+file, key))
+		}
+	}
+
+	for _, s := range settings(cfg) {
+		if raw, source := lookupEnv(s.env); raw != "" {
+			errs = append(errs, cfg.set(s, raw, source, s.env))
+		}
+	}
+
+	for _, override := range opts.Overrides {
+		key, raw, ok := strings.Cut(override, "=")
+		s, known := byKey[key]
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Errorf("override %q must be key=value", override))
+		case !known:
+			errs = append(errs, fmt.Errorf("override %q: unknown setting %q", override, key))
+		default:
+			errs = append(errs, cfg.set(s, raw, "flag", key))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// set assigns raw to s and records where the value came from. name is the
+// setting as the source spells it, for the error message.
+func (c *Config) set(s setting, raw any, source, name string) error {
+	if err := s.value.set(raw); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	c.sources[s.key] = source
+
+	return nil
+}
+
+func defaults() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Host:        "0.0.0.0",
+			Port:        "8080",
+			GRPCPort:    "9090",
+			FrontedURLs: []string{"http://localhost:5173", "http://127.0.0.1:5173"},
+
+			ShutdownTimeout: 5 * time.Second,
+			ReadTimeout:     10 * time.Second,
+			WriteTimeout:    10 * time.Second,
+			IdleTimeout:     60 * time.Second,
+			Listen:          ListenTCP,
+		},
+		Database: DatabaseConfig{
+			Driver:   DriverPostgres,
+			Path:     "data/ontrack.db",
+			Host:     "db",
+			Port:     "5432",
+			User:     "postgres",
+			Password: "password",
+			Name:     "ontrack",
+			SSLMode:  "disable",
+		},
+		Logger: LoggerConfig{
+			Level:  "info",
+			Format: LogFormatJSON,
+			Output: LogOutputStdout,
+			File: LogFileConfig{
+				Path:       "logs/ontrack.log",
+				MaxSizeMB:  100,
+				MaxAgeDays: 28,
+				MaxBackups: 5,
 			},
+			ReportCaller:  true,
+			PackageLevels: map[string]string{},
+			Redaction:     RedactionConfig{Mode: RedactDenylist},
+		},
+		GraphQL: GraphQLConfig{
+			MaxDepth:      8,
+			MaxComplexity: 1000,
+		},
+		Tracing: TracingConfig{
+			Exporter:     ExporterNone,
+			OTLPEndpoint: "http://localhost:4318",
+			ServiceName:  "ontrack",
+			SampleRatio:  1,
+		},
+	}
+}
+
+// Validate reports all invalid settings at once.
+func (c *Config) Validate() error {
+	var errs []error
+
+	ports := []struct{ name, value string }{
+		{"SERVER_PORT", c.Server.Port},
+		{"GRPC_PORT", c.Server.GRPCPort},
+	}
+	if c.Database.Driver == DriverPostgres {
+		ports = append(ports, struct{ name, value string }{"DB_PORT", c.Database.Port})
+	}
+	for _, port := range ports {
+		if parsed, err := strconv.Atoi(port.value); err != nil || parsed < 1 || parsed > 65535 {
+			errs = append(errs, fmt.Errorf("%s must be a port number between 1 and 65535, got %q", port.name, port.value))
+		}
+	}
+
+	errs = append(errs, c.Server.validate()...)
+
+	// Unix sockets and systemd sockets leave SERVER_PORT unused.
+	if c.Server.Listen == ListenTCP && c.Server.Port == c.Server.GRPCPort {
+		errs = append(errs, fmt.Errorf("SERVER_PORT and GRPC_PORT must differ, both are %q", c.Server.Port))
+	}
+
+	switch c.Database.Driver {
+	case DriverPostgres:
+		errs = append(errs, c.Database.validatePostgres()...)
+	case DriverSQLite:
+		if c.Database.Path == "" {
+			errs = append(errs, errors.New("DB_PATH must not be empty"))
+		}
+	case DriverMemory:
+	default:
+		errs = append(errs, fmt.Errorf("DB_DRIVER %q is not supported, use %s, %s or %s", c.Database.Driver, DriverPostgres, DriverSQLite, DriverMemory))
+	}
+
+	errs = append(errs, c.Logger.validate()...)
+
+	if c.GraphQL.MaxDepth < 1 {
+		errs = append(errs, fmt.Errorf("GRAPHQL_MAX_DEPTH must be positive, got %d", c.GraphQL.MaxDepth))
+	}
+
+	if c.GraphQL.MaxComplexity < 1 {
+		errs = append(errs, fmt.Errorf("GRAPHQL_MAX_COMPLEXITY must be positive, got %d", c.GraphQL.MaxComplexity))
+	}
+
+	errs = append(errs, c.Tracing.validate()...)
+
+	return errors.Join(errs...)
+}
+
+func (c *ServerConfig) validate() []error {
+	var errs []error
+
+	timeouts := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"SHUTDOWN_DRAIN_DELAY", c.DrainDelay},
+		{"SERVER_READ_TIMEOUT", c.ReadTimeout},
+		{"SERVER_WRITE_TIMEOUT", c.WriteTimeout},
+		{"SERVER_IDLE_TIMEOUT", c.IdleTimeout},
+	}
+	for _, timeout := range timeouts {
+		if timeout.value < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative, got %s", timeout.name, timeout.value))
+		}
+	}
+
+	if c.ShutdownTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("SHUTDOWN_TIMEOUT must be positive, got %s", c.ShutdownTimeout))
+	}
+
+	switch {
+	case c.Listen == ListenTCP, c.Listen == ListenSystemd:
+	case strings.HasPrefix(c.Listen, ListenUnixPrefix) && len(c.Listen) > len(ListenUnixPrefix):
+	default:
+		errs = append(errs, fmt.Errorf("SERVER_LISTEN %q is not supported, use %s, %s<path> or %s", c.Listen, ListenTCP, ListenUnixPrefix, ListenSystemd))
+	}
+
+	if c.TLS.Enabled() && (c.TLS.CertFile == "" || c.TLS.KeyFile == "") {
+		errs = append(errs, errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together"))
+	}
+
+	if c.TLS.Enabled() && c.H2C {
+		errs = append(errs, errors.New("SERVER_H2C cannot be combined with TLS, which negotiates HTTP/2 itself"))
+	}
+
+	return errs
+}
+
+func (c *LoggerConfig) validate() []error {
+	var errs []error
+
+	if _, err := logrus.ParseLevel(c.Level); err != nil {
+		errs = append(errs, fmt.Errorf("LOG_LEVEL %q is not a valid log level", c.Level))
+	}
+
+	for pkg, level := range c.PackageLevels {
+		if _, err := logrus.ParseLevel(level); err != nil {
+			errs = append(errs, fmt.Errorf("LOG_PACKAGE_LEVELS has invalid level %q for package %q, use package=level", level, pkg))
+		}
+	}
+
+	switch c.Format {
+	case LogFormatJSON, LogFormatCompact, LogFormatText:
+	default:
+		errs = append(errs, fmt.Errorf("LOG_FORMAT %q is not supported, use %s, %s or %s", c.Format, LogFormatJSON, LogFormatCompact, LogFormatText))
+	}
+
+	switch c.Output {
+	case LogOutputStdout, LogOutputStderr:
+	case LogOutputFile:
+		if c.File.Path == "" {
+			errs = append(errs, errors.New("LOG_FILE_PATH must not be empty"))
+		}
+		if c.File.MaxSizeMB < 1 {
+			errs = append(errs, fmt.Errorf("LOG_FILE_MAX_SIZE_MB must be positive, got %d", c.File.MaxSizeMB))
 		}
-	})
+		if c.File.MaxAgeDays < 0 || c.File.MaxBackups < 0 {
+			errs = append(errs, errors.New("LOG_FILE_MAX_AGE_DAYS and LOG_FILE_MAX_BACKUPS must not be negative"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("LOG_OUTPUT %q is not supported, use %s, %s or %s", c.Output, LogOutputStdout, LogOutputStderr, LogOutputFile))
+	}
+
+	switch c.Redaction.Mode {
+	case RedactOff, RedactDenylist, RedactAllowlist:
+	default:
+		errs = append(errs, fmt.Errorf("LOG_REDACT_MODE %q is not supported, use %s, %s or %s", c.Redaction.Mode, RedactOff, RedactDenylist, RedactAllowlist))
+	}
 
-	return config, err
+	for _, pattern := range c.Redaction.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errs = append(errs, fmt.Errorf("LOG_REDACT_PATTERNS has invalid pattern %q: %w", pattern, err))
+		}
+	}
+
+	return errs
+}
+
+func (c *TracingConfig) validate() []error {
+	var errs []error
+
+	switch c.Exporter {
+	case ExporterNone, ExporterStdout:
+	case ExporterOTLP:
+		if u, err := url.Parse(c.OTLPEndpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("TRACING_OTLP_ENDPOINT must be an http or https URL, got %q", c.OTLPEndpoint))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("TRACING_EXPORTER %q is not supported, use %s, %s or %s", c.Exporter, ExporterNone, ExporterStdout, ExporterOTLP))
+	}
+
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("TRACING_SAMPLE_RATIO must be between 0 and 1, got %g", c.SampleRatio))
+	}
+
+	return errs
+}
+
+func (c *DatabaseConfig) validatePostgres() []error {
+	var errs []error
+
+	required := []struct{ name, value string }{
+		{"DB_HOST", c.Host},
+		{"DB_USER", c.User},
+		{"DB_NAME", c.Name},
+	}
+	for _, setting := range required {
+		if setting.value == "" {
+			errs = append(errs, fmt.Errorf("%s must not be empty", setting.name))
+		}
+	}
+
+	switch c.SSLMode {
+	case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+	default:
+		errs = append(errs, fmt.Errorf("DB_SSLMODE %q is not a valid sslmode", c.SSLMode))
+	}
+
+	return errs
 }
 
 func (c *DatabaseConfig) GetDSN() string {
@@ -74,11 +533,3 @@ func (c *DatabaseConfig) GetDSN() string {
 		c.Host, c.Port, c.User, c.Password, c.Name, c.SSLMode,
 	)
 }
-
-func getEnv(key, defaultValue string) string {
-	if val := os.Getenv(key); val != "" {
-		return val
-	}
-
-	return defaultValue
-}
@@ -0,0 +1,266 @@
+package config_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rod1kutzyy/OnTrack/internal/config"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	file := writeFile(t, "ontrack.yaml", `
+server:
+  port: 8081
+  grpc_port: 9091
+  drain_delay: 5s
+  frontend_urls: [https://todo.example.com]
+logger:
+  package_levels:
+    usecase: debug
+graphql:
+  max_depth: 4
+`)
+	t.Setenv("GRPC_PORT", "9092")
+	t.Setenv("GRAPHQL_MAX_DEPTH", "6")
+
+	cfg, err := config.Load(config.Options{File: file, Overrides: []string{"graphql.max_depth=7"}})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.Server.Host != "0.0.0.0" {
+		t.Errorf("server.host = %q, want the default", cfg.Server.Host)
+	}
+	if cfg.Server.Port != "8081" || cfg.Server.DrainDelay != 5*time.Second {
+		t.Errorf("server.port = %q, drain_delay = %s, want the file values", cfg.Server.Port, cfg.Server.DrainDelay)
+	}
+	if !slices.Equal(cfg.Server.FrontedURLs, []string{"https://todo.example.com"}) {
+		t.Errorf("server.frontend_urls = %v, want the file value", cfg.Server.FrontedURLs)
+	}
+	if cfg.Logger.PackageLevels["usecase"] != "debug" {
+		t.Errorf("logger.package_levels = %v, want the file value", cfg.Logger.PackageLevels)
+	}
+	if cfg.Server.GRPCPort != "9092" {
+		t.Errorf("server.grpc_port = %q, want the environment to win over the file", cfg.Server.GRPCPort)
+	}
+	if cfg.GraphQL.MaxDepth != 7 {
+		t.Errorf("graphql.max_depth = %d, want the override to win", cfg.GraphQL.MaxDepth)
+	}
+}
+
+func TestLoadTOML(t *testing.T) {
+	t.Setenv("CONFIG_FILE", writeFile(t, "ontrack.toml", `
+[database]
+driver = "sqlite"
+path = "/var/lib/ontrack/ontrack.db"
+
+[tracing]
+sample_ratio = 0.25
+`))
+
+	cfg, err := config.Load(config.Options{})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.Database.Driver != config.DriverSQLite || cfg.Database.Path != "/var/lib/ontrack/ontrack.db" || cfg.Tracing.SampleRatio != 0.25 {
+		t.Errorf("got database %+v and sample ratio %g, want the file values", cfg.Database, cfg.Tracing.SampleRatio)
+	}
+}
+
+func TestLoadReportsAllErrors(t *testing.T) {
+	file := writeFile(t, "ontrack.yaml", "server:\n  drain_delay: 5\n")
+	t.Setenv("GRAPHQL_MAX_DEPTH", "deep")
+
+	_, err := config.Load(config.Options{File: file, Overrides: []string{"logger.caller=maybe", "server.port", "server.prot=1"}})
+	if err == nil {
+		t.Fatal("Load accepted invalid settings")
+	}
+
+	for _, want := range []string{
+		`server.drain_delay: expected a duration such as 10s, got 5`,
+		`GRAPHQL_MAX_DEPTH: expected an integer, got "deep"`,
+		`logger.caller: expected true or false, got "maybe"`,
+		`override "server.port" must be key=value`,
+		`override "server.prot=1": unknown setting "server.prot"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestLoadRejectsUnknownFileSettings(t *testing.T) {
+	file := writeFile(t, "ontrack.yaml", "databse:\n  host: db\nserver:\n  prot: 8081\n")
+
+	_, err := config.Load(config.Options{File: file})
+	for _, want := range []string{`unknown setting "databse.host"`, `unknown setting "server.prot"`} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("error %v does not mention %q", err, want)
+		}
+	}
+}
+
+func TestLoadWithoutDotEnv(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	if _, err := config.Load(config.Options{}); err != nil {
+		t.Errorf("Load failed without a .env file: %v", err)
+	}
+}
+
+func TestPrintMasksSecrets(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "hunter2")
+
+	cfg, err := config.Load(config.Options{Overrides: []string{"admin.token=s3cret"}})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := cfg.Print(&out); err != nil {
+		t.Fatalf("Print failed: %v", err)
+	}
+
+	printed := out.String()
+	if strings.Contains(printed, "hunter2") || strings.Contains(printed, "s3cret") {
+		t.Errorf("Print shows secrets:\n%s", printed)
+	}
+	for _, want := range []string{"password: '********' # env DB_PASSWORD", "token: '********' # flag", "port: \"8080\"\n"} {
+		if !strings.Contains(printed, want) {
+			t.Errorf("Print output does not contain %q:\n%s", want, printed)
+		}
+	}
+
+	// The output is a valid config file.
+	if _, err := config.Load(config.Options{File: writeFile(t, "printed.yaml", printed)}); err != nil {
+		t.Errorf("printed configuration does not load: %v", err)
+	}
+}
+
+func TestReloader(t *testing.T) {
+	path := writeFile(t, "ontrack.yaml", "logger:\n  level: info\n")
+
+	cfg, err := config.Load(config.Options{File: path})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	reloader := config.NewReloader(cfg)
+	var applied []string
+	reloader.Subscribe("logger", func(cfg *config.Config) error {
+		applied = append(applied, cfg.Logger.Level)
+		return nil
+	})
+
+	rewrite := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rewrite("logger:\n  level: debug\nserver:\n  port: 8081\n")
+	result, err := reloader.Reload()
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if !slices.Equal(result.Changed, []string{"server.port", "logger.level"}) || !slices.Equal(result.NeedsRestart, []string{"server.port"}) {
+		t.Errorf("got %+v, want server.port and logger.level changed and server.port to need a restart", result)
+	}
+	if reloader.Current().Logger.Level != "debug" || !slices.Equal(applied, []string{"debug"}) {
+		t.Errorf("current level %q, subscriber saw %v, want debug", reloader.Current().Logger.Level, applied)
+	}
+
+	// An invalid configuration is rejected before subscribers see it.
+	rewrite("logger:\n  level: loud\n")
+	if _, err := reloader.Reload(); err == nil || !strings.Contains(err.Error(), `LOG_LEVEL "loud"`) {
+		t.Errorf("got %v, want the validation error", err)
+	}
+	if reloader.Current().Logger.Level != "debug" || len(applied) != 1 {
+		t.Errorf("current level %q, subscriber saw %v after an invalid reload", reloader.Current().Logger.Level, applied)
+	}
+
+	// A configuration a subscriber fails to apply is rolled back.
+	reloader.Subscribe("cors", func(*config.Config) error {
+		return errors.New("boom")
+	})
+	rewrite("logger:\n  level: warn\n")
+	if _, err := reloader.Reload(); err == nil || err.Error() != "configuration not reloaded: cors: boom" {
+		t.Errorf("got %v, want the failing subscriber's error", err)
+	}
+	if !slices.Equal(applied, []string{"debug", "warn", "debug"}) {
+		t.Errorf("subscriber saw %v, want warn rolled back to debug", applied)
+	}
+	if reloader.Current().Logger.Level != "debug" {
+		t.Errorf("current level %q after a failed reload, want debug", reloader.Current().Logger.Level)
+	}
+}
+
+func TestValidateServer(t *testing.T) {
+	cfg, err := config.Load(config.Options{Overrides: []string{
+		"server.listen=udp",
+		"server.read_timeout=-1s",
+		"server.shutdown_timeout=0s",
+		"server.tls.cert_file=tls.crt",
+		"server.h2c=true",
+	}})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	err = cfg.Validate()
+	for _, want := range []string{
+		`SERVER_LISTEN "udp" is not supported`,
+		"SERVER_READ_TIMEOUT must not be negative",
+		"SHUTDOWN_TIMEOUT must be positive",
+		"TLS_CERT_FILE and TLS_KEY_FILE must be set together",
+		"SERVER_H2C cannot be combined with TLS",
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("error %v does not mention %q", err, want)
+		}
+	}
+
+	for _, listen := range []string{"tcp", "systemd", "unix:/run/ontrack/api.sock"} {
+		cfg, err := config.Load(config.Options{Overrides: []string{"server.listen=" + listen}})
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("SERVER_LISTEN=%s is invalid: %v", listen, err)
+		}
+	}
+
+	for listen, wantConflict := range map[string]bool{"tcp": true, "systemd": false, "unix:/run/ontrack/api.sock": false} {
+		cfg, err := config.Load(config.Options{Overrides: []string{
+			"server.listen=" + listen,
+			"server.port=9090",
+			"server.grpc_port=9090",
+		}})
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		err = cfg.Validate()
+		if gotConflict := err != nil && strings.Contains(err.Error(), "must differ"); gotConflict != wantConflict {
+			t.Errorf("SERVER_LISTEN=%s: port conflict reported = %v, want %v (error %v)", listen, gotConflict, wantConflict, err)
+		}
+	}
+}
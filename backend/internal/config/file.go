@@ -0,0 +1,126 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const maskedSecret = "********"
+
+// readFile parses a YAML or TOML config file, chosen by its extension, and
+// returns its values by setting key. Keys that name no setting are errors,
+// so that typos do not go unnoticed.
+func readFile(path string, known map[string]setting) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var tree map[string]any
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	case ".toml":
+		err = toml.Unmarshal(data, &tree)
+	default:
+		return nil, fmt.Errorf("config file %s must be .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	values := map[string]any{}
+	if err := flatten(tree, "", known, values); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	return values, nil
+}
+
+func flatten(tree map[string]any, prefix string, known map[string]setting, values map[string]any) error {
+	var errs []error
+
+	for _, name := range slices.Sorted(maps.Keys(tree)) {
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		if _, ok := known[key]; ok {
+			values[key] = tree[name]
+			continue
+		}
+
+		if nested, ok := tree[name].(map[string]any); ok {
+			errs = append(errs, flatten(nested, key, known, values))
+			continue
+		}
+
+		errs = append(errs, fmt.Errorf("unknown setting %q", key))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Print writes the configuration as a YAML config file with secrets masked.
+// Settings that are not defaults are commented with their source.
+func (c *Config) Print(w io.Writer) error {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+
+	for _, s := range settings(c) {
+		parent := root
+		parts := strings.Split(s.key, ".")
+		for _, part := range parts[:len(parts)-1] {
+			parent = child(parent, part)
+		}
+
+		value := s.value.get()
+		if s.secret && value != "" {
+			value = maskedSecret
+		}
+
+		node := &yaml.Node{}
+		if err := node.Encode(value); err != nil {
+			return fmt.Errorf("failed to encode %s: %w", s.key, err)
+		}
+		key := &yaml.Node{Kind: yaml.ScalarNode, Value: parts[len(parts)-1]}
+		if source := c.sources[s.key]; source != "" {
+			// On the key, so that lists and tables are commented on their
+			// first line.
+			key.LineComment = source
+		}
+
+		parent.Content = append(parent.Content, key, node)
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
+
+// child returns the mapping stored under key in parent, adding it if needed.
+func child(parent *yaml.Node, key string) *yaml.Node {
+	for i := 0; i < len(parent.Content); i += 2 {
+		if parent.Content[i].Value == key {
+			return parent.Content[i+1]
+		}
+	}
+
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	parent.Content = append(parent.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, node)
+
+	return node
+}
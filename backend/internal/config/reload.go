@@ -0,0 +1,104 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Subscriber applies a reloaded configuration, e.g. by reconfiguring the
+// logger.
+type Subscriber func(cfg *Config) error
+
+type subscriber struct {
+	name string
+	fn   Subscriber
+}
+
+// Reloader holds the configuration in effect and reloads it from the sources
+// it was first loaded from. It is safe for concurrent use.
+type Reloader struct {
+	mu          sync.Mutex
+	current     atomic.Pointer[Config]
+	subscribers []subscriber
+}
+
+// ReloadResult lists the settings that changed by key.
+type ReloadResult struct {
+	Changed []string
+	// NeedsRestart are the changed settings that only take effect once the
+	// service restarts, such as ports.
+	NeedsRestart []string
+}
+
+func NewReloader(cfg *Config) *Reloader {
+	r := &Reloader{}
+	r.current.Store(cfg)
+
+	return r
+}
+
+// Current returns the configuration in effect.
+func (r *Reloader) Current() *Config {
+	return r.current.Load()
+}
+
+// Subscribe registers fn to be called, in registration order, with every
+// reloaded configuration.
+func (r *Reloader) Subscribe(name string, fn Subscriber) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.subscribers = append(r.subscribers, subscriber{name: name, fn: fn})
+}
+
+// Reload loads and validates the configuration again and hands it to every
+// subscriber. It becomes current only when all of them apply it; an invalid
+// configuration, or one a subscriber fails to apply, is rejected as a whole,
+// the subscribers that already applied it are handed the current one again
+// and it stays in effect.
+func (r *Reloader) Reload() (ReloadResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old := r.current.Load()
+
+	cfg, err := Load(old.options)
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		return ReloadResult{}, fmt.Errorf("configuration not reloaded: %w", err)
+	}
+
+	var result ReloadResult
+	newSettings := settings(cfg)
+	for i, s := range settings(old) {
+		if reflect.DeepEqual(s.value.get(), newSettings[i].value.get()) {
+			continue
+		}
+		result.Changed = append(result.Changed, s.key)
+		if !s.reloadable {
+			result.NeedsRestart = append(result.NeedsRestart, s.key)
+		}
+	}
+
+	for i, sub := range r.subscribers {
+		if err := sub.fn(cfg); err != nil {
+			errs := []error{fmt.Errorf("configuration not reloaded: %s: %w", sub.name, err)}
+			for _, applied := range slices.Backward(r.subscribers[:i]) {
+				if err := applied.fn(old); err != nil {
+					errs = append(errs, fmt.Errorf("failed to roll back %s: %w", applied.name, err))
+				}
+			}
+			return ReloadResult{}, errors.Join(errs...)
+		}
+	}
+
+	r.current.Store(cfg)
+
+	return result, nil
+}
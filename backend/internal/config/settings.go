@@ -0,0 +1,260 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// setting binds a key of the config file, e.g. "server.port", and an
+// environment variable to a field of Config.
+type setting struct {
+	key string
+	env string
+	// secret settings are masked when the configuration is printed.
+	secret bool
+	// reloadable settings take effect on reload, the others on restart.
+	reloadable bool
+	value      value
+}
+
+// value is a typed field of Config. set accepts the value from a config
+// file, where it may already be typed, or a string from the environment or
+// the command line.
+type value interface {
+	set(raw any) error
+	get() any
+}
+
+// settings lists every setting of c in the order they are printed.
+func settings(c *Config) []setting {
+	return []setting{
+		{key: "server.host", env: "SERVER_HOST", value: stringValue{&c.Server.Host}},
+		{key: "server.port", env: "SERVER_PORT", value: stringValue{&c.Server.Port}},
+		{key: "server.grpc_port", env: "GRPC_PORT", value: stringValue{&c.Server.GRPCPort}},
+		{key: "server.frontend_urls", env: "FRONTEND_URLS", reloadable: true, value: listValue{&c.Server.FrontedURLs, ","}},
+		{key: "server.drain_delay", env: "SHUTDOWN_DRAIN_DELAY", reloadable: true, value: durationValue{&c.Server.DrainDelay}},
+		{key: "server.shutdown_timeout", env: "SHUTDOWN_TIMEOUT", value: durationValue{&c.Server.ShutdownTimeout}},
+		{key: "server.read_timeout", env: "SERVER_READ_TIMEOUT", value: durationValue{&c.Server.ReadTimeout}},
+		{key: "server.write_timeout", env: "SERVER_WRITE_TIMEOUT", value: durationValue{&c.Server.WriteTimeout}},
+		{key: "server.idle_timeout", env: "SERVER_IDLE_TIMEOUT", value: durationValue{&c.Server.IdleTimeout}},
+		{key: "server.listen", env: "SERVER_LISTEN", value: stringValue{&c.Server.Listen}},
+		{key: "server.tls.cert_file", env: "TLS_CERT_FILE", value: stringValue{&c.Server.TLS.CertFile}},
+		{key: "server.tls.key_file", env: "TLS_KEY_FILE", value: stringValue{&c.Server.TLS.KeyFile}},
+		{key: "server.h2c", env: "SERVER_H2C", value: boolValue{&c.Server.H2C}},
+
+		{key: "database.driver", env: "DB_DRIVER", value: stringValue{&c.Database.Driver}},
+		{key: "database.path", env: "DB_PATH", value: stringValue{&c.Database.Path}},
+		{key: "database.host", env: "DB_HOST", value: stringValue{&c.Database.Host}},
+		{key: "database.port", env: "DB_PORT", value: stringValue{&c.Database.Port}},
+		{key: "database.user", env: "DB_USER", value: stringValue{&c.Database.User}},
+		{key: "database.password", env: "DB_PASSWORD", secret: true, value: stringValue{&c.Database.Password}},
+		{key: "database.name", env: "DB_NAME", value: stringValue{&c.Database.Name}},
+		{key: "database.sslmode", env: "DB_SSLMODE", value: stringValue{&c.Database.SSLMode}},
+
+		{key: "logger.level", env: "LOG_LEVEL", reloadable: true, value: stringValue{&c.Logger.Level}},
+		{key: "logger.format", env: "LOG_FORMAT", reloadable: true, value: stringValue{&c.Logger.Format}},
+		{key: "logger.output", env: "LOG_OUTPUT", reloadable: true, value: stringValue{&c.Logger.Output}},
+		{key: "logger.file.path", env: "LOG_FILE_PATH", reloadable: true, value: stringValue{&c.Logger.File.Path}},
+		{key: "logger.file.max_size_mb", env: "LOG_FILE_MAX_SIZE_MB", reloadable: true, value: intValue{&c.Logger.File.MaxSizeMB}},
+		{key: "logger.file.max_age_days", env: "LOG_FILE_MAX_AGE_DAYS", reloadable: true, value: intValue{&c.Logger.File.MaxAgeDays}},
+		{key: "logger.file.max_backups", env: "LOG_FILE_MAX_BACKUPS", reloadable: true, value: intValue{&c.Logger.File.MaxBackups}},
+		{key: "logger.file.compress", env: "LOG_FILE_COMPRESS", reloadable: true, value: boolValue{&c.Logger.File.Compress}},
+		{key: "logger.caller", env: "LOG_CALLER", reloadable: true, value: boolValue{&c.Logger.ReportCaller}},
+		{key: "logger.package_levels", env: "LOG_PACKAGE_LEVELS", reloadable: true, value: mapValue{&c.Logger.PackageLevels}},
+		{key: "logger.redaction.mode", env: "LOG_REDACT_MODE", reloadable: true, value: stringValue{&c.Logger.Redaction.Mode}},
+		{key: "logger.redaction.fields", env: "LOG_REDACT_FIELDS", reloadable: true, value: listValue{&c.Logger.Redaction.Fields, ","}},
+		// Regular expressions contain commas, e.g. in {2,}.
+		{key: "logger.redaction.patterns", env: "LOG_REDACT_PATTERNS", reloadable: true, value: listValue{&c.Logger.Redaction.Patterns, ";"}},
+		{key: "logger.redaction.allowed_fields", env: "LOG_REDACT_ALLOWED_FIELDS", reloadable: true, value: listValue{&c.Logger.Redaction.AllowedFields, ","}},
+
+		{key: "graphql.max_depth", env: "GRAPHQL_MAX_DEPTH", value: intValue{&c.GraphQL.MaxDepth}},
+		{key: "graphql.max_complexity", env: "GRAPHQL_MAX_COMPLEXITY", value: intValue{&c.GraphQL.MaxComplexity}},
+
+		{key: "admin.token", env: "ADMIN_TOKEN", secret: true, value: stringValue{&c.Admin.Token}},
+
+		{key: "tracing.exporter", env: "TRACING_EXPORTER", value: stringValue{&c.Tracing.Exporter}},
+		{key: "tracing.otlp_endpoint", env: "TRACING_OTLP_ENDPOINT", value: stringValue{&c.Tracing.OTLPEndpoint}},
+		{key: "tracing.service_name", env: "TRACING_SERVICE_NAME", value: stringValue{&c.Tracing.ServiceName}},
+		{key: "tracing.sample_ratio", env: "TRACING_SAMPLE_RATIO", value: floatValue{&c.Tracing.SampleRatio}},
+	}
+}
+
+type stringValue struct{ p *string }
+
+func (v stringValue) set(raw any) error {
+	switch raw := raw.(type) {
+	case string:
+		*v.p = raw
+	case int, int64, float64, bool:
+		// Unquoted numbers in config files, e.g. port: 8080.
+		*v.p = fmt.Sprint(raw)
+	default:
+		return fmt.Errorf("expected a string, got %v", raw)
+	}
+
+	return nil
+}
+
+func (v stringValue) get() any { return *v.p }
+
+type intValue struct{ p *int }
+
+func (v intValue) set(raw any) error {
+	switch raw := raw.(type) {
+	case int:
+		*v.p = raw
+	case int64:
+		*v.p = int(raw)
+	case string:
+		parsed, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("expected an integer, got %q", raw)
+		}
+		*v.p = parsed
+	default:
+		return fmt.Errorf("expected an integer, got %v", raw)
+	}
+
+	return nil
+}
+
+func (v intValue) get() any { return *v.p }
+
+type floatValue struct{ p *float64 }
+
+func (v floatValue) set(raw any) error {
+	switch raw := raw.(type) {
+	case float64:
+		*v.p = raw
+	case int:
+		*v.p = float64(raw)
+	case int64:
+		*v.p = float64(raw)
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return fmt.Errorf("expected a number, got %q", raw)
+		}
+		*v.p = parsed
+	default:
+		return fmt.Errorf("expected a number, got %v", raw)
+	}
+
+	return nil
+}
+
+func (v floatValue) get() any { return *v.p }
+
+type boolValue struct{ p *bool }
+
+func (v boolValue) set(raw any) error {
+	switch raw := raw.(type) {
+	case bool:
+		*v.p = raw
+	case string:
+		parsed, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("expected true or false, got %q", raw)
+		}
+		*v.p = parsed
+	default:
+		return fmt.Errorf("expected true or false, got %v", raw)
+	}
+
+	return nil
+}
+
+func (v boolValue) get() any { return *v.p }
+
+type durationValue struct{ p *time.Duration }
+
+func (v durationValue) set(raw any) error {
+	s, ok := raw.(string)
+	if !ok {
+		return fmt.Errorf("expected a duration such as 10s, got %v", raw)
+	}
+
+	parsed, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil {
+		return fmt.Errorf("expected a duration such as 10s, got %q", s)
+	}
+	*v.p = parsed
+
+	return nil
+}
+
+func (v durationValue) get() any { return v.p.String() }
+
+// listValue is a list in config files and a list separated by sep in the
+// environment.
+type listValue struct {
+	p   *[]string
+	sep string
+}
+
+func (v listValue) set(raw any) error {
+	var items []string
+
+	switch raw := raw.(type) {
+	case string:
+		for item := range strings.SplitSeq(raw, v.sep) {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	case []any:
+		for _, item := range raw {
+			s, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("expected a list of strings, got item %v", item)
+			}
+			items = append(items, s)
+		}
+	default:
+		return fmt.Errorf("expected a list, got %v", raw)
+	}
+
+	*v.p = items
+
+	return nil
+}
+
+func (v listValue) get() any { return *v.p }
+
+// mapValue is a table in config files and comma-separated key=value pairs in
+// the environment. A pair without "=" gets an empty value, which validation
+// reports.
+type mapValue struct{ p *map[string]string }
+
+func (v mapValue) set(raw any) error {
+	result := map[string]string{}
+
+	switch raw := raw.(type) {
+	case string:
+		for pair := range strings.SplitSeq(raw, ",") {
+			if pair = strings.TrimSpace(pair); pair == "" {
+				continue
+			}
+			name, value, _ := strings.Cut(pair, "=")
+			result[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+	case map[string]any:
+		for name, value := range raw {
+			s, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("expected string values, got %v for %q", value, name)
+			}
+			result[name] = s
+		}
+	default:
+		return fmt.Errorf("expected key=value pairs, got %v", raw)
+	}
+
+	*v.p = result
+
+	return nil
+}
+
+func (v mapValue) get() any { return *v.p }
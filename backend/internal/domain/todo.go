@@ -1,12 +1,18 @@
 package domain
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
+
+const generatedUIDFormat = "todo-%d@ontrack"
 
 type Todo struct {
 	ID          uint      `json:"id" gorm:"primaryKey"`
 	Title       string    `json:"title" gorm:"type:varchar(255);not null"`
 	Description *string   `json:"description"`
 	Completed   bool      `json:"completed" gorm:"default:false;index"`
+	UID         *string   `json:"uid,omitempty" gorm:"type:varchar(255);uniqueIndex"`
 	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime;index"`
 	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 }
@@ -15,6 +21,40 @@ func (Todo) TableName() string {
 	return "todos"
 }
 
+// CalendarUID returns the globally unique identifier used when the todo is
+// exchanged with calendar apps: the UID it was imported with, if any, or one
+// derived from its ID.
+func (t *Todo) CalendarUID() string {
+	if t.UID != nil {
+		return *t.UID
+	}
+
+	return fmt.Sprintf(generatedUIDFormat, t.ID)
+}
+
+// ParseGeneratedUID extracts the todo ID from a UID produced by CalendarUID.
+func ParseGeneratedUID(uid string) (uint, bool) {
+	var id uint
+	if _, err := fmt.Sscanf(uid, generatedUIDFormat, &id); err != nil || fmt.Sprintf(generatedUIDFormat, id) != uid {
+		return 0, false
+	}
+
+	return id, true
+}
+
+// TodoUpdate holds the fields to change in a todo. Nil fields keep their
+// value; an empty Description removes the description.
+type TodoUpdate struct {
+	Title       *string
+	Description *string
+	Completed   *bool
+}
+
+// IsEmpty reports whether the update changes nothing.
+func (u TodoUpdate) IsEmpty() bool {
+	return u.Title == nil && u.Description == nil && u.Completed == nil
+}
+
 type TodoFilter struct {
 	Completed *bool
 	Search    string
@@ -0,0 +1,11 @@
+package dto
+
+type RestoreRequest struct {
+	RemapIDs bool `form:"remap_ids"`
+}
+
+type RestoreResponse struct {
+	SchemaVersion int           `json:"schema_version"`
+	Restored      int           `json:"restored"`
+	IDs           map[uint]uint `json:"ids"`
+}
@@ -0,0 +1,13 @@
+package dto
+
+type LogLevelRequest struct {
+	Level string `json:"level" binding:"required" example:"debug"`
+	// Packages replaces the per-package overrides; they are kept when it is
+	// omitted.
+	Packages map[string]string `json:"packages,omitempty"`
+}
+
+type LogLevelResponse struct {
+	Level    string            `json:"level" example:"info"`
+	Packages map[string]string `json:"packages"`
+}
@@ -57,6 +57,8 @@ type ErrorResponse struct {
 	Message string      `json:"message"`
 	Code    string      `json:"code,omitempty"`
 	Details interface{} `json:"details,omitempty"`
+	// RequestID identifies the request in the server logs.
+	RequestID string `json:"request_id,omitempty"`
 }
 
 type ValidationError struct {
@@ -0,0 +1,53 @@
+package dto
+
+const (
+	ImportStatusCreated = "created"
+	ImportStatusUpdated = "updated"
+	ImportStatusSkipped = "skipped"
+	ImportStatusFailed  = "failed"
+)
+
+type TodoExportRequest struct {
+	Completed *bool  `form:"completed"`
+	Search    string `form:"search" binding:"omitempty,max=100"`
+	Format    string `form:"format"`
+}
+
+type TodoImportRequest struct {
+	Format string `form:"format"`
+	DryRun bool   `form:"dry_run"`
+}
+
+type ImportRowResult struct {
+	Row    int               `json:"row"`
+	Status string            `json:"status"`
+	TodoID uint              `json:"todo_id,omitempty"`
+	Errors []ValidationError `json:"errors,omitempty"`
+}
+
+type ImportSummaryResponse struct {
+	DryRun  bool              `json:"dry_run"`
+	Total   int               `json:"total"`
+	Created int               `json:"created"`
+	Updated int               `json:"updated"`
+	Skipped int               `json:"skipped"`
+	Failed  int               `json:"failed"`
+	Rows    []ImportRowResult `json:"rows"`
+}
+
+func (s *ImportSummaryResponse) Add(result ImportRowResult) {
+	s.Total++
+
+	switch result.Status {
+	case ImportStatusCreated:
+		s.Created++
+	case ImportStatusUpdated:
+		s.Updated++
+	case ImportStatusSkipped:
+		s.Skipped++
+	case ImportStatusFailed:
+		s.Failed++
+	}
+
+	s.Rows = append(s.Rows, result)
+}
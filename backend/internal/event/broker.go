@@ -0,0 +1,62 @@
+package event
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rod1kutzyy/OnTrack/internal/logger"
+)
+
+const subscriberBufferSize = 16
+
+// Broker is an in-process fan-out of todo events. Slow subscribers never
+// block publishers: events that do not fit into a subscriber's buffer are dropped.
+type Broker struct {
+	mu          sync.RWMutex
+	subscribers map[uint64]chan TodoEvent
+	nextID      uint64
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		subscribers: make(map[uint64]chan TodoEvent),
+	}
+}
+
+func (b *Broker) Publish(ctx context.Context, event TodoEvent) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for id, ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			logger.Logger.WithField("subscriber_id", id).Warn("Dropping todo event for slow subscriber")
+		}
+	}
+}
+
+// Subscribe registers a new subscriber. The returned function must be called
+// to release the subscription; it closes the events channel.
+func (b *Broker) Subscribe() (<-chan TodoEvent, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+
+	ch := make(chan TodoEvent, subscriberBufferSize)
+	b.subscribers[id] = ch
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, id)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+
+	return ch, unsubscribe
+}
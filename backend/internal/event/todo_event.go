@@ -0,0 +1,38 @@
+package event
+
+import (
+	"context"
+	"time"
+
+	"github.com/rod1kutzyy/OnTrack/internal/domain"
+)
+
+type TodoEventType string
+
+const (
+	TodoCreated TodoEventType = "CREATED"
+	TodoUpdated TodoEventType = "UPDATED"
+	TodoDeleted TodoEventType = "DELETED"
+)
+
+type TodoEvent struct {
+	Type       TodoEventType
+	Todo       domain.Todo
+	OccurredAt time.Time
+}
+
+func NewTodoEvent(eventType TodoEventType, todo domain.Todo) TodoEvent {
+	return TodoEvent{
+		Type:       eventType,
+		Todo:       todo,
+		OccurredAt: time.Now().UTC(),
+	}
+}
+
+type Publisher interface {
+	Publish(ctx context.Context, event TodoEvent)
+}
+
+type Subscriber interface {
+	Subscribe() (<-chan TodoEvent, func())
+}
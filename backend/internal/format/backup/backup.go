@@ -0,0 +1,195 @@
+package backup
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rod1kutzyy/OnTrack/internal/domain"
+)
+
+// SchemaVersion is the version of the backup document written by Encoder.
+// It must be increased whenever the document changes in an incompatible way.
+const SchemaVersion = 1
+
+const (
+	maxTitleLength       = 255
+	maxDescriptionLength = 1000
+)
+
+// Snapshot is a complete backup document:
+//
+//	{"schema_version": 1, "exported_at": "...", "todos": [...], "todo_count": 2}
+//
+// todo_count follows the todos so that the document can be streamed and still
+// allows truncated backups to be detected on restore.
+type Snapshot struct {
+	SchemaVersion int       `json:"schema_version"`
+	ExportedAt    time.Time `json:"exported_at"`
+	Todos         []Todo    `json:"todos"`
+	TodoCount     *int      `json:"todo_count"`
+}
+
+// Todo is the backup representation of domain.Todo. It is kept separate from
+// the API representation so that the backup schema only changes together with
+// SchemaVersion.
+type Todo struct {
+	ID          uint      `json:"id"`
+	UID         *string   `json:"uid,omitempty"`
+	Title       string    `json:"title"`
+	Description *string   `json:"description,omitempty"`
+	Completed   bool      `json:"completed"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (t Todo) ToDomain() domain.Todo {
+	return domain.Todo{
+		ID:          t.ID,
+		UID:         t.UID,
+		Title:       t.Title,
+		Description: t.Description,
+		Completed:   t.Completed,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+	}
+}
+
+func fromDomain(todo *domain.Todo) Todo {
+	return Todo{
+		ID:          todo.ID,
+		UID:         todo.UID,
+		Title:       todo.Title,
+		Description: todo.Description,
+		Completed:   todo.Completed,
+		CreatedAt:   todo.CreatedAt.UTC(),
+		UpdatedAt:   todo.UpdatedAt.UTC(),
+	}
+}
+
+// Encoder streams a Snapshot so that large databases never have to be held in
+// memory. Close must be called to terminate the document.
+type Encoder struct {
+	w     *bufio.Writer
+	count int
+}
+
+func NewEncoder(w io.Writer) (*Encoder, error) {
+	e := &Encoder{w: bufio.NewWriter(w)}
+
+	exportedAt, err := json.Marshal(time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(e.w, "{\n  \"schema_version\": %d,\n  \"exported_at\": %s,\n  \"todos\": [", SchemaVersion, exportedAt)
+
+	if err := e.w.Flush(); err != nil {
+		return nil, fmt.Errorf("failed to write backup header: %w", err)
+	}
+
+	return e, nil
+}
+
+func (e *Encoder) Write(todo *domain.Todo) error {
+	data, err := json.Marshal(fromDomain(todo))
+	if err != nil {
+		return fmt.Errorf("failed to encode todo %d: %w", todo.ID, err)
+	}
+
+	if e.count > 0 {
+		e.w.WriteString(",")
+	}
+	e.w.WriteString("\n    ")
+	e.w.Write(data)
+	e.count++
+
+	// Write errors are sticky in bufio.Writer and surface on the next Flush.
+	return nil
+}
+
+func (e *Encoder) Flush() error {
+	return e.w.Flush()
+}
+
+func (e *Encoder) Close() error {
+	if e.count > 0 {
+		e.w.WriteString("\n  ")
+	}
+	fmt.Fprintf(e.w, "],\n  \"todo_count\": %d\n}\n", e.count)
+
+	return e.w.Flush()
+}
+
+// Decode reads and validates a backup document. The whole document is
+// validated before anything is returned, so a restore never starts from a
+// partially valid backup.
+func Decode(r io.Reader) (*Snapshot, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	var snapshot Snapshot
+	if err := decoder.Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("invalid backup document: %w", err)
+	}
+
+	if err := snapshot.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &snapshot, nil
+}
+
+func (s *Snapshot) Validate() error {
+	switch {
+	case s.SchemaVersion == 0:
+		return errors.New("backup has no schema_version")
+	case s.SchemaVersion > SchemaVersion:
+		return fmt.Errorf("backup schema version %d is newer than the supported version %d", s.SchemaVersion, SchemaVersion)
+	case s.SchemaVersion < SchemaVersion:
+		return fmt.Errorf("backup schema version %d is no longer supported", s.SchemaVersion)
+	}
+
+	if s.TodoCount == nil {
+		return errors.New("backup has no todo_count, it may be truncated")
+	}
+
+	if *s.TodoCount != len(s.Todos) {
+		return fmt.Errorf("backup declares %d todos but contains %d", *s.TodoCount, len(s.Todos))
+	}
+
+	ids := make(map[uint]struct{}, len(s.Todos))
+	uids := make(map[string]struct{})
+
+	for i, todo := range s.Todos {
+		if todo.ID == 0 {
+			return fmt.Errorf("todo at index %d has no id", i)
+		}
+
+		if _, ok := ids[todo.ID]; ok {
+			return fmt.Errorf("todo id %d occurs more than once", todo.ID)
+		}
+		ids[todo.ID] = struct{}{}
+
+		if todo.UID != nil {
+			if _, ok := uids[*todo.UID]; ok {
+				return fmt.Errorf("todo uid %q occurs more than once", *todo.UID)
+			}
+			uids[*todo.UID] = struct{}{}
+		}
+
+		if length := utf8.RuneCountInString(todo.Title); length == 0 || length > maxTitleLength {
+			return fmt.Errorf("todo %d: title must be between 1 and %d characters", todo.ID, maxTitleLength)
+		}
+
+		if todo.Description != nil && utf8.RuneCountInString(*todo.Description) > maxDescriptionLength {
+			return fmt.Errorf("todo %d: description must be at most %d characters", todo.ID, maxDescriptionLength)
+		}
+	}
+
+	return nil
+}
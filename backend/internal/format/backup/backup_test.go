@@ -0,0 +1,159 @@
+package backup_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rod1kutzyy/OnTrack/internal/domain"
+	"github.com/rod1kutzyy/OnTrack/internal/format/backup"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestRoundTrip(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 9, 0, 0, 0, time.FixedZone("CET", 3600))
+	todos := []domain.Todo{
+		{ID: 3, Title: "Buy milk", Description: ptr("2 litres"), CreatedAt: createdAt, UpdatedAt: createdAt},
+		{ID: 7, Title: "Call mom", Completed: true, UID: ptr("call@example.com"), CreatedAt: createdAt, UpdatedAt: createdAt.Add(time.Hour)},
+	}
+
+	var buf bytes.Buffer
+	encoder, err := backup.NewEncoder(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range todos {
+		if err := encoder.Write(&todos[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, err := backup.Decode(&buf)
+	if err != nil {
+		t.Fatalf("Decode failed: %v\n%s", err, buf.String())
+	}
+	if snapshot.SchemaVersion != backup.SchemaVersion || len(snapshot.Todos) != len(todos) {
+		t.Fatalf("got version %d with %d todos, want version %d with %d", snapshot.SchemaVersion, len(snapshot.Todos), backup.SchemaVersion, len(todos))
+	}
+
+	for i, got := range snapshot.Todos {
+		todo := got.ToDomain()
+		want := todos[i]
+		if todo.ID != want.ID || todo.Title != want.Title || todo.Completed != want.Completed ||
+			!equal(todo.Description, want.Description) || !equal(todo.UID, want.UID) ||
+			!todo.CreatedAt.Equal(want.CreatedAt) || !todo.UpdatedAt.Equal(want.UpdatedAt) {
+			t.Errorf("todo %d = %+v, want %+v", i, todo, want)
+		}
+		if todo.CreatedAt.Location() != time.UTC {
+			t.Errorf("todo %d created at %s, want UTC", i, todo.CreatedAt)
+		}
+	}
+}
+
+func TestEmptyBackup(t *testing.T) {
+	var buf bytes.Buffer
+	encoder, err := backup.NewEncoder(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, err := backup.Decode(&buf)
+	if err != nil {
+		t.Fatalf("Decode failed: %v\n%s", err, buf.String())
+	}
+	if len(snapshot.Todos) != 0 || *snapshot.TodoCount != 0 {
+		t.Errorf("got %+v, want no todos", snapshot)
+	}
+}
+
+func TestDecodeRejectsInvalidBackups(t *testing.T) {
+	const todo = `{"id": 1, "title": "Buy milk", "completed": false, "created_at": "2024-01-01T00:00:00Z", "updated_at": "2024-01-01T00:00:00Z"}`
+
+	tests := []struct {
+		name    string
+		doc     string
+		wantErr string
+	}{
+		{
+			name:    "not json",
+			doc:     `todos: []`,
+			wantErr: "invalid backup document",
+		},
+		{
+			name:    "unknown field",
+			doc:     `{"schema_version": 1, "todos": [], "todo_count": 0, "extra": true}`,
+			wantErr: `unknown field "extra"`,
+		},
+		{
+			name:    "missing version",
+			doc:     `{"todos": [], "todo_count": 0}`,
+			wantErr: "no schema_version",
+		},
+		{
+			name:    "newer version",
+			doc:     `{"schema_version": 2, "todos": [], "todo_count": 0}`,
+			wantErr: "newer than the supported version 1",
+		},
+		{
+			name:    "truncated",
+			doc:     `{"schema_version": 1, "todos": [` + todo + `]}`,
+			wantErr: "no todo_count",
+		},
+		{
+			name:    "count mismatch",
+			doc:     `{"schema_version": 1, "todos": [` + todo + `], "todo_count": 2}`,
+			wantErr: "declares 2 todos but contains 1",
+		},
+		{
+			name:    "missing id",
+			doc:     `{"schema_version": 1, "todos": [{"title": "Buy milk"}], "todo_count": 1}`,
+			wantErr: "index 0 has no id",
+		},
+		{
+			name:    "duplicate id",
+			doc:     `{"schema_version": 1, "todos": [` + todo + `, ` + todo + `], "todo_count": 2}`,
+			wantErr: "id 1 occurs more than once",
+		},
+		{
+			name:    "duplicate uid",
+			doc:     `{"schema_version": 1, "todos": [{"id": 1, "uid": "a", "title": "One"}, {"id": 2, "uid": "a", "title": "Two"}], "todo_count": 2}`,
+			wantErr: `uid "a" occurs more than once`,
+		},
+		{
+			name:    "empty title",
+			doc:     `{"schema_version": 1, "todos": [{"id": 1, "title": ""}], "todo_count": 1}`,
+			wantErr: "title must be between 1 and 255 characters",
+		},
+		{
+			name:    "long description",
+			doc:     `{"schema_version": 1, "todos": [{"id": 1, "title": "Buy milk", "description": "` + strings.Repeat("x", 1001) + `"}], "todo_count": 1}`,
+			wantErr: "description must be at most 1000 characters",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := backup.Decode(strings.NewReader(tt.doc))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Decode() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func equal(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
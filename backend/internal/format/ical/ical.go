@@ -0,0 +1,299 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/rod1kutzyy/OnTrack/internal/domain"
+)
+
+const (
+	productID     = "-//OnTrack//OnTrack API//EN"
+	maxLineOctets = 75
+	dateTimeUTC   = "20060102T150405Z"
+	dateTimeLocal = "20060102T150405"
+	dateOnly      = "20060102"
+)
+
+// Todo is the subset of a VTODO component that maps onto domain.Todo.
+type Todo struct {
+	Line        int
+	UID         string
+	Summary     string
+	Description *string
+	Completed   bool
+	CreatedAt   *time.Time
+}
+
+type Encoder struct {
+	w *bufio.Writer
+}
+
+// NewEncoder writes the VCALENDAR header and returns an Encoder for VTODO
+// components. Close must be called to terminate the calendar.
+func NewEncoder(w io.Writer, calendarName string) (*Encoder, error) {
+	e := &Encoder{w: bufio.NewWriter(w)}
+
+	e.writeLine("BEGIN", "VCALENDAR")
+	e.writeLine("VERSION", "2.0")
+	e.writeLine("PRODID", productID)
+	e.writeLine("CALSCALE", "GREGORIAN")
+	e.writeLine("METHOD", "PUBLISH")
+	e.writeLine("X-WR-CALNAME", escapeText(calendarName))
+
+	if err := e.w.Flush(); err != nil {
+		return nil, fmt.Errorf("failed to write calendar header: %w", err)
+	}
+
+	return e, nil
+}
+
+func (e *Encoder) Write(todo *domain.Todo) error {
+	e.writeLine("BEGIN", "VTODO")
+	e.writeLine("UID", escapeText(todo.CalendarUID()))
+	e.writeLine("DTSTAMP", time.Now().UTC().Format(dateTimeUTC))
+	e.writeLine("CREATED", todo.CreatedAt.UTC().Format(dateTimeUTC))
+	e.writeLine("LAST-MODIFIED", todo.UpdatedAt.UTC().Format(dateTimeUTC))
+	e.writeLine("SUMMARY", escapeText(todo.Title))
+
+	if todo.Description != nil {
+		e.writeLine("DESCRIPTION", escapeText(*todo.Description))
+	}
+
+	if todo.Completed {
+		e.writeLine("STATUS", "COMPLETED")
+		e.writeLine("PERCENT-COMPLETE", "100")
+		// COMPLETED is left out: todos do not record when they were
+		// completed, and UpdatedAt changes with every later edit.
+	} else {
+		e.writeLine("STATUS", "NEEDS-ACTION")
+	}
+
+	e.writeLine("END", "VTODO")
+
+	return nil
+}
+
+func (e *Encoder) Flush() error {
+	return e.w.Flush()
+}
+
+func (e *Encoder) Close() error {
+	e.writeLine("END", "VCALENDAR")
+	return e.w.Flush()
+}
+
+// writeLine writes a content line folded at 75 octets as required by RFC 5545.
+// Write errors are sticky in bufio.Writer and surface on the next Flush.
+func (e *Encoder) writeLine(name, value string) {
+	line := name + ":" + value
+	limit := maxLineOctets
+
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+
+		e.w.WriteString(line[:cut])
+		e.w.WriteString("\r\n ")
+		line = line[cut:]
+
+		// Continuation lines start with a space, which counts towards the limit.
+		limit = maxLineOctets - 1
+	}
+
+	e.w.WriteString(line)
+	e.w.WriteString("\r\n")
+}
+
+// Decode parses all VTODO components of a calendar. Other components such as
+// VEVENT or VTIMEZONE are ignored.
+func Decode(r io.Reader) ([]Todo, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		todos      []Todo
+		current    *Todo
+		inCalendar bool
+		depth      int
+	)
+
+	for _, l := range lines {
+		name, params, value, ok := parseContentLine(l.text)
+		if !ok {
+			if current != nil {
+				return nil, fmt.Errorf("line %d: malformed content line", l.number)
+			}
+			continue
+		}
+
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VCALENDAR"):
+			inCalendar = true
+		case name == "BEGIN" && strings.EqualFold(value, "VTODO") && current == nil:
+			current = &Todo{Line: l.number}
+			depth = 0
+		case name == "BEGIN" && current != nil:
+			// Nested components such as VALARM do not describe the todo itself.
+			depth++
+		case name == "END" && current != nil && depth > 0:
+			depth--
+		case name == "END" && strings.EqualFold(value, "VTODO") && current != nil:
+			todos = append(todos, *current)
+			current = nil
+		case current != nil && depth == 0:
+			if err := current.set(name, params, value); err != nil {
+				return nil, fmt.Errorf("line %d: %w", l.number, err)
+			}
+		}
+	}
+
+	if !inCalendar {
+		return nil, errors.New("file is not an iCalendar file")
+	}
+
+	if current != nil {
+		return nil, fmt.Errorf("line %d: VTODO is not terminated", current.Line)
+	}
+
+	return todos, nil
+}
+
+func (t *Todo) set(name string, params map[string]string, value string) error {
+	switch name {
+	case "UID":
+		t.UID = unescapeText(value)
+	case "SUMMARY":
+		t.Summary = unescapeText(value)
+	case "DESCRIPTION":
+		description := unescapeText(value)
+		t.Description = &description
+	case "STATUS":
+		t.Completed = strings.EqualFold(value, "COMPLETED")
+	case "COMPLETED":
+		t.Completed = true
+	case "CREATED":
+		created, err := parseDateTime(value, params)
+		if err != nil {
+			return err
+		}
+		t.CreatedAt = &created
+	}
+
+	return nil
+}
+
+type contentLine struct {
+	number int
+	text   string
+}
+
+func unfold(r io.Reader) ([]contentLine, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+
+	var lines []contentLine
+	number := 0
+
+	for scanner.Scan() {
+		number++
+		text := strings.TrimRight(scanner.Text(), "\r")
+
+		if (strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1].text += text[1:]
+			continue
+		}
+
+		if text == "" {
+			continue
+		}
+
+		lines = append(lines, contentLine{number: number, text: text})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read calendar: %w", err)
+	}
+
+	return lines, nil
+}
+
+func parseContentLine(line string) (name string, params map[string]string, value string, ok bool) {
+	colon := -1
+	inQuotes := false
+
+	for i, r := range line {
+		if r == '"' {
+			inQuotes = !inQuotes
+		}
+		if r == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+
+	if colon <= 0 {
+		return "", nil, "", false
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	params = make(map[string]string, len(parts)-1)
+
+	for _, param := range parts[1:] {
+		key, val, found := strings.Cut(param, "=")
+		if found {
+			params[strings.ToUpper(key)] = strings.Trim(val, `"`)
+		}
+	}
+
+	return strings.ToUpper(parts[0]), params, line[colon+1:], true
+}
+
+func parseDateTime(value string, params map[string]string) (time.Time, error) {
+	if params["VALUE"] == "DATE" || len(value) == len(dateOnly) {
+		return time.Parse(dateOnly, value)
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse(dateTimeUTC, value)
+	}
+
+	location := time.UTC
+	if tzid, ok := params["TZID"]; ok {
+		if loaded, err := time.LoadLocation(tzid); err == nil {
+			location = loaded
+		}
+	}
+
+	parsed, err := time.ParseInLocation(dateTimeLocal, value, location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date-time %q", value)
+	}
+
+	return parsed.UTC(), nil
+}
+
+var (
+	textEscaper   = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	textUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+)
+
+func escapeText(value string) string {
+	return textEscaper.Replace(value)
+}
+
+func unescapeText(value string) string {
+	return textUnescaper.Replace(value)
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -0,0 +1,251 @@
+package ical_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+	"unicode/utf8"
+
+	"github.com/rod1kutzyy/OnTrack/internal/domain"
+	"github.com/rod1kutzyy/OnTrack/internal/format/ical"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func encode(t *testing.T, todos ...domain.Todo) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	encoder, err := ical.NewEncoder(&buf, "OnTrack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range todos {
+		if err := encoder.Write(&todos[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.String()
+}
+
+// calendar wraps content lines in a VCALENDAR with CRLF line endings.
+func calendar(lines ...string) string {
+	lines = append(append([]string{"BEGIN:VCALENDAR", "VERSION:2.0"}, lines...), "END:VCALENDAR")
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
+
+func TestLineFolding(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+	}{
+		{name: "ascii", title: strings.Repeat("Buy milk and bread ", 12)},
+		{name: "multibyte", title: strings.Repeat("Äpfel und Brötchen kaufen – ", 8)},
+		{name: "four byte runes", title: strings.Repeat("🥛🍞", 40)},
+		{name: "exactly one line", title: strings.Repeat("x", 75-len("SUMMARY:"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := encode(t, domain.Todo{ID: 1, Title: tt.title})
+
+			if !strings.HasSuffix(doc, "\r\n") {
+				t.Fatalf("document does not end with CRLF:\n%q", doc)
+			}
+			for i, line := range strings.Split(strings.TrimSuffix(doc, "\r\n"), "\r\n") {
+				if len(line) > 75 {
+					t.Errorf("line %d has %d octets: %q", i+1, len(line), line)
+				}
+				if !utf8.ValidString(line) {
+					t.Errorf("line %d splits a character: %q", i+1, line)
+				}
+			}
+
+			todos, err := ical.Decode(strings.NewReader(doc))
+			if err != nil {
+				t.Fatalf("Decode failed: %v", err)
+			}
+			if len(todos) != 1 || todos[0].Summary != tt.title {
+				t.Errorf("Decode() = %+v, want the summary unfolded", todos)
+			}
+		})
+	}
+}
+
+func TestTextEscaping(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		encoded     string
+	}{
+		{name: "plain", description: "2 litres", encoded: "DESCRIPTION:2 litres\r\n"},
+		{name: "separators", description: "milk; bread, eggs", encoded: `DESCRIPTION:milk\; bread\, eggs` + "\r\n"},
+		{name: "backslash", description: `C:\todo\n`, encoded: `DESCRIPTION:C:\\todo\\n` + "\r\n"},
+		{name: "newlines", description: "first\nsecond\r\nthird", encoded: `DESCRIPTION:first\nsecond\nthird` + "\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := encode(t, domain.Todo{ID: 1, Title: "Buy milk", Description: ptr(tt.description)})
+			if !strings.Contains(doc, tt.encoded) {
+				t.Errorf("document does not contain %q:\n%s", tt.encoded, doc)
+			}
+
+			todos, err := ical.Decode(strings.NewReader(doc))
+			if err != nil {
+				t.Fatalf("Decode failed: %v", err)
+			}
+			want := strings.ReplaceAll(tt.description, "\r\n", "\n")
+			if len(todos) != 1 || todos[0].Description == nil || *todos[0].Description != want {
+				t.Errorf("Decode() = %+v, want description %q", todos, want)
+			}
+		})
+	}
+
+	// Other producers may write an upper-case \N.
+	todos, err := ical.Decode(strings.NewReader(calendar("BEGIN:VTODO", `SUMMARY:first\Nsecond`, "END:VTODO")))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if len(todos) != 1 || todos[0].Summary != "first\nsecond" {
+		t.Errorf("Decode() = %+v, want \\N unescaped", todos)
+	}
+}
+
+func TestEncodeCompleted(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	doc := encode(t,
+		domain.Todo{ID: 1, Title: "Buy milk", Completed: true, CreatedAt: createdAt, UpdatedAt: createdAt.Add(time.Hour)},
+		domain.Todo{ID: 2, Title: "Call mom", CreatedAt: createdAt, UpdatedAt: createdAt},
+	)
+
+	for _, want := range []string{"STATUS:COMPLETED\r\nPERCENT-COMPLETE:100\r\n", "STATUS:NEEDS-ACTION\r\n", "CREATED:20240101T090000Z\r\n", "LAST-MODIFIED:20240101T100000Z\r\n"} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("document does not contain %q:\n%s", want, doc)
+		}
+	}
+	// Todos do not record when they were completed.
+	if strings.Contains(doc, "\nCOMPLETED:") {
+		t.Errorf("document has a COMPLETED property:\n%s", doc)
+	}
+
+	todos, err := ical.Decode(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if len(todos) != 2 || !todos[0].Completed || todos[1].Completed {
+		t.Errorf("Decode() = %+v, want only the first todo completed", todos)
+	}
+}
+
+func TestDecodeDates(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    time.Time
+		wantErr string
+	}{
+		{name: "utc", line: "CREATED:20240102T030405Z", want: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{name: "tzid", line: "CREATED;TZID=Europe/Berlin:20240102T030405", want: time.Date(2024, 1, 2, 2, 4, 5, 0, time.UTC)},
+		{name: "quoted tzid in summer", line: `CREATED;TZID="America/New_York":20240702T030405`, want: time.Date(2024, 7, 2, 7, 4, 5, 0, time.UTC)},
+		{name: "unknown tzid", line: "CREATED;TZID=Mars/Olympus:20240102T030405", want: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{name: "floating", line: "CREATED:20240102T030405", want: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{name: "date", line: "CREATED;VALUE=DATE:20240102", want: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{name: "date without value", line: "CREATED:20240102", want: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{name: "invalid date-time", line: "CREATED:yesterday", wantErr: `line 4: invalid date-time "yesterday"`},
+		{name: "invalid date", line: "CREATED;VALUE=DATE:2024-1-2", wantErr: "line 4: "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todos, err := ical.Decode(strings.NewReader(calendar("BEGIN:VTODO", tt.line, "SUMMARY:Buy milk", "END:VTODO")))
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Errorf("Decode() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Decode failed: %v", err)
+			}
+			if len(todos) != 1 || todos[0].CreatedAt == nil || !todos[0].CreatedAt.Equal(tt.want) {
+				t.Errorf("Decode() = %+v, want created at %s", todos, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeNestedComponents(t *testing.T) {
+	doc := calendar(
+		"BEGIN:VTIMEZONE",
+		"TZID:Europe/Berlin",
+		"BEGIN:STANDARD",
+		"DTSTART:19701025T030000",
+		"TZOFFSETFROM:+0200",
+		"TZOFFSETTO:+0100",
+		"END:STANDARD",
+		"END:VTIMEZONE",
+		"BEGIN:VEVENT",
+		"UID:event@example.com",
+		"SUMMARY:Not a todo",
+		"END:VEVENT",
+		"BEGIN:VTODO",
+		"UID:milk@example.com",
+		"SUMMARY:Buy",
+		"  milk",
+		"BEGIN:VALARM",
+		"ACTION:DISPLAY",
+		"DESCRIPTION:Reminder",
+		"BEGIN:X-NESTED",
+		"STATUS:COMPLETED",
+		"END:X-NESTED",
+		"END:VALARM",
+		"DESCRIPTION:2 litres",
+		"STATUS:NEEDS-ACTION",
+		"END:VTODO",
+	)
+
+	todos, err := ical.Decode(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	if len(todos) != 1 {
+		t.Fatalf("got %d todos %+v, want 1", len(todos), todos)
+	}
+	got := todos[0]
+	if got.Line != 15 || got.UID != "milk@example.com" || got.Summary != "Buy milk" || got.Completed ||
+		got.Description == nil || *got.Description != "2 litres" {
+		t.Errorf("Decode() = %+v, want the VTODO's own properties", got)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		wantErr string
+	}{
+		{name: "not a calendar", doc: "BEGIN:VCARD\r\nEND:VCARD\r\n", wantErr: "file is not an iCalendar file"},
+		{name: "unterminated", doc: "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nSUMMARY:Buy milk\r\n", wantErr: "line 2: VTODO is not terminated"},
+		{name: "malformed line", doc: calendar("BEGIN:VTODO", "SUMMARY Buy milk", "END:VTODO"), wantErr: "line 4: malformed content line"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ical.Decode(strings.NewReader(tt.doc))
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Decode() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
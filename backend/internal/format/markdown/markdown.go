@@ -0,0 +1,343 @@
+package markdown
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/rod1kutzyy/OnTrack/internal/domain"
+)
+
+const (
+	tabWidth       = 4
+	groupSeparator = " / "
+	maxLineBytes   = 1 << 20
+)
+
+// Item is a GitHub-flavored Markdown task list item such as "- [x] Ship it".
+type Item struct {
+	Line      int
+	Title     string
+	Completed bool
+	// Groups lists the enclosing headings followed by the enclosing list
+	// items, outermost first.
+	Groups []string
+	// Description holds the paragraphs indented under the item, as written
+	// by Encoder.
+	Description string
+}
+
+// Group returns the enclosing headings and list items as a single path.
+func (i Item) Group() string {
+	return strings.Join(i.Groups, groupSeparator)
+}
+
+type heading struct {
+	level int
+	text  string
+}
+
+type parent struct {
+	indent int
+	text   string
+}
+
+// Decode extracts all task list items of a Markdown document. Paragraphs
+// indented under a task item become its description. Other content such as
+// paragraphs, plain list items and fenced code blocks is skipped; headings
+// and plain list items are only used to group the items nested in them.
+func Decode(r io.Reader) ([]Item, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+
+	var (
+		items    []Item
+		headings []heading
+		parents  []parent
+		fence    string
+		number   int
+		// last is the task item the following indented paragraphs belong
+		// to, or nil.
+		last       *Item
+		lastIndent int
+		blanks     int
+	)
+
+	for scanner.Scan() {
+		number++
+		line := strings.TrimPrefix(scanner.Text(), "\ufeff")
+		indent, text := splitIndent(line)
+
+		if fence != "" {
+			if strings.HasPrefix(text, fence) {
+				fence = ""
+			}
+			continue
+		}
+
+		if marker := fenceMarker(text); marker != "" {
+			fence = marker
+			last = nil
+			continue
+		}
+
+		if text == "" {
+			blanks++
+			continue
+		}
+
+		if last != nil && indent >= lastIndent+2 && !isBlock(text) {
+			if last.Description != "" {
+				last.Description += strings.Repeat("\n", blanks+1)
+			}
+			last.Description += strings.Repeat(" ", indent-lastIndent-2) + unescapeLine(text)
+			blanks = 0
+			continue
+		}
+		last = nil
+		blanks = 0
+
+		if level, title, ok := parseHeading(text); ok && indent < tabWidth {
+			for len(headings) > 0 && headings[len(headings)-1].level >= level {
+				headings = headings[:len(headings)-1]
+			}
+			headings = append(headings, heading{level: level, text: title})
+			parents = nil
+			continue
+		}
+
+		content, ok := cutListMarker(text)
+		if !ok {
+			// Paragraph lines only end the enclosing lists when they are not indented.
+			if indent == 0 {
+				parents = nil
+			}
+			continue
+		}
+
+		for len(parents) > 0 && parents[len(parents)-1].indent >= indent {
+			parents = parents[:len(parents)-1]
+		}
+
+		title, completed, isTask := cutTaskMarker(content)
+		if isTask {
+			items = append(items, Item{
+				Line:      number,
+				Title:     title,
+				Completed: completed,
+				Groups:    groups(headings, parents),
+			})
+			last, lastIndent = &items[len(items)-1], indent
+		} else {
+			title = content
+		}
+
+		parents = append(parents, parent{indent: indent, text: title})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read markdown: %w", err)
+	}
+
+	return items, nil
+}
+
+type Encoder struct {
+	w *bufio.Writer
+}
+
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: bufio.NewWriter(w)}
+}
+
+// Write renders the todo as a task list item. The description follows as an
+// indented paragraph so that it stays part of the item, with description
+// lines that would read as list items, headings or fences escaped.
+func (e *Encoder) Write(todo *domain.Todo) error {
+	marker := "[ ]"
+	if todo.Completed {
+		marker = "[x]"
+	}
+
+	e.w.WriteString("- " + marker + " " + singleLine(todo.Title) + "\n")
+
+	if todo.Description != nil {
+		for _, line := range strings.Split(strings.TrimSpace(*todo.Description), "\n") {
+			if line = strings.TrimRight(line, " \r"); line == "" {
+				e.w.WriteString("\n")
+				continue
+			}
+			e.w.WriteString("  " + escapeLine(line) + "\n")
+		}
+	}
+
+	// Write errors are sticky in bufio.Writer and surface on the next Flush.
+	return nil
+}
+
+func (e *Encoder) Flush() error {
+	return e.w.Flush()
+}
+
+func (e *Encoder) Close() error {
+	return e.w.Flush()
+}
+
+func groups(headings []heading, parents []parent) []string {
+	var result []string
+	for _, h := range headings {
+		result = append(result, h.text)
+	}
+	for _, p := range parents {
+		result = append(result, p.text)
+	}
+
+	return result
+}
+
+func splitIndent(line string) (int, string) {
+	indent := 0
+	for i, r := range line {
+		switch r {
+		case ' ':
+			indent++
+		case '\t':
+			indent += tabWidth - indent%tabWidth
+		default:
+			return indent, strings.TrimRight(line[i:], " \t\r")
+		}
+	}
+
+	return indent, ""
+}
+
+func fenceMarker(text string) string {
+	for _, marker := range []string{"```", "~~~"} {
+		if strings.HasPrefix(text, marker) {
+			return marker
+		}
+	}
+
+	return ""
+}
+
+func parseHeading(text string) (int, string, bool) {
+	level := 0
+	for level < len(text) && text[level] == '#' {
+		level++
+	}
+
+	if level == 0 || level > 6 || (level < len(text) && text[level] != ' ' && text[level] != '\t') {
+		return 0, "", false
+	}
+
+	title := strings.TrimSpace(text[level:])
+	// Closing sequences such as "## Title ##" are not part of the heading.
+	title = strings.TrimSpace(strings.TrimRight(title, "#"))
+
+	return level, title, true
+}
+
+// cutListMarker strips a bullet ("-", "*", "+") or ordered ("1.", "1)") list
+// marker and returns the item content.
+func cutListMarker(text string) (string, bool) {
+	if len(text) >= 2 && strings.ContainsRune("-*+", rune(text[0])) && text[1] == ' ' {
+		return strings.TrimSpace(text[2:]), true
+	}
+
+	digits := countDigits(text)
+	if digits > 0 && len(text) > digits+1 && (text[digits] == '.' || text[digits] == ')') && text[digits+1] == ' ' {
+		return strings.TrimSpace(text[digits+2:]), true
+	}
+
+	return "", false
+}
+
+func cutTaskMarker(content string) (string, bool, bool) {
+	if len(content) < 3 || content[0] != '[' || content[2] != ']' {
+		return "", false, false
+	}
+
+	if len(content) > 3 && content[3] != ' ' && content[3] != '\t' {
+		return "", false, false
+	}
+
+	switch content[1] {
+	case ' ':
+		return strings.TrimSpace(content[3:]), false, true
+	case 'x', 'X':
+		return strings.TrimSpace(content[3:]), true, true
+	default:
+		return "", false, false
+	}
+}
+
+// isBlock reports whether text starts a list item, heading or fence.
+func isBlock(text string) bool {
+	if _, ok := cutListMarker(text); ok {
+		return true
+	}
+	if _, _, ok := parseHeading(text); ok {
+		return true
+	}
+
+	return fenceMarker(text) != ""
+}
+
+// escapeLine backslash-escapes a description line that Decode would read as
+// a list item, heading or fence, and lines that already start with such an
+// escape, so that unescapeLine restores them exactly. Ordered list markers
+// are escaped as "1\." and the others as "\-".
+func escapeLine(line string) string {
+	text := strings.TrimLeft(line, " \t")
+	indent := line[:len(line)-len(text)]
+
+	if digits := countDigits(text); digits > 0 && digits < len(text) {
+		if _, ok := cutListMarker(text); ok || text[digits] == '\\' {
+			return indent + text[:digits] + `\` + text[digits:]
+		}
+		return line
+	}
+
+	if isBlock(text) || isEscaped(text) {
+		return indent + `\` + text
+	}
+
+	return line
+}
+
+// unescapeLine reverses escapeLine.
+func unescapeLine(text string) string {
+	if digits := countDigits(text); digits > 0 {
+		if digits+1 < len(text) && text[digits] == '\\' && strings.ContainsRune(`.)\`, rune(text[digits+1])) {
+			return text[:digits] + text[digits+1:]
+		}
+		return text
+	}
+
+	if isEscaped(text) {
+		return text[1:]
+	}
+
+	return text
+}
+
+// isEscaped reports whether text starts with a backslash escaping one of the
+// characters that open a block.
+func isEscaped(text string) bool {
+	return len(text) > 1 && text[0] == '\\' && strings.ContainsRune("\\-*+#`~", rune(text[1]))
+}
+
+func countDigits(text string) int {
+	digits := 0
+	for digits < len(text) && digits < 9 && text[digits] >= '0' && text[digits] <= '9' {
+		digits++
+	}
+
+	return digits
+}
+
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
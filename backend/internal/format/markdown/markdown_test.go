@@ -0,0 +1,118 @@
+package markdown_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rod1kutzyy/OnTrack/internal/domain"
+	"github.com/rod1kutzyy/OnTrack/internal/format/markdown"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestDecode(t *testing.T) {
+	const doc = "# Meeting\n" +
+		"\n" +
+		"Agenda for today.\n" +
+		"\n" +
+		"- [ ] Book room\n" +
+		"  Second floor if possible.\n" +
+		"\n" +
+		"  Ask Anna.\n" +
+		"- Follow-ups\n" +
+		"  - [x] Send notes\n" +
+		"  * [X] Share slides\n" +
+		"1. [ ] Ordered task\n" +
+		"\n" +
+		"## Later ##\n" +
+		"```\n" +
+		"- [ ] Not a task\n" +
+		"```\n" +
+		"+ [ ] Review budget\n" +
+		"- [y] Not a task either\n" +
+		"- [ ]Not a task without a space\n"
+
+	items, err := markdown.Decode(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	want := []markdown.Item{
+		{Line: 5, Title: "Book room", Groups: []string{"Meeting"}, Description: "Second floor if possible.\n\nAsk Anna."},
+		{Line: 10, Title: "Send notes", Completed: true, Groups: []string{"Meeting", "Follow-ups"}},
+		{Line: 11, Title: "Share slides", Completed: true, Groups: []string{"Meeting", "Follow-ups"}},
+		{Line: 12, Title: "Ordered task", Groups: []string{"Meeting"}},
+		{Line: 18, Title: "Review budget", Groups: []string{"Meeting", "Later"}},
+	}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("Decode() =\n%+v\nwant\n%+v", items, want)
+	}
+	if got := items[1].Group(); got != "Meeting / Follow-ups" {
+		t.Errorf("Group() = %q, want %q", got, "Meeting / Follow-ups")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		// line is a line the encoded description must contain.
+		line string
+	}{
+		{name: "plain", description: "2 litres", line: "  2 litres\n"},
+		{name: "task marker", description: "- [ ] x", line: "  \\- [ ] x\n"},
+		{name: "completed task marker", description: "* [x] done", line: "  \\* [x] done\n"},
+		{name: "bullet", description: "Shopping:\n+ milk\n- bread", line: "  \\+ milk\n  \\- bread\n"},
+		{name: "ordered", description: "1. first\n2) second", line: "  1\\. first\n  2\\) second\n"},
+		{name: "heading", description: "# Not a heading", line: "  \\# Not a heading\n"},
+		{name: "fence", description: "```\ncode\n```", line: "  \\```\n  code\n  \\```\n"},
+		{name: "literal escapes", description: `\- kept` + "\n" + `1\. kept` + "\n" + `\n kept`, line: "  \\\\- kept\n  1\\\\. kept\n  \\n kept\n"},
+		{name: "not markers", description: "-5 degrees\n2024 plans\n#hashtag", line: "  -5 degrees\n  2024 plans\n  #hashtag\n"},
+		{name: "paragraphs and indent", description: "First\n\nSecond\n    indented", line: "  First\n\n  Second\n      indented\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todos := []domain.Todo{
+				{Title: "Buy milk", Description: ptr(tt.description)},
+				{Title: "Call mom", Completed: true},
+			}
+
+			var buf bytes.Buffer
+			encoder := markdown.NewEncoder(&buf)
+			for i := range todos {
+				if err := encoder.Write(&todos[i]); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := encoder.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.Contains(buf.String(), tt.line) {
+				t.Errorf("encoded document does not contain %q:\n%s", tt.line, buf.String())
+			}
+
+			items, err := markdown.Decode(&buf)
+			if err != nil {
+				t.Fatalf("Decode failed: %v", err)
+			}
+			if len(items) != len(todos) {
+				t.Fatalf("got %d items %+v, want %d", len(items), items, len(todos))
+			}
+			for i, item := range items {
+				want := ""
+				if todos[i].Description != nil {
+					want = *todos[i].Description
+				}
+				if item.Title != todos[i].Title || item.Completed != todos[i].Completed || item.Description != want || len(item.Groups) != 0 {
+					t.Errorf("item %d = %+v, want %+v", i, item, todos[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package todocsv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rod1kutzyy/OnTrack/internal/domain"
+)
+
+const (
+	ColumnID          = "id"
+	ColumnTitle       = "title"
+	ColumnDescription = "description"
+	ColumnCompleted   = "completed"
+	ColumnCreatedAt   = "created_at"
+	ColumnUpdatedAt   = "updated_at"
+)
+
+var exportColumns = []string{
+	ColumnID,
+	ColumnTitle,
+	ColumnDescription,
+	ColumnCompleted,
+	ColumnCreatedAt,
+	ColumnUpdatedAt,
+}
+
+// headerAliases lists the spreadsheet headers recognized for each importable
+// column when no explicit mapping is given. Matching is case-insensitive.
+var headerAliases = map[string][]string{
+	ColumnTitle:       {"title", "name", "task", "subject", "summary"},
+	ColumnDescription: {"description", "notes", "note", "details", "body"},
+	ColumnCompleted:   {"completed", "done", "status", "complete"},
+	ColumnCreatedAt:   {"created_at", "created", "created at", "date"},
+}
+
+// formulaPrefixes start cells that spreadsheets evaluate as formulas.
+const formulaPrefixes = "=+-@\t\r"
+
+type Writer struct {
+	csv *csv.Writer
+}
+
+// NewWriter writes the header row and returns a Writer for todo rows.
+func NewWriter(w io.Writer) (*Writer, error) {
+	csvWriter := csv.NewWriter(w)
+
+	if err := csvWriter.Write(exportColumns); err != nil {
+		return nil, fmt.Errorf("failed to write csv header: %w", err)
+	}
+
+	return &Writer{csv: csvWriter}, nil
+}
+
+func (w *Writer) Write(todo *domain.Todo) error {
+	description := ""
+	if todo.Description != nil {
+		description = *todo.Description
+	}
+
+	return w.csv.Write([]string{
+		strconv.FormatUint(uint64(todo.ID), 10),
+		escapeFormula(todo.Title),
+		escapeFormula(description),
+		strconv.FormatBool(todo.Completed),
+		todo.CreatedAt.UTC().Format(time.RFC3339),
+		todo.UpdatedAt.UTC().Format(time.RFC3339),
+	})
+}
+
+func (w *Writer) Flush() error {
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+func (w *Writer) Close() error {
+	return w.Flush()
+}
+
+type Record struct {
+	Line        int
+	Empty       bool
+	Title       string
+	Description *string
+	Completed   bool
+	CreatedAt   *time.Time
+}
+
+type Reader struct {
+	csv     *csv.Reader
+	columns map[string]int
+}
+
+// NewReader reads the header row and resolves column positions. mapping maps
+// todo columns (title, description, completed, created_at) to header names in
+// the file and takes precedence over the built-in aliases.
+func NewReader(r io.Reader, mapping map[string]string) (*Reader, error) {
+	csvReader := csv.NewReader(r)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+
+	header, err := csvReader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("csv file is empty")
+		}
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+
+	positions := make(map[string]int, len(header))
+	for i, name := range header {
+		positions[normalizeHeader(name)] = i
+	}
+
+	columns := make(map[string]int)
+	for column, aliases := range headerAliases {
+		if headerName, ok := mapping[column]; ok {
+			position, found := positions[normalizeHeader(headerName)]
+			if !found {
+				return nil, fmt.Errorf("mapped header %q for column %q not found", headerName, column)
+			}
+			columns[column] = position
+			continue
+		}
+
+		for _, alias := range aliases {
+			if position, found := positions[alias]; found {
+				columns[column] = position
+				break
+			}
+		}
+	}
+
+	for column := range mapping {
+		if _, ok := headerAliases[column]; !ok {
+			return nil, fmt.Errorf("unknown column %q in header mapping", column)
+		}
+	}
+
+	if _, ok := columns[ColumnTitle]; !ok {
+		return nil, errors.New("csv header has no title column")
+	}
+
+	return &Reader{
+		csv:     csvReader,
+		columns: columns,
+	}, nil
+}
+
+// Next returns the next record or io.EOF. A returned error other than io.EOF
+// describes a malformed row; reading may continue with the following row.
+func (r *Reader) Next() (*Record, error) {
+	fields, err := r.csv.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return &Record{Line: parseErr.Line}, err
+		}
+		return nil, err
+	}
+
+	line, _ := r.csv.FieldPos(0)
+	record := &Record{Line: line}
+
+	if isBlank(fields) {
+		record.Empty = true
+		return record, nil
+	}
+
+	record.Title = unescapeFormula(r.field(fields, ColumnTitle))
+
+	if description := unescapeFormula(r.field(fields, ColumnDescription)); description != "" {
+		record.Description = &description
+	}
+
+	if completed := r.field(fields, ColumnCompleted); completed != "" {
+		record.Completed, err = parseCompleted(completed)
+		if err != nil {
+			return record, err
+		}
+	}
+
+	if createdAt := r.field(fields, ColumnCreatedAt); createdAt != "" {
+		parsed, err := parseTime(createdAt)
+		if err != nil {
+			return record, err
+		}
+		record.CreatedAt = &parsed
+	}
+
+	return record, nil
+}
+
+func (r *Reader) field(fields []string, column string) string {
+	position, ok := r.columns[column]
+	if !ok || position >= len(fields) {
+		return ""
+	}
+
+	return strings.TrimSpace(fields[position])
+}
+
+// escapeFormula prefixes cells that spreadsheets would evaluate as formulas
+// with a quote, which makes them text. Cells already starting with a quote
+// are prefixed too, so that unescapeFormula keeps them.
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune(formulaPrefixes+"'", rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
+
+// unescapeFormula reverses escapeFormula, so that exported files import
+// unchanged.
+func unescapeFormula(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(formulaPrefixes+"'", rune(value[1])) {
+		return value[1:]
+	}
+
+	return value
+}
+
+func parseCompleted(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "true", "yes", "y", "1", "x", "done", "completed":
+		return true, nil
+	case "false", "no", "n", "0", "", "todo", "open", "pending":
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid completed value %q", value)
+	}
+}
+
+func parseTime(value string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed.UTC(), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+func normalizeHeader(name string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+}
+
+func isBlank(fields []string) bool {
+	for _, field := range fields {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+
+	return true
+}
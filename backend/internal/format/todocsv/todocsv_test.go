@@ -0,0 +1,204 @@
+package todocsv_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rod1kutzyy/OnTrack/internal/domain"
+	"github.com/rod1kutzyy/OnTrack/internal/format/todocsv"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// readAll returns the records of doc, with the error of a malformed row in
+// place of its title.
+func readAll(t *testing.T, doc string, mapping map[string]string) []todocsv.Record {
+	t.Helper()
+
+	reader, err := todocsv.NewReader(strings.NewReader(doc), mapping)
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+
+	var records []todocsv.Record
+	for {
+		record, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return records
+		}
+		if err != nil {
+			records = append(records, todocsv.Record{Line: record.Line, Title: "error: " + err.Error()})
+			continue
+		}
+		records = append(records, *record)
+	}
+}
+
+func TestHeaderAliases(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+	}{
+		{name: "export columns", header: "id,title,description,completed,created_at,updated_at"},
+		{name: "aliases", header: "Task,Notes,Done,Date"},
+		{name: "mixed case and spaces", header: " SUMMARY , Details , Status , Created At "},
+		{name: "byte order mark", header: "\ufeffname,body,complete,created"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := "Buy milk,2 litres,yes,2024-01-02"
+			if strings.HasPrefix(tt.header, "id,") {
+				row = "1,Buy milk,2 litres,true,2024-01-02T00:00:00Z,2024-01-03T00:00:00Z"
+			}
+
+			records := readAll(t, tt.header+"\n"+row+"\n", nil)
+			if len(records) != 1 {
+				t.Fatalf("got %d records, want 1", len(records))
+			}
+
+			got := records[0]
+			if got.Title != "Buy milk" || got.Description == nil || *got.Description != "2 litres" || !got.Completed ||
+				got.CreatedAt == nil || !got.CreatedAt.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)) {
+				t.Errorf("got %+v, want every column read", got)
+			}
+		})
+	}
+}
+
+func TestHeaderMapping(t *testing.T) {
+	const doc = "Title,Item,Remarks,Finished\nIgnored,Buy milk,2 litres,x\n"
+
+	records := readAll(t, doc, map[string]string{
+		todocsv.ColumnTitle:       "item",
+		todocsv.ColumnDescription: "Remarks",
+		todocsv.ColumnCompleted:   "Finished",
+	})
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	if got := records[0]; got.Title != "Buy milk" || got.Description == nil || *got.Description != "2 litres" || !got.Completed {
+		t.Errorf("got %+v, want the mapped columns to win over the aliases", got)
+	}
+}
+
+func TestNewReaderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		mapping map[string]string
+		wantErr string
+	}{
+		{
+			name:    "empty file",
+			doc:     "",
+			wantErr: "csv file is empty",
+		},
+		{
+			name:    "no title column",
+			doc:     "description,completed\nx,true\n",
+			wantErr: "csv header has no title column",
+		},
+		{
+			name:    "mapped header missing",
+			doc:     "title\nBuy milk\n",
+			mapping: map[string]string{todocsv.ColumnDescription: "notes"},
+			wantErr: `mapped header "notes" for column "description" not found`,
+		},
+		{
+			name:    "unknown column",
+			doc:     "title\nBuy milk\n",
+			mapping: map[string]string{"priority": "title"},
+			wantErr: `unknown column "priority" in header mapping`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := todocsv.NewReader(strings.NewReader(tt.doc), tt.mapping)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("NewReader() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMalformedRows(t *testing.T) {
+	const doc = "title,completed,created_at\n" +
+		"Buy milk,maybe,\n" +
+		"Call mom,,yesterday\n" +
+		",,\n" +
+		"Short row\n" +
+		"\"Unterminated,true,\n"
+
+	records := readAll(t, doc, nil)
+
+	want := []todocsv.Record{
+		{Line: 2, Title: `error: invalid completed value "maybe"`},
+		{Line: 3, Title: `error: invalid date "yesterday"`},
+		{Line: 4, Empty: true},
+		{Line: 5, Title: "Short row"},
+	}
+	if len(records) != len(want)+1 {
+		t.Fatalf("got %d records %+v, want %d", len(records), records, len(want)+1)
+	}
+	for i, w := range want {
+		got := records[i]
+		if got.Line != w.Line || got.Empty != w.Empty || got.Title != w.Title {
+			t.Errorf("record %d = %+v, want %+v", i, got, w)
+		}
+	}
+
+	// The quote error is reported with its line.
+	last := records[len(want)]
+	if last.Line != 6 || !strings.Contains(last.Title, "error: ") {
+		t.Errorf("got %+v, want a parse error on line 6", last)
+	}
+}
+
+func TestFormulaEscaping(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	titles := []string{"=1+1", "+49 30 1234", "-10 kg", "@SUM(A1:A2)", "'quoted", "'=1+1", "plain"}
+
+	var buf bytes.Buffer
+	writer, err := todocsv.NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, title := range titles {
+		todo := domain.Todo{ID: uint(i + 1), Title: title, Description: ptr("=HYPERLINK(\"http://example.com\")"), CreatedAt: createdAt, UpdatedAt: createdAt}
+		if err := writer.Write(&todo); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	exported := buf.String()
+	for _, want := range []string{",'=1+1,", ",'+49 30 1234,", ",'-10 kg,", ",'@SUM(A1:A2),", ",''quoted,", ",''=1+1,", ",plain,", `"'=HYPERLINK(""http://example.com"")"`} {
+		if !strings.Contains(exported, want) {
+			t.Errorf("export does not contain %q:\n%s", want, exported)
+		}
+	}
+
+	// Importing the export gives back the original values.
+	records := readAll(t, exported, nil)
+	if len(records) != len(titles) {
+		t.Fatalf("got %d records, want %d", len(records), len(titles))
+	}
+	for i, record := range records {
+		if record.Title != titles[i] {
+			t.Errorf("record %d title = %q, want %q", i, record.Title, titles[i])
+		}
+		if record.Description == nil || *record.Description != "=HYPERLINK(\"http://example.com\")" {
+			t.Errorf("record %d description = %v, want the formula unescaped", i, record.Description)
+		}
+	}
+}
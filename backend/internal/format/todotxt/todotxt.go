@@ -0,0 +1,295 @@
+package todotxt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/rod1kutzyy/OnTrack/internal/domain"
+)
+
+const (
+	dateLayout   = "2006-01-02"
+	priorityKey  = "pri"
+	maxLineBytes = 1 << 20
+)
+
+type Extension struct {
+	Key   string
+	Value string
+}
+
+// Task is a single line of a todo.txt file as described at
+// https://github.com/todotxt/todo.txt.
+type Task struct {
+	Completed      bool
+	Priority       string
+	CompletionDate *time.Time
+	CreationDate   *time.Time
+	// Description is the task text including projects, contexts and extensions.
+	Description string
+	Projects    []string
+	Contexts    []string
+	Extensions  []Extension
+}
+
+// Parse parses a single non-empty todo.txt line.
+func Parse(line string) (Task, error) {
+	var task Task
+
+	rest := strings.TrimSpace(line)
+	if rest == "" {
+		return task, errors.New("task is empty")
+	}
+
+	if strings.HasPrefix(rest, "x ") {
+		task.Completed = true
+		rest = strings.TrimLeft(rest[2:], " ")
+	}
+
+	if priority, remaining, ok := cutPriority(rest); ok {
+		task.Priority = priority
+		rest = remaining
+	}
+
+	first, remaining, ok := cutDate(rest)
+	if ok {
+		rest = remaining
+
+		if second, remaining, ok := cutDate(rest); ok {
+			rest = remaining
+			if task.Completed {
+				task.CompletionDate, task.CreationDate = &first, &second
+			} else {
+				// Only completed tasks carry two dates; the second one belongs to the text.
+				task.CreationDate = &first
+				rest = second.Format(dateLayout) + " " + rest
+			}
+		} else if task.Completed {
+			task.CompletionDate = &first
+		} else {
+			task.CreationDate = &first
+		}
+	}
+
+	task.Description = strings.TrimSpace(rest)
+	if task.Description == "" {
+		return task, errors.New("task has no description")
+	}
+
+	task.scanTags()
+
+	return task, nil
+}
+
+// scanTags collects the projects, contexts and extensions of the description.
+func (t *Task) scanTags() {
+	t.Projects, t.Contexts, t.Extensions = nil, nil, nil
+
+	for _, word := range strings.Fields(t.Description) {
+		switch {
+		case len(word) > 1 && word[0] == '+':
+			t.Projects = append(t.Projects, word[1:])
+		case len(word) > 1 && word[0] == '@':
+			t.Contexts = append(t.Contexts, word[1:])
+		default:
+			if key, value, ok := cutExtension(word); ok {
+				t.Extensions = append(t.Extensions, Extension{Key: key, Value: value})
+			}
+		}
+	}
+}
+
+// String renders the task as a todo.txt line.
+func (t Task) String() string {
+	var parts []string
+
+	if t.Completed {
+		parts = append(parts, "x")
+	}
+
+	if t.Priority != "" {
+		parts = append(parts, "("+t.Priority+")")
+	}
+
+	if t.Completed && t.CompletionDate != nil {
+		parts = append(parts, t.CompletionDate.Format(dateLayout))
+	}
+
+	if t.CreationDate != nil && (!t.Completed || t.CompletionDate != nil) {
+		parts = append(parts, t.CreationDate.Format(dateLayout))
+	}
+
+	parts = append(parts, t.Description)
+
+	return strings.Join(parts, " ")
+}
+
+// Extension returns the value of the first key:value extension with the given key.
+func (t Task) Extension(key string) (string, bool) {
+	for _, extension := range t.Extensions {
+		if extension.Key == key {
+			return extension.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// ToTodo maps the task onto a todo. Projects, contexts and extensions stay part
+// of the title. Since todos have no priority, a priority is kept as a pri:X
+// extension, the convention todo.txt uses for completed tasks.
+func (t Task) ToTodo() domain.Todo {
+	title := t.Description
+	if _, ok := t.Extension(priorityKey); t.Priority != "" && !ok {
+		title += " " + priorityKey + ":" + t.Priority
+	}
+
+	todo := domain.Todo{
+		Title:     title,
+		Completed: t.Completed,
+	}
+
+	if t.CreationDate != nil {
+		todo.CreatedAt = *t.CreationDate
+	}
+
+	return todo
+}
+
+// FromTodo is the inverse of ToTodo. A pri:X extension of an open todo is
+// turned back into a leading priority. The todo description has no todo.txt
+// counterpart and is not rendered.
+func FromTodo(todo *domain.Todo) Task {
+	task := Task{
+		Completed:   todo.Completed,
+		Description: strings.Join(strings.Fields(todo.Title), " "),
+	}
+	task.scanTags()
+
+	if !todo.CreatedAt.IsZero() {
+		createdAt := todo.CreatedAt.UTC()
+		task.CreationDate = &createdAt
+	}
+
+	if todo.Completed {
+		if !todo.UpdatedAt.IsZero() {
+			completedAt := todo.UpdatedAt.UTC()
+			task.CompletionDate = &completedAt
+		}
+	} else if priority, ok := task.Extension(priorityKey); ok && isPriority(priority) {
+		task.Priority = priority
+		task.Description = removeWord(task.Description, priorityKey+":"+priority)
+	}
+
+	return task
+}
+
+type Line struct {
+	Number int
+	Task   Task
+	Err    error
+}
+
+// Decode reads all non-blank lines of a todo.txt file. Lines that fail to
+// parse are returned with Err set so that callers can report them per line.
+func Decode(r io.Reader) ([]Line, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+
+	var lines []Line
+	number := 0
+
+	for scanner.Scan() {
+		number++
+
+		text := strings.TrimPrefix(scanner.Text(), "\ufeff")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		task, err := Parse(text)
+		lines = append(lines, Line{Number: number, Task: task, Err: err})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read todo.txt: %w", err)
+	}
+
+	return lines, nil
+}
+
+type Encoder struct {
+	w *bufio.Writer
+}
+
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: bufio.NewWriter(w)}
+}
+
+func (e *Encoder) Write(todo *domain.Todo) error {
+	if _, err := e.w.WriteString(FromTodo(todo).String() + "\n"); err != nil {
+		return fmt.Errorf("failed to write task: %w", err)
+	}
+
+	return nil
+}
+
+func (e *Encoder) Flush() error {
+	return e.w.Flush()
+}
+
+func (e *Encoder) Close() error {
+	return e.w.Flush()
+}
+
+func cutPriority(s string) (string, string, bool) {
+	if len(s) < 4 || s[0] != '(' || s[2] != ')' || s[3] != ' ' || !isPriority(s[1:2]) {
+		return "", s, false
+	}
+
+	return s[1:2], strings.TrimLeft(s[4:], " "), true
+}
+
+func cutDate(s string) (time.Time, string, bool) {
+	word, rest, _ := strings.Cut(s, " ")
+
+	date, err := time.Parse(dateLayout, word)
+	if err != nil {
+		return time.Time{}, s, false
+	}
+
+	return date, strings.TrimLeft(rest, " "), true
+}
+
+func cutExtension(word string) (string, string, bool) {
+	key, value, ok := strings.Cut(word, ":")
+	if !ok || key == "" || value == "" || strings.Contains(value, ":") || strings.HasPrefix(value, "//") {
+		return "", "", false
+	}
+
+	return key, value, true
+}
+
+func isPriority(s string) bool {
+	return len(s) == 1 && s[0] >= 'A' && s[0] <= 'Z'
+}
+
+func removeWord(s, word string) string {
+	fields := strings.Fields(s)
+
+	kept := fields[:0]
+	removed := false
+	for _, field := range fields {
+		if field == word && !removed {
+			removed = true
+			continue
+		}
+		kept = append(kept, field)
+	}
+
+	return strings.Join(kept, " ")
+}
@@ -0,0 +1,225 @@
+package todotxt_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rod1kutzyy/OnTrack/internal/domain"
+	"github.com/rod1kutzyy/OnTrack/internal/format/todotxt"
+)
+
+func date(s string) *time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return &t
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want todotxt.Task
+		// render is the line String produces, if it differs from line.
+		render string
+	}{
+		{
+			name: "plain",
+			line: "Buy milk",
+			want: todotxt.Task{Description: "Buy milk"},
+		},
+		{
+			name: "priority and creation date",
+			line: "(A) 2024-01-01 Call mom",
+			want: todotxt.Task{Priority: "A", CreationDate: date("2024-01-01"), Description: "Call mom"},
+		},
+		{
+			name: "completed with both dates",
+			line: "x 2024-01-02 2024-01-01 Call mom +family @phone due:2024-01-05",
+			want: todotxt.Task{
+				Completed:      true,
+				CompletionDate: date("2024-01-02"),
+				CreationDate:   date("2024-01-01"),
+				Description:    "Call mom +family @phone due:2024-01-05",
+				Projects:       []string{"family"},
+				Contexts:       []string{"phone"},
+				Extensions:     []todotxt.Extension{{Key: "due", Value: "2024-01-05"}},
+			},
+		},
+		{
+			name: "completed with completion date only",
+			line: "x 2024-01-02 Pay rent",
+			want: todotxt.Task{Completed: true, CompletionDate: date("2024-01-02"), Description: "Pay rent"},
+		},
+		{
+			name: "second date of an open task is text",
+			line: "2024-01-01 2024-02-01 is the deadline",
+			want: todotxt.Task{CreationDate: date("2024-01-01"), Description: "2024-02-01 is the deadline"},
+		},
+		{
+			name: "lowercase priority is text",
+			line: "(a) Buy milk",
+			want: todotxt.Task{Description: "(a) Buy milk"},
+		},
+		{
+			name: "x without space is text",
+			line: "xylophone lessons",
+			want: todotxt.Task{Description: "xylophone lessons"},
+		},
+		{
+			name: "urls are not extensions",
+			line: "Read https://example.com/post key:value",
+			want: todotxt.Task{
+				Description: "Read https://example.com/post key:value",
+				Extensions:  []todotxt.Extension{{Key: "key", Value: "value"}},
+			},
+		},
+		{
+			name:   "extra spaces",
+			line:   "  x  (B)  2024-01-02   Water plants  ",
+			want:   todotxt.Task{Completed: true, Priority: "B", CompletionDate: date("2024-01-02"), Description: "Water plants"},
+			render: "x (B) 2024-01-02 Water plants",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task, err := todotxt.Parse(tt.line)
+			if err != nil {
+				t.Fatalf("Parse() failed: %v", err)
+			}
+			if !reflect.DeepEqual(task, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", task, tt.want)
+			}
+
+			render := tt.render
+			if render == "" {
+				render = tt.line
+			}
+			if got := task.String(); got != render {
+				t.Errorf("String() = %q, want %q", got, render)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, line := range []string{"", "   ", "2024-01-01", "x 2024-01-02 2024-01-01 "} {
+		if _, err := todotxt.Parse(line); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", line)
+		}
+	}
+}
+
+func TestTodoRoundTrip(t *testing.T) {
+	createdAt := time.Date(2024, 3, 1, 15, 4, 5, 0, time.UTC)
+	updatedAt := time.Date(2024, 3, 2, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		todo domain.Todo
+		line string
+		// want is the todo read back, if it differs from todo.
+		want *domain.Todo
+	}{
+		{
+			name: "open",
+			todo: domain.Todo{Title: "Buy milk +home @shop", CreatedAt: createdAt},
+			line: "2024-03-01 Buy milk +home @shop",
+			want: &domain.Todo{Title: "Buy milk +home @shop", CreatedAt: *date("2024-03-01")},
+		},
+		{
+			name: "open with priority",
+			todo: domain.Todo{Title: "Call mom pri:A"},
+			line: "(A) Call mom",
+		},
+		{
+			name: "completed keeps priority as extension",
+			todo: domain.Todo{Title: "Call mom pri:A", Completed: true, CreatedAt: *date("2024-03-01"), UpdatedAt: updatedAt},
+			line: "x 2024-03-02 2024-03-01 Call mom pri:A",
+			want: &domain.Todo{Title: "Call mom pri:A", Completed: true, CreatedAt: *date("2024-03-01")},
+		},
+		{
+			name: "whitespace is collapsed",
+			todo: domain.Todo{Title: "Pay \t rent\nnow"},
+			line: "Pay rent now",
+			want: &domain.Todo{Title: "Pay rent now"},
+		},
+		{
+			name: "description is dropped",
+			todo: domain.Todo{Title: "Pay rent", Description: ptr("By Friday")},
+			line: "Pay rent",
+			want: &domain.Todo{Title: "Pay rent"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			encoder := todotxt.NewEncoder(&buf)
+			if err := encoder.Write(&tt.todo); err != nil {
+				t.Fatal(err)
+			}
+			if err := encoder.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := strings.TrimSuffix(buf.String(), "\n"); got != tt.line {
+				t.Errorf("encoded %q, want %q", got, tt.line)
+			}
+
+			lines, err := todotxt.Decode(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(lines) != 1 || lines[0].Err != nil {
+				t.Fatalf("Decode() = %+v, want one valid line", lines)
+			}
+
+			want := tt.todo
+			if tt.want != nil {
+				want = *tt.want
+			}
+			if got := lines[0].Task.ToTodo(); !reflect.DeepEqual(got, want) {
+				t.Errorf("decoded %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	input := "\ufeff(A) First\n\n   \n2024-01-01\nx Second\n"
+
+	lines, err := todotxt.Decode(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type result struct {
+		number      int
+		description string
+		failed      bool
+	}
+
+	var got []result
+	for _, line := range lines {
+		got = append(got, result{line.Number, line.Task.Description, line.Err != nil})
+	}
+
+	want := []result{
+		{number: 1, description: "First"},
+		{number: 4, failed: true},
+		{number: 5, description: "Second"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Decode() = %+v, want %+v", got, want)
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,31 @@
+package graphql
+
+type apiError struct {
+	message string
+	code    string
+	details interface{}
+}
+
+func newError(message, code string, details interface{}) *apiError {
+	return &apiError{
+		message: message,
+		code:    code,
+		details: details,
+	}
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+func (e *apiError) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{
+		"code": e.code,
+	}
+
+	if e.details != nil {
+		extensions["details"] = e.details
+	}
+
+	return extensions
+}
@@ -0,0 +1,197 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/rod1kutzyy/OnTrack/internal/logger"
+)
+
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+type Handler struct {
+	schema gql.Schema
+	limits Limits
+
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
+}
+
+func NewHandler(schema gql.Schema, limits Limits) *Handler {
+	return &Handler{
+		schema:   schema,
+		limits:   limits,
+		shutdown: make(chan struct{}),
+	}
+}
+
+// Shutdown ends all subscription streams with a "complete" event; streams
+// started later end right away. http.Server.Shutdown waits for active
+// requests, so without it open subscriptions would hold up the shutdown
+// until its timeout.
+func (h *Handler) Shutdown() {
+	h.shutdownOnce.Do(func() {
+		close(h.shutdown)
+	})
+}
+
+// Serve executes queries and mutations as plain JSON responses. Subscriptions
+// are streamed as server-sent events: every result is sent as a "next" event
+// and the stream ends with a "complete" event.
+func (h *Handler) Serve(c *gin.Context) {
+	req, err := h.bindRequest(c)
+	if err != nil {
+		logger.FromContext(c.Request.Context()).WithError(err).Warn("Failed to parse GraphQL request")
+		c.JSON(http.StatusBadRequest, errorResult("Invalid GraphQL request"))
+		return
+	}
+
+	if req.Query == "" {
+		c.JSON(http.StatusBadRequest, errorResult("Query must not be empty"))
+		return
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &gql.Result{Errors: gqlerrors.FormatErrors(err)})
+		return
+	}
+
+	operation := findOperation(doc, req.OperationName)
+	if operation == nil {
+		c.JSON(http.StatusBadRequest, errorResult("Unknown or ambiguous operation"))
+		return
+	}
+
+	if err := h.limits.Check(doc, operation, req.Variables); err != nil {
+		logger.FromContext(c.Request.Context()).WithError(err).Warn("GraphQL query rejected by limits")
+		c.JSON(http.StatusBadRequest, errorResult(err.Error()))
+		return
+	}
+
+	if operation.Operation == ast.OperationTypeMutation && c.Request.Method != http.MethodPost {
+		c.JSON(http.StatusMethodNotAllowed, errorResult("Mutations are only allowed over POST"))
+		return
+	}
+
+	params := gql.Params{
+		Schema:         h.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        context.WithValue(c.Request.Context(), variablesKey{}, req.Variables),
+	}
+
+	if operation.Operation == ast.OperationTypeSubscription {
+		h.stream(c, gql.Subscribe(params))
+		return
+	}
+
+	c.JSON(http.StatusOK, gql.Do(params))
+}
+
+func (h *Handler) stream(c *gin.Context, results chan *gql.Result) {
+	// Subscriptions outlive the server write timeout, so lift the deadline for this response.
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		logger.FromContext(c.Request.Context()).WithError(err).Debug("Failed to clear write deadline for subscription")
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+
+	ctx := c.Request.Context()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-h.shutdown:
+			c.SSEvent("complete", "")
+			return false
+		case result, ok := <-results:
+			if !ok {
+				c.SSEvent("complete", "")
+				return false
+			}
+			c.SSEvent("next", result)
+			return true
+		}
+	})
+
+	// The executor may still be blocked delivering a result; drain it so its goroutine exits.
+	go func() {
+		for range results {
+		}
+	}()
+}
+
+func (h *Handler) bindRequest(c *gin.Context) (*Request, error) {
+	var req Request
+
+	if c.Request.Method == http.MethodGet {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+
+		if variables := c.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				return nil, err
+			}
+		}
+
+		return &req, nil
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return nil, err
+	}
+
+	return &req, nil
+}
+
+func findOperation(doc *ast.Document, operationName string) *ast.OperationDefinition {
+	var found *ast.OperationDefinition
+
+	for _, definition := range doc.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+
+		if operationName == "" {
+			if found != nil {
+				return nil
+			}
+			found = operation
+			continue
+		}
+
+		if operation.Name != nil && operation.Name.Value == operationName {
+			return operation
+		}
+	}
+
+	return found
+}
+
+func errorResult(message string) *gql.Result {
+	return &gql.Result{
+		Errors: []gqlerrors.FormattedError{gqlerrors.NewFormattedError(message)},
+	}
+}
@@ -0,0 +1,361 @@
+package graphql_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rod1kutzyy/OnTrack/internal/event"
+	"github.com/rod1kutzyy/OnTrack/internal/graphql"
+	"github.com/rod1kutzyy/OnTrack/internal/logger"
+	"github.com/rod1kutzyy/OnTrack/internal/middleware"
+	"github.com/rod1kutzyy/OnTrack/internal/repository/memory"
+	"github.com/rod1kutzyy/OnTrack/internal/usecase"
+	"github.com/rod1kutzyy/OnTrack/internal/validator"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	if err := logger.InitWithOutput("error", io.Discard); err != nil {
+		panic(err)
+	}
+
+	os.Exit(m.Run())
+}
+
+// notifyingSubscriber reports every new subscription on subscribed.
+type notifyingSubscriber struct {
+	*event.Broker
+	subscribed chan struct{}
+}
+
+func (s *notifyingSubscriber) Subscribe() (<-chan event.TodoEvent, func()) {
+	events, unsubscribe := s.Broker.Subscribe()
+	s.subscribed <- struct{}{}
+	return events, unsubscribe
+}
+
+type testServer struct {
+	handler    *graphql.Handler
+	router     *gin.Engine
+	subscribed chan struct{}
+}
+
+// newTestServer serves the schema over an in-memory repository.
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+
+	broker := event.NewBroker()
+	subscriber := &notifyingSubscriber{Broker: broker, subscribed: make(chan struct{}, 1)}
+	todoRepo := memory.NewTodoRepository()
+	todoUseCase := usecase.NewTodoUseCase(todoRepo, memory.NewTransactor(todoRepo), broker)
+
+	schema, err := graphql.NewSchema(todoUseCase, validator.NewTodoValidator(), subscriber)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := graphql.NewHandler(schema, graphql.Limits{MaxDepth: 5, MaxComplexity: 200})
+	router := gin.New()
+	router.POST("/graphql", handler.Serve)
+	router.GET("/graphql", handler.Serve)
+
+	return &testServer{handler: handler, router: router, subscribed: subscriber.subscribed}
+}
+
+type result struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string `json:"message"`
+		Extensions struct {
+			Code string `json:"code"`
+		} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func (s *testServer) do(t *testing.T, query string, variables map[string]interface{}) (int, result) {
+	t.Helper()
+
+	body, err := json.Marshal(graphql.Request{Query: query, Variables: variables})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+
+	var res result
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatalf("failed to decode %s: %v", rec.Body, err)
+	}
+
+	return rec.Code, res
+}
+
+// field decodes a top-level field of a successful result.
+func field[T any](t *testing.T, res result, name string) T {
+	t.Helper()
+
+	if len(res.Errors) > 0 {
+		t.Fatalf("unexpected errors: %+v", res.Errors)
+	}
+
+	var v T
+	if err := json.Unmarshal(res.Data[name], &v); err != nil {
+		t.Fatalf("failed to decode %s: %v", res.Data[name], err)
+	}
+	return v
+}
+
+type todo struct {
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	Description *string `json:"description"`
+	Completed   bool    `json:"completed"`
+}
+
+const todoFields = `id title description completed`
+
+func TestResolvers(t *testing.T) {
+	s := newTestServer(t)
+
+	for _, title := range []string{"Buy milk", "Call mom", "Pay rent"} {
+		_, res := s.do(t, `mutation($input: CreateTodoInput!) { createTodo(input: $input) { `+todoFields+` } }`,
+			map[string]interface{}{"input": map[string]interface{}{"title": title, "description": "for " + title}})
+		if created := field[todo](t, res, "createTodo"); created.Title != title || created.Description == nil {
+			t.Errorf("createTodo = %+v, want %s with a description", created, title)
+		}
+	}
+
+	_, res := s.do(t, `{ todo(id: "2") { `+todoFields+` } }`, nil)
+	if got := field[todo](t, res, "todo"); got.ID != "2" || got.Title != "Call mom" {
+		t.Errorf("todo = %+v, want Call mom", got)
+	}
+
+	_, res = s.do(t, `{ todo(id: "42") { id } }`, nil)
+	if got := field[*todo](t, res, "todo"); got != nil {
+		t.Errorf("todo(42) = %+v, want null", got)
+	}
+
+	_, res = s.do(t, `mutation { updateTodo(id: "1", input: {title: "Buy oat milk", completed: true}) { `+todoFields+` } }`, nil)
+	if got := field[todo](t, res, "updateTodo"); got.Title != "Buy oat milk" || !got.Completed || got.Description == nil {
+		t.Errorf("updateTodo = %+v, want the title and status changed", got)
+	}
+
+	_, res = s.do(t, `mutation { toggleTodo(id: "2") { completed } }`, nil)
+	if got := field[todo](t, res, "toggleTodo"); !got.Completed {
+		t.Errorf("toggleTodo = %+v, want it completed", got)
+	}
+
+	_, res = s.do(t, `mutation { deleteTodo(id: "3") }`, nil)
+	if !field[bool](t, res, "deleteTodo") {
+		t.Error("deleteTodo = false, want true")
+	}
+
+	type todoList struct {
+		Items      []todo `json:"items"`
+		Pagination struct {
+			Total   int  `json:"total"`
+			HasNext bool `json:"hasNext"`
+		} `json:"pagination"`
+	}
+	_, res = s.do(t, `{ todos(completed: true, limit: 1) { items { title } pagination { total hasNext } } }`, nil)
+	if got := field[todoList](t, res, "todos"); len(got.Items) != 1 || got.Pagination.Total != 2 || !got.Pagination.HasNext {
+		t.Errorf("todos = %+v, want one of two completed todos", got)
+	}
+	_, res = s.do(t, `{ todos(search: "MOM") { items { title } pagination { total } } }`, nil)
+	if got := field[todoList](t, res, "todos"); len(got.Items) != 1 || got.Items[0].Title != "Call mom" {
+		t.Errorf("todos(search) = %+v, want Call mom", got)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		wantCode string
+	}{
+		{name: "invalid id", query: `{ todo(id: "abc") { id } }`, wantCode: "INVALID_ID"},
+		{name: "update missing", query: `mutation { updateTodo(id: "42", input: {title: "x"}) { id } }`, wantCode: "TODO_NOT_FOUND"},
+		{name: "delete missing", query: `mutation { deleteTodo(id: "3") }`, wantCode: "TODO_NOT_FOUND"},
+		{name: "toggle missing", query: `mutation { toggleTodo(id: "42") { id } }`, wantCode: "TODO_NOT_FOUND"},
+		{name: "empty title", query: `mutation { createTodo(input: {title: ""}) { id } }`, wantCode: "VALIDATION_ERROR"},
+		{name: "invalid filter", query: `{ todos(limit: -1) { items { id } } }`, wantCode: "VALIDATION_ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, res := s.do(t, tt.query, nil)
+			if len(res.Errors) != 1 || res.Errors[0].Extensions.Code != tt.wantCode {
+				t.Errorf("errors = %+v, want %s", res.Errors, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestServeRejectsRequests(t *testing.T) {
+	s := newTestServer(t)
+
+	status, res := s.do(t, `{ todos { items { id } } } { todo(id: "1") { id } }`, nil)
+	if status != http.StatusBadRequest || len(res.Errors) != 1 {
+		t.Errorf("ambiguous operation: status %d, errors %+v", status, res.Errors)
+	}
+
+	status, res = s.do(t, `{ todos { items { id } } }`, nil)
+	if status != http.StatusOK {
+		t.Errorf("query within limits: status %d, errors %+v", status, res.Errors)
+	}
+
+	status, res = s.do(t, `{ todos(limit: 100) { items { id title description completed createdAt updatedAt } } }`, nil)
+	if status != http.StatusBadRequest || len(res.Errors) != 1 || !strings.Contains(res.Errors[0].Message, "complexity") {
+		t.Errorf("complex query: status %d, errors %+v", status, res.Errors)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(`mutation { deleteTodo(id: "1") }`), nil)
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("mutation over GET: status %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestLogsCarryRequestID(t *testing.T) {
+	s := newTestServer(t)
+	router := gin.New()
+	router.Use(middleware.RequestID())
+	router.POST("/graphql", s.handler.Serve)
+
+	var logs bytes.Buffer
+	if err := logger.InitWithOutput("warn", &logs); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { logger.InitWithOutput("error", io.Discard) })
+
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query": "{ todos(limit: 100) { items { id title description completed createdAt updatedAt } } }"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.RequestIDHeader, "client-request-1")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status %d, want the query rejected by limits", rec.Code)
+	}
+	if !strings.Contains(logs.String(), "GraphQL query rejected by limits") || !strings.Contains(logs.String(), `"request_id": "client-request-1"`) {
+		t.Errorf("logs do not carry the request ID:\n%s", logs.String())
+	}
+}
+
+func TestUpdateDescription(t *testing.T) {
+	const (
+		withInput = `mutation($input: UpdateTodoInput!) { updateTodo(id: "1", input: $input) { description } }`
+		withField = `mutation($description: String) { updateTodo(id: "1", input: {description: $description, completed: true}) { description } }`
+	)
+
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]interface{}
+		want      *string
+	}{
+		{name: "omitted in input", query: withInput, variables: map[string]interface{}{"input": map[string]interface{}{"completed": true}}, want: ptr("2 litres")},
+		{name: "null in input", query: withInput, variables: map[string]interface{}{"input": map[string]interface{}{"description": nil}}},
+		{name: "empty in input", query: withInput, variables: map[string]interface{}{"input": map[string]interface{}{"description": ""}}},
+		{name: "changed in input", query: withInput, variables: map[string]interface{}{"input": map[string]interface{}{"description": "3 litres"}}, want: ptr("3 litres")},
+		{name: "variable not provided", query: withField, variables: map[string]interface{}{}, want: ptr("2 litres")},
+		{name: "null variable", query: withField, variables: map[string]interface{}{"description": nil}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t)
+			s.do(t, `mutation { createTodo(input: {title: "Buy milk", description: "2 litres"}) { id } }`, nil)
+
+			_, res := s.do(t, tt.query, tt.variables)
+			got := field[todo](t, res, "updateTodo").Description
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("description = %v, want %v", deref(got), deref(tt.want))
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func deref(s *string) interface{} {
+	if s == nil {
+		return nil
+	}
+	return *s
+}
+
+func TestShutdownEndsSubscriptions(t *testing.T) {
+	s := newTestServer(t)
+	server := httptest.NewServer(s.router)
+	defer server.Close()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	subscriptionURL := server.URL + "/graphql?query=" + url.QueryEscape(`subscription { todoChanged { type todo { title } } }`)
+
+	// Headers are only sent with the first event, so the request has to
+	// run while the event is published.
+	responses := make(chan *http.Response, 1)
+	go func() {
+		resp, err := client.Get(subscriptionURL)
+		if err != nil {
+			t.Errorf("subscription failed: %v", err)
+			close(responses)
+			return
+		}
+		responses <- resp
+	}()
+
+	select {
+	case <-s.subscribed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription did not start")
+	}
+	s.do(t, `mutation { createTodo(input: {title: "Buy milk"}) { id } }`, nil)
+
+	resp, ok := <-responses
+	if !ok {
+		return
+	}
+	defer resp.Body.Close()
+
+	reader := bufio.NewReader(resp.Body)
+	line, err := reader.ReadString('\n')
+	if err != nil || line != "event:next\n" {
+		t.Fatalf("first line = %q (%v), want the next event", line, err)
+	}
+
+	s.handler.Shutdown()
+
+	rest, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("stream did not end: %v", err)
+	}
+	if !strings.Contains(string(rest), `"title":"Buy milk"`) || !strings.HasSuffix(string(rest), "event:complete\ndata:\n\n") {
+		t.Errorf("stream = %q, want the event followed by complete", rest)
+	}
+
+	// Subscriptions started after the shutdown end right away.
+	resp, err = client.Get(subscriptionURL)
+	if err != nil {
+		t.Fatalf("subscription failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if body, err := io.ReadAll(resp.Body); err != nil || string(body) != "event:complete\ndata:\n\n" {
+		t.Errorf("stream after shutdown = %q (%v), want only complete", body, err)
+	}
+}
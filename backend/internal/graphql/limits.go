@@ -0,0 +1,228 @@
+package graphql
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// listMultipliers maps list-returning root fields to the argument that bounds
+// the number of returned items, so their selections are weighted accordingly.
+var listMultipliers = map[string]string{
+	"todos": "limit",
+}
+
+// maxListLimit is the most items a list field returns, as enforced by
+// dto.TodoFilterRequest.Validate; larger limit arguments cost no more.
+const maxListLimit = 100
+
+// Introspection fields are measured against fixed caps instead of the
+// configured limits, which are too tight for the introspection queries of
+// tools such as GraphiQL; their nested ofType selections alone reach a depth
+// of ten.
+const (
+	maxIntrospectionDepth      = 20
+	maxIntrospectionComplexity = 2000
+)
+
+// introspectionFields are the root fields that introspect the schema.
+var introspectionFields = map[string]bool{
+	"__schema": true,
+	"__type":   true,
+}
+
+type Limits struct {
+	MaxDepth      int
+	MaxComplexity int
+}
+
+type analyzer struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+// Check rejects operations whose selection depth or estimated complexity
+// exceeds the configured limits. A zero limit disables the corresponding
+// check. Root introspection fields are checked against fixed caps instead.
+func (l Limits) Check(doc *ast.Document, operation *ast.OperationDefinition, variables map[string]interface{}) error {
+	a := &analyzer{
+		fragments: make(map[string]*ast.FragmentDefinition),
+		variables: variables,
+	}
+
+	for _, definition := range doc.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			a.fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	selections, introspection := splitIntrospection(operation.SelectionSet)
+
+	if depth := a.depth(introspection, map[string]bool{}); depth > maxIntrospectionDepth {
+		return fmt.Errorf("introspection depth %d exceeds the maximum allowed depth of %d", depth, maxIntrospectionDepth)
+	}
+
+	if complexity := a.complexity(introspection, map[string]bool{}); complexity > maxIntrospectionComplexity {
+		return fmt.Errorf("introspection complexity %d exceeds the maximum allowed complexity of %d", complexity, maxIntrospectionComplexity)
+	}
+
+	if l.MaxDepth > 0 {
+		if depth := a.depth(selections, map[string]bool{}); depth > l.MaxDepth {
+			return fmt.Errorf("query depth %d exceeds the maximum allowed depth of %d", depth, l.MaxDepth)
+		}
+	}
+
+	if l.MaxComplexity > 0 {
+		if complexity := a.complexity(selections, map[string]bool{}); complexity > l.MaxComplexity {
+			return fmt.Errorf("query complexity %d exceeds the maximum allowed complexity of %d", complexity, l.MaxComplexity)
+		}
+	}
+
+	return nil
+}
+
+// splitIntrospection separates the root introspection fields of a selection
+// set from the others. Introspection fields selected through fragments stay
+// with the others and count against the configured limits.
+func splitIntrospection(selectionSet *ast.SelectionSet) (*ast.SelectionSet, *ast.SelectionSet) {
+	selections := &ast.SelectionSet{}
+	introspection := &ast.SelectionSet{}
+
+	if selectionSet == nil {
+		return selections, introspection
+	}
+
+	for _, selection := range selectionSet.Selections {
+		if field, ok := selection.(*ast.Field); ok && introspectionFields[field.Name.Value] {
+			introspection.Selections = append(introspection.Selections, selection)
+			continue
+		}
+		selections.Selections = append(selections.Selections, selection)
+	}
+
+	return selections, introspection
+}
+
+func (a *analyzer) depth(selectionSet *ast.SelectionSet, visited map[string]bool) int {
+	if selectionSet == nil {
+		return 0
+	}
+
+	maxDepth := 0
+	for _, selection := range selectionSet.Selections {
+		var depth int
+
+		switch s := selection.(type) {
+		case *ast.Field:
+			depth = 1 + a.depth(s.SelectionSet, visited)
+		case *ast.InlineFragment:
+			depth = a.depth(s.SelectionSet, visited)
+		case *ast.FragmentSpread:
+			depth = a.withFragment(s, visited, a.depth)
+		}
+
+		if depth > maxDepth {
+			maxDepth = depth
+		}
+	}
+
+	return maxDepth
+}
+
+func (a *analyzer) complexity(selectionSet *ast.SelectionSet, visited map[string]bool) int {
+	if selectionSet == nil {
+		return 0
+	}
+
+	total := 0
+	for _, selection := range selectionSet.Selections {
+		switch s := selection.(type) {
+		case *ast.Field:
+			total = saturatingAdd(total, saturatingAdd(1, saturatingMul(a.multiplier(s), a.complexity(s.SelectionSet, visited))))
+		case *ast.InlineFragment:
+			total = saturatingAdd(total, a.complexity(s.SelectionSet, visited))
+		case *ast.FragmentSpread:
+			total = saturatingAdd(total, a.withFragment(s, visited, a.complexity))
+		}
+	}
+
+	return total
+}
+
+func (a *analyzer) withFragment(spread *ast.FragmentSpread, visited map[string]bool, measure func(*ast.SelectionSet, map[string]bool) int) int {
+	name := spread.Name.Value
+
+	fragment, ok := a.fragments[name]
+	if !ok || visited[name] {
+		return 0
+	}
+
+	visited[name] = true
+	defer delete(visited, name)
+
+	return measure(fragment.SelectionSet, visited)
+}
+
+func (a *analyzer) multiplier(field *ast.Field) int {
+	argName, ok := listMultipliers[field.Name.Value]
+	if !ok {
+		return 1
+	}
+
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != argName {
+			continue
+		}
+
+		var n float64
+		switch v := arg.Value.(type) {
+		case *ast.IntValue:
+			parsed, err := strconv.ParseFloat(v.Value, 64)
+			if err != nil {
+				return defaultListLimit
+			}
+			n = parsed
+		case *ast.Variable:
+			switch value := a.variables[v.Name.Value].(type) {
+			case float64:
+				n = value
+			case int:
+				n = float64(value)
+			}
+		}
+
+		// The resolver treats limits below one as the default and caps the
+		// others; clamping before the conversion also keeps huge values from
+		// overflowing.
+		switch {
+		case n < 1:
+			return defaultListLimit
+		case n > maxListLimit:
+			return maxListLimit
+		default:
+			return int(n)
+		}
+	}
+
+	return defaultListLimit
+}
+
+// saturatingAdd adds two non-negative ints, returning math.MaxInt instead of
+// wrapping around.
+func saturatingAdd(a, b int) int {
+	if a > math.MaxInt-b {
+		return math.MaxInt
+	}
+	return a + b
+}
+
+// saturatingMul multiplies two non-negative ints, returning math.MaxInt
+// instead of wrapping around.
+func saturatingMul(a, b int) int {
+	if a != 0 && b > math.MaxInt/a {
+		return math.MaxInt
+	}
+	return a * b
+}
@@ -0,0 +1,179 @@
+package graphql_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/testutil"
+	"github.com/rod1kutzyy/OnTrack/internal/graphql"
+)
+
+func checkLimits(t *testing.T, limits graphql.Limits, query string, variables map[string]interface{}) error {
+	t.Helper()
+
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		t.Fatalf("failed to parse %q: %v", query, err)
+	}
+
+	for _, definition := range doc.Definitions {
+		if operation, ok := definition.(*ast.OperationDefinition); ok {
+			return limits.Check(doc, operation, variables)
+		}
+	}
+
+	t.Fatalf("%q has no operation", query)
+	return nil
+}
+
+func TestLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		limits    graphql.Limits
+		query     string
+		variables map[string]interface{}
+		wantErr   string
+	}{
+		{
+			name:   "depth within limit",
+			limits: graphql.Limits{MaxDepth: 3},
+			query:  `{ todos { items { id } } }`,
+		},
+		{
+			name:    "depth over limit",
+			limits:  graphql.Limits{MaxDepth: 2},
+			query:   `{ todos { items { id } } }`,
+			wantErr: "query depth 3 exceeds the maximum allowed depth of 2",
+		},
+		{
+			name:   "depth through fragments",
+			limits: graphql.Limits{MaxDepth: 3},
+			query:  `{ todos { ...List } } fragment List on TodoList { items { ... on Todo { id } } pagination { total } }`,
+		},
+		{
+			name:    "depth through nested fragments over limit",
+			limits:  graphql.Limits{MaxDepth: 2},
+			query:   `{ todos { ...List } } fragment List on TodoList { items { ...Fields } } fragment Fields on Todo { id }`,
+			wantErr: "query depth 3 exceeds the maximum allowed depth of 2",
+		},
+		{
+			name:   "introspection has its own caps",
+			limits: graphql.Limits{MaxDepth: 1, MaxComplexity: 1},
+			query:  `{ __schema { types { fields { type { name } } } } todo(id: "1") }`,
+		},
+		{
+			name:   "standard introspection query",
+			limits: graphql.Limits{MaxDepth: 1, MaxComplexity: 1},
+			query:  testutil.IntrospectionQuery,
+		},
+		{
+			name:    "deep introspection",
+			limits:  graphql.Limits{MaxDepth: 100, MaxComplexity: 10000},
+			query:   `{ __type(name: "Todo") { ` + strings.Repeat("ofType { ", 20) + `name` + strings.Repeat(" }", 20) + ` } }`,
+			wantErr: "introspection depth 22 exceeds the maximum allowed depth of 20",
+		},
+		{
+			name:    "introspection in fragments counts against the limits",
+			limits:  graphql.Limits{MaxDepth: 3},
+			query:   `{ ...Schema } fragment Schema on Query { __schema { types { fields { name } } } }`,
+			wantErr: "query depth 4 exceeds the maximum allowed depth of 3",
+		},
+		{
+			name:    "typename counts",
+			limits:  graphql.Limits{MaxComplexity: 2},
+			query:   `{ __typename todo(id: "1") { __typename } }`,
+			wantErr: "query complexity 3 exceeds the maximum allowed complexity of 2",
+		},
+		{
+			name:   "complexity with default limit",
+			limits: graphql.Limits{MaxComplexity: 16},
+			// todos counts 1 plus 5 times its items field and id and title.
+			query: `{ todos { items { id title } } }`,
+		},
+		{
+			name:    "complexity with literal limit",
+			limits:  graphql.Limits{MaxComplexity: 100},
+			query:   `{ todos(limit: 50) { items { id title } } }`,
+			wantErr: "query complexity 151 exceeds the maximum allowed complexity of 100",
+		},
+		{
+			name:      "complexity with variable limit",
+			limits:    graphql.Limits{MaxComplexity: 100},
+			query:     `query List($limit: Int) { todos(limit: $limit) { items { id title } } }`,
+			variables: map[string]interface{}{"limit": float64(50)},
+			wantErr:   "query complexity 151 exceeds the maximum allowed complexity of 100",
+		},
+		{
+			name:    "limit above the maximum",
+			limits:  graphql.Limits{MaxComplexity: 300},
+			query:   `{ todos(limit: 1000) { items { id title } } }`,
+			wantErr: "query complexity 301 exceeds the maximum allowed complexity of 300",
+		},
+		{
+			name:    "huge literal limit",
+			limits:  graphql.Limits{MaxComplexity: 300},
+			query:   `{ todos(limit: 99999999999999999999) { items { id title } } }`,
+			wantErr: "query complexity 301 exceeds the maximum allowed complexity of 300",
+		},
+		{
+			name:      "huge variable limit",
+			limits:    graphql.Limits{MaxComplexity: 300},
+			query:     `query List($limit: Int) { a: todos(limit: $limit) { items { id title } } b: todos(limit: $limit) { items { id } } }`,
+			variables: map[string]interface{}{"limit": 1e300},
+			wantErr:   "query complexity 502 exceeds the maximum allowed complexity of 300",
+		},
+		{
+			name:      "negative limit",
+			limits:    graphql.Limits{MaxComplexity: 16},
+			query:     `query List($limit: Int) { todos(limit: $limit) { items { id title } } }`,
+			variables: map[string]interface{}{"limit": float64(-1e300)},
+		},
+		{
+			name:    "complexity adds up fields",
+			limits:  graphql.Limits{MaxComplexity: 5},
+			query:   `{ a: todo(id: "1") { id title } b: todo(id: "2") { id title } }`,
+			wantErr: "query complexity 6 exceeds the maximum allowed complexity of 5",
+		},
+		{
+			name:   "zero limits disable the checks",
+			limits: graphql.Limits{},
+			query:  `{ todos(limit: 100) { items { id title description completed createdAt updatedAt } } }`,
+		},
+		{
+			name:   "fragment cycle",
+			limits: graphql.Limits{MaxDepth: 2, MaxComplexity: 3},
+			// Each fragment is expanded once per path, so todo counts 1, id 1
+			// and title 1.
+			query: `{ todo(id: "1") { ...A } } fragment A on Todo { id ...B } fragment B on Todo { title ...A }`,
+		},
+		{
+			name:    "fragment cycle over limit",
+			limits:  graphql.Limits{MaxDepth: 1},
+			query:   `{ todo(id: "1") { ...A } } fragment A on Todo { id ...A }`,
+			wantErr: "query depth 2 exceeds the maximum allowed depth of 1",
+		},
+		{
+			name:   "unknown fragment",
+			limits: graphql.Limits{MaxDepth: 1},
+			query:  `{ todo(id: "1") { ...Missing } }`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkLimits(t, tt.limits, tt.query, tt.variables)
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Check() = %v, want no error", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Check() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
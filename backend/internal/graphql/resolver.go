@@ -0,0 +1,313 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/rod1kutzyy/OnTrack/internal/domain"
+	"github.com/rod1kutzyy/OnTrack/internal/dto"
+	"github.com/rod1kutzyy/OnTrack/internal/event"
+	"github.com/rod1kutzyy/OnTrack/internal/logger"
+	"github.com/rod1kutzyy/OnTrack/internal/usecase"
+	"github.com/rod1kutzyy/OnTrack/internal/validator"
+)
+
+const defaultListLimit = 5
+
+// variablesKey is the context key of the variables as sent in the request.
+type variablesKey struct{}
+
+type resolver struct {
+	todoUseCase usecase.TodoUseCase
+	validator   *validator.TodoValidator
+	subscriber  event.Subscriber
+}
+
+func (r *resolver) todo(p gql.ResolveParams) (interface{}, error) {
+	id, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := r.todoUseCase.GetTodoByID(p.Context, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, nil
+		}
+
+		logger.FromContext(p.Context).WithError(err).Error("Failed to get todo")
+		return nil, newError("Failed to retrieve todo", "INTERNAL_ERROR", nil)
+	}
+
+	return mapTodo(todo), nil
+}
+
+func (r *resolver) todos(p gql.ResolveParams) (interface{}, error) {
+	filter := dto.TodoFilterRequest{
+		Page:  1,
+		Limit: defaultListLimit,
+	}
+
+	if completed, ok := p.Args["completed"].(bool); ok {
+		filter.Completed = &completed
+	}
+	if search, ok := p.Args["search"].(string); ok {
+		filter.Search = search
+	}
+	if page, ok := p.Args["page"].(int); ok {
+		filter.Page = page
+	}
+	if limit, ok := p.Args["limit"].(int); ok {
+		filter.Limit = limit
+	}
+
+	if validationErrors := r.validator.ValidateFilter(filter); len(validationErrors) > 0 {
+		return nil, newError("Request validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	filter.Validate()
+
+	domainFilter := domain.TodoFilter{
+		Completed: filter.Completed,
+		Search:    filter.Search,
+		Limit:     filter.Limit,
+		Offset:    filter.GetOffset(),
+	}
+
+	todos, total, err := r.todoUseCase.GetAllTodos(p.Context, domainFilter)
+	if err != nil {
+		logger.FromContext(p.Context).WithError(err).Error("Failed to get todos")
+		return nil, newError("Failed to retrieve todos", "INTERNAL_ERROR", nil)
+	}
+
+	items := make([]map[string]interface{}, len(todos))
+	for i := range todos {
+		items[i] = mapTodo(&todos[i])
+	}
+
+	pagination := dto.NewPaginationResponse(total, filter.Page, filter.Limit)
+
+	return map[string]interface{}{
+		"items": items,
+		"pagination": map[string]interface{}{
+			"total":       int(pagination.Total),
+			"totalPages":  pagination.TotalPages,
+			"currentPage": pagination.CurrentPage,
+			"perPage":     pagination.PerPage,
+			"hasNext":     pagination.HasNext,
+			"hasPrev":     pagination.HasPrev,
+		},
+	}, nil
+}
+
+func (r *resolver) createTodo(p gql.ResolveParams) (interface{}, error) {
+	input, _ := p.Args["input"].(map[string]interface{})
+
+	var req dto.CreateTodoRequest
+	req.Title, _ = input["title"].(string)
+	if description, ok := input["description"].(string); ok {
+		req.Description = &description
+	}
+
+	if validationErrors := r.validator.ValidateCreateTodo(req); len(validationErrors) > 0 {
+		return nil, newError("Request validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	todo, err := r.todoUseCase.CreateTodo(p.Context, req)
+	if err != nil {
+		logger.FromContext(p.Context).WithError(err).Error("Failed to create todo")
+		return nil, newError("Failed to create todo", "INTERNAL_ERROR", nil)
+	}
+
+	return mapTodo(todo), nil
+}
+
+func (r *resolver) updateTodo(p gql.ResolveParams) (interface{}, error) {
+	id, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+
+	input, _ := p.Args["input"].(map[string]interface{})
+
+	var req dto.UpdateTodoRequest
+	if title, ok := input["title"].(string); ok {
+		req.Title = &title
+	}
+	if description, ok := input["description"].(string); ok {
+		req.Description = &description
+	} else if isExplicitNull(p, "input", "description") {
+		// Like an empty string, null clears the description.
+		cleared := ""
+		req.Description = &cleared
+	}
+	if completed, ok := input["completed"].(bool); ok {
+		req.Completed = &completed
+	}
+
+	if validationErrors := r.validator.ValidateUpdateTodo(req); len(validationErrors) > 0 {
+		return nil, newError("Request validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	todo, err := r.todoUseCase.UpdateTodo(p.Context, id, req)
+	if err != nil {
+		return nil, mapUseCaseError(p.Context, err, id, "Failed to update todo")
+	}
+
+	return mapTodo(todo), nil
+}
+
+func (r *resolver) deleteTodo(p gql.ResolveParams) (interface{}, error) {
+	id, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.todoUseCase.DeleteTodo(p.Context, id); err != nil {
+		return nil, mapUseCaseError(p.Context, err, id, "Failed to delete todo")
+	}
+
+	return true, nil
+}
+
+func (r *resolver) toggleTodo(p gql.ResolveParams) (interface{}, error) {
+	id, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := r.todoUseCase.ToggleTodoComplete(p.Context, id)
+	if err != nil {
+		return nil, mapUseCaseError(p.Context, err, id, "Failed to toggle completion status")
+	}
+
+	return mapTodo(todo), nil
+}
+
+func (r *resolver) subscribeTodoChanged(p gql.ResolveParams) (interface{}, error) {
+	wanted := make(map[event.TodoEventType]bool)
+	if types, ok := p.Args["types"].([]interface{}); ok {
+		for _, t := range types {
+			if name, ok := t.(string); ok {
+				wanted[event.TodoEventType(name)] = true
+			}
+		}
+	}
+
+	events, unsubscribe := r.subscriber.Subscribe()
+	results := make(chan interface{})
+
+	go func() {
+		defer close(results)
+		defer unsubscribe()
+
+		for {
+			select {
+			case <-p.Context.Done():
+				return
+			case e, ok := <-events:
+				if !ok {
+					return
+				}
+				if len(wanted) > 0 && !wanted[e.Type] {
+					continue
+				}
+
+				select {
+				case results <- e:
+				case <-p.Context.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return results, nil
+}
+
+func (r *resolver) todoChanged(p gql.ResolveParams) (interface{}, error) {
+	e, ok := p.Source.(event.TodoEvent)
+	if !ok {
+		return nil, fmt.Errorf("unexpected subscription payload %T", p.Source)
+	}
+
+	return map[string]interface{}{
+		"type":       string(e.Type),
+		"todo":       mapTodo(&e.Todo),
+		"occurredAt": e.OccurredAt,
+	}, nil
+}
+
+func mapTodo(todo *domain.Todo) map[string]interface{} {
+	var description interface{}
+	if todo.Description != nil {
+		description = *todo.Description
+	}
+
+	return map[string]interface{}{
+		"id":          strconv.FormatUint(uint64(todo.ID), 10),
+		"title":       todo.Title,
+		"description": description,
+		"completed":   todo.Completed,
+		"createdAt":   todo.CreatedAt,
+		"updatedAt":   todo.UpdatedAt,
+	}
+}
+
+func mapUseCaseError(ctx context.Context, err error, id uint, message string) error {
+	if strings.Contains(err.Error(), "not found") {
+		return newError(fmt.Sprintf("Todo with ID %d not found", id), "TODO_NOT_FOUND", nil)
+	}
+
+	logger.FromContext(ctx).WithError(err).Error(message)
+	return newError(message, "INTERNAL_ERROR", nil)
+}
+
+// isExplicitNull reports whether field of the input object argument was set
+// to null. graphql-go leaves such fields out of p.Args as if they had been
+// omitted, and null can only be passed through variables, so this looks the
+// variables up as sent in the request.
+func isExplicitNull(p gql.ResolveParams, argument, field string) bool {
+	variables, _ := p.Context.Value(variablesKey{}).(map[string]interface{})
+	isNull := func(values map[string]interface{}, name string) bool {
+		value, ok := values[name]
+		return ok && value == nil
+	}
+
+	for _, fieldAST := range p.Info.FieldASTs {
+		for _, arg := range fieldAST.Arguments {
+			if arg.Name.Value != argument {
+				continue
+			}
+
+			switch value := arg.Value.(type) {
+			case *ast.Variable:
+				input, _ := variables[value.Name.Value].(map[string]interface{})
+				return isNull(input, field)
+			case *ast.ObjectValue:
+				for _, objectField := range value.Fields {
+					if variable, ok := objectField.Value.(*ast.Variable); ok && objectField.Name.Value == field {
+						return isNull(variables, variable.Name.Value)
+					}
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+func parseID(value interface{}) (uint, error) {
+	idStr, _ := value.(string)
+
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		return 0, newError("Invalid todo ID format", "INVALID_ID", nil)
+	}
+
+	return uint(id), nil
+}
@@ -0,0 +1,165 @@
+package graphql
+
+import (
+	gql "github.com/graphql-go/graphql"
+	"github.com/rod1kutzyy/OnTrack/internal/event"
+	"github.com/rod1kutzyy/OnTrack/internal/usecase"
+	"github.com/rod1kutzyy/OnTrack/internal/validator"
+)
+
+func NewSchema(todoUseCase usecase.TodoUseCase, validator *validator.TodoValidator, subscriber event.Subscriber) (gql.Schema, error) {
+	r := &resolver{
+		todoUseCase: todoUseCase,
+		validator:   validator,
+		subscriber:  subscriber,
+	}
+
+	todoType := gql.NewObject(gql.ObjectConfig{
+		Name: "Todo",
+		Fields: gql.Fields{
+			"id":          &gql.Field{Type: gql.NewNonNull(gql.ID)},
+			"title":       &gql.Field{Type: gql.NewNonNull(gql.String)},
+			"description": &gql.Field{Type: gql.String},
+			"completed":   &gql.Field{Type: gql.NewNonNull(gql.Boolean)},
+			"createdAt":   &gql.Field{Type: gql.NewNonNull(gql.DateTime)},
+			"updatedAt":   &gql.Field{Type: gql.NewNonNull(gql.DateTime)},
+		},
+	})
+
+	paginationType := gql.NewObject(gql.ObjectConfig{
+		Name: "Pagination",
+		Fields: gql.Fields{
+			"total":       &gql.Field{Type: gql.NewNonNull(gql.Int)},
+			"totalPages":  &gql.Field{Type: gql.NewNonNull(gql.Int)},
+			"currentPage": &gql.Field{Type: gql.NewNonNull(gql.Int)},
+			"perPage":     &gql.Field{Type: gql.NewNonNull(gql.Int)},
+			"hasNext":     &gql.Field{Type: gql.NewNonNull(gql.Boolean)},
+			"hasPrev":     &gql.Field{Type: gql.NewNonNull(gql.Boolean)},
+		},
+	})
+
+	todoListType := gql.NewObject(gql.ObjectConfig{
+		Name: "TodoList",
+		Fields: gql.Fields{
+			"items":      &gql.Field{Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(todoType)))},
+			"pagination": &gql.Field{Type: gql.NewNonNull(paginationType)},
+		},
+	})
+
+	todoEventTypeEnum := gql.NewEnum(gql.EnumConfig{
+		Name: "TodoEventType",
+		Values: gql.EnumValueConfigMap{
+			string(event.TodoCreated): &gql.EnumValueConfig{Value: string(event.TodoCreated)},
+			string(event.TodoUpdated): &gql.EnumValueConfig{Value: string(event.TodoUpdated)},
+			string(event.TodoDeleted): &gql.EnumValueConfig{Value: string(event.TodoDeleted)},
+		},
+	})
+
+	todoEventType := gql.NewObject(gql.ObjectConfig{
+		Name: "TodoEvent",
+		Fields: gql.Fields{
+			"type":       &gql.Field{Type: gql.NewNonNull(todoEventTypeEnum)},
+			"todo":       &gql.Field{Type: gql.NewNonNull(todoType)},
+			"occurredAt": &gql.Field{Type: gql.NewNonNull(gql.DateTime)},
+		},
+	})
+
+	createTodoInput := gql.NewInputObject(gql.InputObjectConfig{
+		Name: "CreateTodoInput",
+		Fields: gql.InputObjectConfigFieldMap{
+			"title":       &gql.InputObjectFieldConfig{Type: gql.NewNonNull(gql.String)},
+			"description": &gql.InputObjectFieldConfig{Type: gql.String},
+		},
+	})
+
+	updateTodoInput := gql.NewInputObject(gql.InputObjectConfig{
+		Name: "UpdateTodoInput",
+		Fields: gql.InputObjectConfigFieldMap{
+			"title": &gql.InputObjectFieldConfig{Type: gql.String},
+			"description": &gql.InputObjectFieldConfig{
+				Type:        gql.String,
+				Description: "Omit to keep the description; null or an empty string clears it",
+			},
+			"completed": &gql.InputObjectFieldConfig{Type: gql.Boolean},
+		},
+	})
+
+	idArgs := gql.FieldConfigArgument{
+		"id": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID)},
+	}
+
+	queryType := gql.NewObject(gql.ObjectConfig{
+		Name: "Query",
+		Fields: gql.Fields{
+			"todo": &gql.Field{
+				Type:        todoType,
+				Description: "Returns a single todo by its ID, or null if it does not exist",
+				Args:        idArgs,
+				Resolve:     r.todo,
+			},
+			"todos": &gql.Field{
+				Type:        gql.NewNonNull(todoListType),
+				Description: "Returns a paginated list of todos, optionally filtered by completion status or search term",
+				Args: gql.FieldConfigArgument{
+					"completed": &gql.ArgumentConfig{Type: gql.Boolean},
+					"search":    &gql.ArgumentConfig{Type: gql.String},
+					"page":      &gql.ArgumentConfig{Type: gql.Int, DefaultValue: 1},
+					"limit":     &gql.ArgumentConfig{Type: gql.Int, DefaultValue: defaultListLimit},
+				},
+				Resolve: r.todos,
+			},
+		},
+	})
+
+	mutationType := gql.NewObject(gql.ObjectConfig{
+		Name: "Mutation",
+		Fields: gql.Fields{
+			"createTodo": &gql.Field{
+				Type: gql.NewNonNull(todoType),
+				Args: gql.FieldConfigArgument{
+					"input": &gql.ArgumentConfig{Type: gql.NewNonNull(createTodoInput)},
+				},
+				Resolve: r.createTodo,
+			},
+			"updateTodo": &gql.Field{
+				Type: gql.NewNonNull(todoType),
+				Args: gql.FieldConfigArgument{
+					"id":    &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID)},
+					"input": &gql.ArgumentConfig{Type: gql.NewNonNull(updateTodoInput)},
+				},
+				Resolve: r.updateTodo,
+			},
+			"deleteTodo": &gql.Field{
+				Type:    gql.NewNonNull(gql.Boolean),
+				Args:    idArgs,
+				Resolve: r.deleteTodo,
+			},
+			"toggleTodo": &gql.Field{
+				Type:    gql.NewNonNull(todoType),
+				Args:    idArgs,
+				Resolve: r.toggleTodo,
+			},
+		},
+	})
+
+	subscriptionType := gql.NewObject(gql.ObjectConfig{
+		Name: "Subscription",
+		Fields: gql.Fields{
+			"todoChanged": &gql.Field{
+				Type:        gql.NewNonNull(todoEventType),
+				Description: "Streams todo changes, optionally restricted to the given event types",
+				Args: gql.FieldConfigArgument{
+					"types": &gql.ArgumentConfig{Type: gql.NewList(gql.NewNonNull(todoEventTypeEnum))},
+				},
+				Subscribe: r.subscribeTodoChanged,
+				Resolve:   r.todoChanged,
+			},
+		},
+	})
+
+	return gql.NewSchema(gql.SchemaConfig{
+		Query:        queryType,
+		Mutation:     mutationType,
+		Subscription: subscriptionType,
+	})
+}
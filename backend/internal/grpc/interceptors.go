@@ -0,0 +1,121 @@
+package grpc
+
+import (
+	"context"
+	"runtime/debug"
+	"time"
+
+	"github.com/rod1kutzyy/OnTrack/internal/logger"
+	"github.com/rod1kutzyy/OnTrack/internal/middleware"
+	"github.com/sirupsen/logrus"
+	grpcgo "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// requestIDMetadataKey carries the request ID in the metadata of calls and
+// their response headers, like the X-Request-ID header of the HTTP API.
+const requestIDMetadataKey = "x-request-id"
+
+// requestIDUnaryInterceptor accepts the request ID of the caller or
+// generates one, returns it in the response header and puts a logger that
+// adds it to every line into the context, see logger.FromContext.
+func requestIDUnaryInterceptor(ctx context.Context, req interface{}, info *grpcgo.UnaryServerInfo, handler grpcgo.UnaryHandler) (interface{}, error) {
+	ctx, requestID := withRequestID(ctx)
+
+	if err := grpcgo.SetHeader(ctx, metadata.Pairs(requestIDMetadataKey, requestID)); err != nil {
+		logger.FromContext(ctx).WithError(err).Debug("Failed to set request ID header")
+	}
+
+	return handler(ctx, req)
+}
+
+func requestIDStreamInterceptor(srv interface{}, ss grpcgo.ServerStream, info *grpcgo.StreamServerInfo, handler grpcgo.StreamHandler) error {
+	ctx, requestID := withRequestID(ss.Context())
+
+	if err := ss.SetHeader(metadata.Pairs(requestIDMetadataKey, requestID)); err != nil {
+		logger.FromContext(ctx).WithError(err).Debug("Failed to set request ID header")
+	}
+
+	return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+}
+
+func withRequestID(ctx context.Context) (context.Context, string) {
+	var requestID string
+	if values := metadata.ValueFromIncomingContext(ctx, requestIDMetadataKey); len(values) > 0 {
+		requestID = values[0]
+	}
+	requestID = middleware.AcceptRequestID(requestID)
+
+	return middleware.WithRequestID(ctx, requestID), requestID
+}
+
+// contextStream replaces the context of a server stream.
+type contextStream struct {
+	grpcgo.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+func loggingUnaryInterceptor(ctx context.Context, req interface{}, info *grpcgo.UnaryServerInfo, handler grpcgo.UnaryHandler) (interface{}, error) {
+	startTime := time.Now()
+
+	resp, err := handler(ctx, req)
+
+	logCall(ctx, info.FullMethod, startTime, err)
+	return resp, err
+}
+
+func loggingStreamInterceptor(srv interface{}, ss grpcgo.ServerStream, info *grpcgo.StreamServerInfo, handler grpcgo.StreamHandler) error {
+	startTime := time.Now()
+
+	err := handler(srv, ss)
+
+	logCall(ss.Context(), info.FullMethod, startTime, err)
+	return err
+}
+
+func recoveryUnaryInterceptor(ctx context.Context, req interface{}, info *grpcgo.UnaryServerInfo, handler grpcgo.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.FromContext(ctx).WithField("stack", string(debug.Stack())).Errorf("Panic recovered: %v", r)
+			err = status.Error(codes.Internal, "internal server error")
+		}
+	}()
+
+	return handler(ctx, req)
+}
+
+func recoveryStreamInterceptor(srv interface{}, ss grpcgo.ServerStream, info *grpcgo.StreamServerInfo, handler grpcgo.StreamHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.FromContext(ss.Context()).WithField("stack", string(debug.Stack())).Errorf("Panic recovered: %v", r)
+			err = status.Error(codes.Internal, "internal server error")
+		}
+	}()
+
+	return handler(srv, ss)
+}
+
+func logCall(ctx context.Context, method string, startTime time.Time, err error) {
+	code := status.Code(err)
+
+	logEntry := logger.FromContext(ctx).WithFields(logrus.Fields{
+		"grpc_method": method,
+		"grpc_code":   code.String(),
+		"latency":     time.Since(startTime).Milliseconds(),
+	})
+
+	switch code {
+	case codes.OK:
+		logEntry.Info("RPC processed")
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+		logEntry.Error("RPC server error")
+	default:
+		logEntry.Warn("RPC client error")
+	}
+}
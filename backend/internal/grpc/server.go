@@ -0,0 +1,28 @@
+package grpc
+
+import (
+	todov1 "github.com/rod1kutzyy/OnTrack/api/proto/todo/v1"
+	grpcgo "google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// NewServer builds a gRPC server exposing the TodoService together with the
+// standard health checking and reflection services.
+func NewServer(todoServer *TodoServer) (*grpcgo.Server, *health.Server) {
+	server := grpcgo.NewServer(
+		grpcgo.ChainUnaryInterceptor(requestIDUnaryInterceptor, recoveryUnaryInterceptor, loggingUnaryInterceptor),
+		grpcgo.ChainStreamInterceptor(requestIDStreamInterceptor, recoveryStreamInterceptor, loggingStreamInterceptor),
+	)
+
+	todov1.RegisterTodoServiceServer(server, todoServer)
+
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	healthServer.SetServingStatus(todov1.TodoService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+
+	reflection.Register(server)
+
+	return server, healthServer
+}
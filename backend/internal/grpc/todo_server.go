@@ -0,0 +1,172 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	todov1 "github.com/rod1kutzyy/OnTrack/api/proto/todo/v1"
+	"github.com/rod1kutzyy/OnTrack/internal/domain"
+	"github.com/rod1kutzyy/OnTrack/internal/dto"
+	"github.com/rod1kutzyy/OnTrack/internal/logger"
+	"github.com/rod1kutzyy/OnTrack/internal/usecase"
+	"github.com/rod1kutzyy/OnTrack/internal/validator"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const maxListBatchSize = 100
+
+type TodoServer struct {
+	todov1.UnimplementedTodoServiceServer
+
+	todoUseCase usecase.TodoUseCase
+	validator   *validator.TodoValidator
+}
+
+func NewTodoServer(todoUseCase usecase.TodoUseCase, validator *validator.TodoValidator) *TodoServer {
+	return &TodoServer{
+		todoUseCase: todoUseCase,
+		validator:   validator,
+	}
+}
+
+func (s *TodoServer) CreateTodo(ctx context.Context, req *todov1.CreateTodoRequest) (*todov1.CreateTodoResponse, error) {
+	createReq := dto.CreateTodoRequest{
+		Title:       req.GetTitle(),
+		Description: req.Description,
+	}
+
+	if validationErrors := s.validator.ValidateCreateTodo(createReq); len(validationErrors) > 0 {
+		return nil, validationStatus(validationErrors)
+	}
+
+	todo, err := s.todoUseCase.CreateTodo(ctx, createReq)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Failed to create todo")
+		return nil, status.Error(codes.Internal, "failed to create todo")
+	}
+
+	return &todov1.CreateTodoResponse{Todo: mapTodoToProto(todo)}, nil
+}
+
+func (s *TodoServer) GetTodo(ctx context.Context, req *todov1.GetTodoRequest) (*todov1.GetTodoResponse, error) {
+	todo, err := s.todoUseCase.GetTodoByID(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, useCaseStatus(ctx, err, req.GetId(), "failed to retrieve todo")
+	}
+
+	return &todov1.GetTodoResponse{Todo: mapTodoToProto(todo)}, nil
+}
+
+func (s *TodoServer) ListTodos(req *todov1.ListTodosRequest, stream todov1.TodoService_ListTodosServer) error {
+	filterReq := dto.TodoFilterRequest{
+		Completed: req.Completed,
+		Search:    req.GetSearch(),
+		Page:      1,
+		Limit:     int(req.GetBatchSize()),
+	}
+	if filterReq.Limit == 0 {
+		filterReq.Limit = maxListBatchSize
+	}
+
+	if validationErrors := s.validator.ValidateFilter(filterReq); len(validationErrors) > 0 {
+		return validationStatus(validationErrors)
+	}
+
+	filter := domain.TodoFilter{
+		Completed: filterReq.Completed,
+		Search:    filterReq.Search,
+		Limit:     filterReq.Limit,
+	}
+
+	err := s.todoUseCase.IterateTodos(stream.Context(), filter, func(todo *domain.Todo) error {
+		return stream.Send(&todov1.ListTodosResponse{Todo: mapTodoToProto(todo)})
+	})
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+
+		logger.FromContext(stream.Context()).WithError(err).Error("Failed to get todos")
+		return status.Error(codes.Internal, "failed to retrieve todos")
+	}
+
+	return nil
+}
+
+func (s *TodoServer) UpdateTodo(ctx context.Context, req *todov1.UpdateTodoRequest) (*todov1.UpdateTodoResponse, error) {
+	updateReq := dto.UpdateTodoRequest{
+		Title:       req.Title,
+		Description: req.Description,
+		Completed:   req.Completed,
+	}
+
+	if validationErrors := s.validator.ValidateUpdateTodo(updateReq); len(validationErrors) > 0 {
+		return nil, validationStatus(validationErrors)
+	}
+
+	todo, err := s.todoUseCase.UpdateTodo(ctx, uint(req.GetId()), updateReq)
+	if err != nil {
+		return nil, useCaseStatus(ctx, err, req.GetId(), "failed to update todo")
+	}
+
+	return &todov1.UpdateTodoResponse{Todo: mapTodoToProto(todo)}, nil
+}
+
+func (s *TodoServer) DeleteTodo(ctx context.Context, req *todov1.DeleteTodoRequest) (*todov1.DeleteTodoResponse, error) {
+	if err := s.todoUseCase.DeleteTodo(ctx, uint(req.GetId())); err != nil {
+		return nil, useCaseStatus(ctx, err, req.GetId(), "failed to delete todo")
+	}
+
+	return &todov1.DeleteTodoResponse{}, nil
+}
+
+func (s *TodoServer) ToggleTodo(ctx context.Context, req *todov1.ToggleTodoRequest) (*todov1.ToggleTodoResponse, error) {
+	todo, err := s.todoUseCase.ToggleTodoComplete(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, useCaseStatus(ctx, err, req.GetId(), "failed to toggle completion status")
+	}
+
+	return &todov1.ToggleTodoResponse{Todo: mapTodoToProto(todo)}, nil
+}
+
+func mapTodoToProto(todo *domain.Todo) *todov1.Todo {
+	return &todov1.Todo{
+		Id:          uint32(todo.ID),
+		Title:       todo.Title,
+		Description: todo.Description,
+		Completed:   todo.Completed,
+		CreatedAt:   timestamppb.New(todo.CreatedAt),
+		UpdatedAt:   timestamppb.New(todo.UpdatedAt),
+	}
+}
+
+func useCaseStatus(ctx context.Context, err error, id uint32, message string) error {
+	if strings.Contains(err.Error(), "not found") {
+		return status.Error(codes.NotFound, fmt.Sprintf("todo with ID %d not found", id))
+	}
+
+	logger.FromContext(ctx).WithError(err).Error(message)
+	return status.Error(codes.Internal, message)
+}
+
+func validationStatus(validationErrors []dto.ValidationError) error {
+	badRequest := &errdetails.BadRequest{}
+	for _, validationError := range validationErrors {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       validationError.Field,
+			Description: validationError.Message,
+		})
+	}
+
+	st := status.New(codes.InvalidArgument, "request validation failed")
+	detailed, err := st.WithDetails(badRequest)
+	if err != nil {
+		return st.Err()
+	}
+
+	return detailed.Err()
+}
@@ -0,0 +1,289 @@
+package grpc_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	todov1 "github.com/rod1kutzyy/OnTrack/api/proto/todo/v1"
+	"github.com/rod1kutzyy/OnTrack/internal/event"
+	"github.com/rod1kutzyy/OnTrack/internal/grpc"
+	"github.com/rod1kutzyy/OnTrack/internal/logger"
+	"github.com/rod1kutzyy/OnTrack/internal/repository/memory"
+	"github.com/rod1kutzyy/OnTrack/internal/usecase"
+	"github.com/rod1kutzyy/OnTrack/internal/validator"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	grpcgo "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestMain(m *testing.M) {
+	if err := logger.InitWithOutput("error", io.Discard); err != nil {
+		panic(err)
+	}
+
+	os.Exit(m.Run())
+}
+
+// newTestClient serves the TodoService over an in-memory connection, backed
+// by an in-memory repository.
+func newTestClient(t *testing.T) todov1.TodoServiceClient {
+	t.Helper()
+
+	todoRepo := memory.NewTodoRepository()
+	todoUseCase := usecase.NewTodoUseCase(todoRepo, memory.NewTransactor(todoRepo), event.NewBroker())
+	server, _ := grpc.NewServer(grpc.NewTodoServer(todoUseCase, validator.NewTodoValidator()))
+
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpcgo.NewClient("passthrough:///bufconn",
+		grpcgo.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpcgo.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return todov1.NewTodoServiceClient(conn)
+}
+
+func listTodos(t *testing.T, client todov1.TodoServiceClient, req *todov1.ListTodosRequest) []string {
+	t.Helper()
+
+	stream, err := client.ListTodos(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ListTodos failed: %v", err)
+	}
+
+	var titles []string
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return titles
+		}
+		if err != nil {
+			t.Fatalf("ListTodos failed: %v", err)
+		}
+		titles = append(titles, resp.GetTodo().GetTitle())
+	}
+}
+
+func TestTodoServiceCRUD(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	created, err := client.CreateTodo(ctx, &todov1.CreateTodoRequest{Title: "Buy milk", Description: proto.String("2 litres")})
+	if err != nil {
+		t.Fatalf("CreateTodo failed: %v", err)
+	}
+	todo := created.GetTodo()
+	if todo.GetId() == 0 || todo.GetTitle() != "Buy milk" || todo.GetDescription() != "2 litres" || todo.GetCompleted() || todo.GetCreatedAt() == nil {
+		t.Errorf("CreateTodo() = %v", todo)
+	}
+
+	got, err := client.GetTodo(ctx, &todov1.GetTodoRequest{Id: todo.GetId()})
+	if err != nil {
+		t.Fatalf("GetTodo failed: %v", err)
+	}
+	if !proto.Equal(got.GetTodo(), todo) {
+		t.Errorf("GetTodo() = %v, want %v", got.GetTodo(), todo)
+	}
+
+	updated, err := client.UpdateTodo(ctx, &todov1.UpdateTodoRequest{Id: todo.GetId(), Title: proto.String("Buy oat milk")})
+	if err != nil {
+		t.Fatalf("UpdateTodo failed: %v", err)
+	}
+	if updated.GetTodo().GetTitle() != "Buy oat milk" || updated.GetTodo().GetDescription() != "2 litres" {
+		t.Errorf("UpdateTodo() = %v, want only the title changed", updated.GetTodo())
+	}
+
+	toggled, err := client.ToggleTodo(ctx, &todov1.ToggleTodoRequest{Id: todo.GetId()})
+	if err != nil {
+		t.Fatalf("ToggleTodo failed: %v", err)
+	}
+	if !toggled.GetTodo().GetCompleted() {
+		t.Errorf("ToggleTodo() = %v, want it completed", toggled.GetTodo())
+	}
+
+	for _, title := range []string{"Call mom", "Pay rent"} {
+		if _, err := client.CreateTodo(ctx, &todov1.CreateTodoRequest{Title: title}); err != nil {
+			t.Fatalf("CreateTodo failed: %v", err)
+		}
+	}
+
+	// A batch size of one makes the server page through every todo.
+	if titles := listTodos(t, client, &todov1.ListTodosRequest{BatchSize: 1}); strings.Join(titles, ",") != "Buy oat milk,Call mom,Pay rent" {
+		t.Errorf("ListTodos() = %v, want every todo in ID order", titles)
+	}
+	if titles := listTodos(t, client, &todov1.ListTodosRequest{Completed: proto.Bool(false), Search: "M"}); strings.Join(titles, ",") != "Call mom" {
+		t.Errorf("ListTodos(open, M) = %v, want Call mom", titles)
+	}
+
+	if _, err := client.DeleteTodo(ctx, &todov1.DeleteTodoRequest{Id: todo.GetId()}); err != nil {
+		t.Fatalf("DeleteTodo failed: %v", err)
+	}
+	if _, err := client.GetTodo(ctx, &todov1.GetTodoRequest{Id: todo.GetId()}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetTodo after delete error = %v, want NotFound", err)
+	}
+}
+
+func TestStatusCodes(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		call      func() error
+		wantCode  codes.Code
+		wantField string
+	}{
+		{
+			name: "get missing",
+			call: func() error {
+				_, err := client.GetTodo(ctx, &todov1.GetTodoRequest{Id: 42})
+				return err
+			},
+			wantCode: codes.NotFound,
+		},
+		{
+			name: "update missing",
+			call: func() error {
+				_, err := client.UpdateTodo(ctx, &todov1.UpdateTodoRequest{Id: 42, Title: proto.String("Buy milk")})
+				return err
+			},
+			wantCode: codes.NotFound,
+		},
+		{
+			name: "delete missing",
+			call: func() error {
+				_, err := client.DeleteTodo(ctx, &todov1.DeleteTodoRequest{Id: 42})
+				return err
+			},
+			wantCode: codes.NotFound,
+		},
+		{
+			name: "toggle missing",
+			call: func() error {
+				_, err := client.ToggleTodo(ctx, &todov1.ToggleTodoRequest{Id: 42})
+				return err
+			},
+			wantCode: codes.NotFound,
+		},
+		{
+			name: "create without title",
+			call: func() error {
+				_, err := client.CreateTodo(ctx, &todov1.CreateTodoRequest{})
+				return err
+			},
+			wantCode:  codes.InvalidArgument,
+			wantField: "title",
+		},
+		{
+			name: "update with long description",
+			call: func() error {
+				_, err := client.UpdateTodo(ctx, &todov1.UpdateTodoRequest{Id: 42, Description: proto.String(strings.Repeat("x", 1001))})
+				return err
+			},
+			wantCode:  codes.InvalidArgument,
+			wantField: "description",
+		},
+		{
+			name: "list with large batch",
+			call: func() error {
+				stream, err := client.ListTodos(ctx, &todov1.ListTodosRequest{BatchSize: 1000})
+				if err != nil {
+					return err
+				}
+				_, err = stream.Recv()
+				return err
+			},
+			wantCode:  codes.InvalidArgument,
+			wantField: "limit",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := status.Convert(tt.call())
+			if st.Code() != tt.wantCode {
+				t.Fatalf("code = %s, want %s: %v", st.Code(), tt.wantCode, st.Message())
+			}
+			if tt.wantField == "" {
+				return
+			}
+
+			var fields []string
+			for _, detail := range st.Details() {
+				if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+					for _, violation := range badRequest.GetFieldViolations() {
+						fields = append(fields, violation.GetField())
+					}
+				}
+			}
+			if len(fields) != 1 || fields[0] != tt.wantField {
+				t.Errorf("field violations = %v, want %s", fields, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	client := newTestClient(t)
+
+	var logs bytes.Buffer
+	if err := logger.InitWithOutput("info", &logs); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { logger.InitWithOutput("error", io.Discard) })
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "client-request-1")
+
+	var header metadata.MD
+	if _, err := client.GetTodo(ctx, &todov1.GetTodoRequest{Id: 42}, grpcgo.Header(&header)); status.Code(err) != codes.NotFound {
+		t.Fatalf("GetTodo error = %v, want NotFound", err)
+	}
+	if got := header.Get("x-request-id"); len(got) != 1 || got[0] != "client-request-1" {
+		t.Errorf("x-request-id header = %v, want the caller's ID", got)
+	}
+	if !strings.Contains(logs.String(), `"request_id": "client-request-1"`) {
+		t.Errorf("logs do not carry the request ID:\n%s", logs.String())
+	}
+
+	// Streams get a generated ID, as does a caller sending an invalid one.
+	logs.Reset()
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "bad id")
+	stream, err := client.ListTodos(ctx, &todov1.ListTodosRequest{})
+	if err != nil {
+		t.Fatalf("ListTodos failed: %v", err)
+	}
+	header, err = stream.Header()
+	if err != nil {
+		t.Fatalf("Header failed: %v", err)
+	}
+	if _, err := stream.Recv(); !errors.Is(err, io.EOF) {
+		t.Fatalf("Recv error = %v, want EOF", err)
+	}
+
+	got := header.Get("x-request-id")
+	if len(got) != 1 || got[0] == "" || strings.Contains(got[0], " ") {
+		t.Fatalf("x-request-id header = %v, want a generated ID", got)
+	}
+	if !strings.Contains(logs.String(), `"request_id": "`+got[0]+`"`) {
+		t.Errorf("logs do not carry the generated request ID %s:\n%s", got[0], logs.String())
+	}
+}
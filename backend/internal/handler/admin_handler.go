@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rod1kutzyy/OnTrack/internal/dto"
+	"github.com/rod1kutzyy/OnTrack/internal/logger"
+	"github.com/rod1kutzyy/OnTrack/internal/repository"
+	"github.com/rod1kutzyy/OnTrack/internal/usecase"
+	"github.com/sirupsen/logrus"
+)
+
+const maxRestoreSize = 100 << 20
+
+type AdminHandler struct {
+	backupUseCase usecase.BackupUseCase
+}
+
+func NewAdminHandler(backupUseCase usecase.BackupUseCase) *AdminHandler {
+	return &AdminHandler{
+		backupUseCase: backupUseCase,
+	}
+}
+
+// @Summary Create a backup
+// @Description Streams a versioned JSON backup of all todos
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Success 200 {file} file "Backup document"
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Router /admin/backup [get]
+func (h *AdminHandler) Backup(c *gin.Context) {
+	fileName := fmt.Sprintf("ontrack-backup-%s.json", time.Now().UTC().Format("20060102-150405"))
+
+	// The backup can be large, so the server write timeout must not cut it short.
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(exportWriteTimeout)); err != nil {
+		logger.FromContext(c.Request.Context()).WithError(err).Debug("Failed to extend write deadline")
+	}
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	c.Status(http.StatusOK)
+
+	if _, err := h.backupUseCase.Backup(c.Request.Context(), c.Writer); err != nil {
+		// Headers are already sent; the backup document is left unterminated so
+		// that restoring it fails instead of silently losing todos.
+		logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to create backup")
+	}
+}
+
+// @Summary Restore a backup
+// @Description Restores a backup created by the backup endpoint in a single transaction. Without remap_ids the database must be empty and todos keep their IDs; with remap_ids the todos are added with new IDs. UIDs are kept, so a backup whose UIDs are already stored is rejected with 409.
+// @Tags admin
+// @Accept json
+// @Accept multipart/form-data
+// @Produce json
+// @Security AdminToken
+// @Param remap_ids query bool false "Assign new IDs so that the backup can be restored into a non-empty database"
+// @Param file formData file false "Backup file (alternatively send it as the request body)"
+// @Success 200 {object} dto.SuccessResponse{data=dto.RestoreResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/restore [post]
+func (h *AdminHandler) Restore(c *gin.Context) {
+	var req dto.RestoreRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logger.FromContext(c.Request.Context()).WithError(err).Warn("Invalid query parameters")
+		response := dto.NewErrorResponse("Bad Request", "Invalid query parameters")
+		respondError(c, http.StatusBadRequest, response)
+		return
+	}
+
+	body, err := openUploadedFile(c, maxRestoreSize)
+	if err != nil {
+		logger.FromContext(c.Request.Context()).WithError(err).Warn("Failed to read backup file")
+		response := dto.NewErrorResponseWithCode("Bad Request", "Backup file is missing or unreadable", "INVALID_FILE")
+		respondError(c, http.StatusBadRequest, response)
+		return
+	}
+	defer body.Close()
+
+	result, err := h.backupUseCase.Restore(c.Request.Context(), body, req.RemapIDs)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrInvalidBackup):
+			response := dto.NewErrorResponseWithCode("Bad Request", err.Error(), "INVALID_BACKUP")
+			respondError(c, http.StatusBadRequest, response)
+		case errors.Is(err, repository.ErrNotEmpty):
+			response := dto.NewErrorResponseWithCode(
+				"Conflict",
+				"The database already contains todos; use remap_ids=true to restore with new IDs",
+				"DATABASE_NOT_EMPTY",
+			)
+			respondError(c, http.StatusConflict, response)
+		case errors.Is(err, repository.ErrUIDConflict):
+			response := dto.NewErrorResponseWithCode("Conflict", err.Error(), "UID_CONFLICT")
+			respondError(c, http.StatusConflict, response)
+		default:
+			response := dto.NewErrorResponse("Internal Server Error", "Failed to restore backup")
+			respondError(c, http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	response := dto.NewSuccessResponse(dto.RestoreResponse{
+		SchemaVersion: result.SchemaVersion,
+		Restored:      result.Restored,
+		IDs:           result.IDs,
+	}, "Backup restored successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Get the log level
+// @Description Returns the log level and the per-package overrides in effect
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Success 200 {object} dto.SuccessResponse{data=dto.LogLevelResponse}
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Router /admin/log-level [get]
+func (h *AdminHandler) GetLogLevel(c *gin.Context) {
+	level, packages := logger.Levels()
+
+	response := dto.NewSuccessResponse(dto.LogLevelResponse{Level: level, Packages: packages}, "")
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Change the log level
+// @Description Changes the log level until the service restarts. The per-package overrides are replaced when packages is given and kept otherwise.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param request body dto.LogLevelRequest true "New log levels"
+// @Success 200 {object} dto.SuccessResponse{data=dto.LogLevelResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Router /admin/log-level [put]
+func (h *AdminHandler) SetLogLevel(c *gin.Context) {
+	var req dto.LogLevelRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.FromContext(c.Request.Context()).WithError(err).Warn("Failed to parse request body")
+		response := dto.NewErrorResponseWithCode("Bad Request", "Invalid request data format", "INVALID_JSON")
+		respondError(c, http.StatusBadRequest, response)
+		return
+	}
+
+	packages := req.Packages
+	if packages == nil {
+		_, packages = logger.Levels()
+	}
+
+	if err := logger.SetLevels(req.Level, packages); err != nil {
+		response := dto.NewErrorResponseWithCode("Bad Request", err.Error(), "INVALID_LOG_LEVEL")
+		respondError(c, http.StatusBadRequest, response)
+		return
+	}
+
+	level, packages := logger.Levels()
+	logger.FromContext(c.Request.Context()).WithFields(logrus.Fields{
+		"log_level":      level,
+		"package_levels": packages,
+	}).Warn("Log level changed")
+
+	response := dto.NewSuccessResponse(dto.LogLevelResponse{Level: level, Packages: packages}, "Log level changed")
+	c.JSON(http.StatusOK, response)
+}
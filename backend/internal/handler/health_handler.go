@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rod1kutzyy/OnTrack/internal/health"
+	"github.com/rod1kutzyy/OnTrack/internal/logger"
+)
+
+type HealthHandler struct {
+	registry *health.Registry
+}
+
+func NewHealthHandler(registry *health.Registry) *HealthHandler {
+	return &HealthHandler{
+		registry: registry,
+	}
+}
+
+// Live answers liveness probes. It checks nothing but that the process
+// serves HTTP, so that a slow database never gets the service restarted.
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status": "ok",
+		"time":   time.Now().UTC(),
+	})
+}
+
+// Ready answers readiness probes with the result of every registered check,
+// and 503 Service Unavailable when one fails or the server is shutting down.
+func (h *HealthHandler) Ready(c *gin.Context) {
+	report := h.registry.Check(c.Request.Context())
+
+	if !report.Ready() {
+		if report.Status == health.StatusNotReady {
+			logger.FromContext(c.Request.Context()).WithField("checks", report.Checks).Warn("Readiness check failed")
+		}
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,14 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rod1kutzyy/OnTrack/internal/dto"
+	"github.com/rod1kutzyy/OnTrack/internal/middleware"
+)
+
+// respondError sends an error response that includes the request ID, so that
+// a reported error can be found in the logs.
+func respondError(c *gin.Context, status int, response *dto.ErrorResponse) {
+	response.RequestID = middleware.GetRequestID(c)
+	c.JSON(status, response)
+}
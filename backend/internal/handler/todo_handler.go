@@ -41,28 +41,28 @@ func (h *TodoHandler) CreateTodo(c *gin.Context) {
 	var req dto.CreateTodoRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		logger.Logger.WithError(err).Warn("Failed to parse request body")
+		logger.FromContext(c.Request.Context()).WithError(err).Warn("Failed to parse request body")
 		response := dto.NewErrorResponseWithCode(
 			"Bad Request",
 			"Invalid request data format",
 			"INVALID_JSON",
 		)
-		c.JSON(http.StatusBadRequest, response)
+		respondError(c, http.StatusBadRequest, response)
 		return
 	}
 
 	if validationErrors := h.validator.ValidateCreateTodo(req); len(validationErrors) > 0 {
-		logger.Logger.WithField("errors", validationErrors).Warn("Validation failed")
+		logger.FromContext(c.Request.Context()).WithField("errors", validationErrors).Warn("Validation failed")
 		response := dto.NewValidationErrorResponse(validationErrors)
-		c.JSON(http.StatusBadRequest, response)
+		respondError(c, http.StatusBadRequest, response)
 		return
 	}
 
 	todo, err := h.todoUseCase.CreateTodo(c.Request.Context(), req)
 	if err != nil {
-		logger.Logger.WithError(err).Error("Failed to create todo")
+		logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to create todo")
 		response := dto.NewErrorResponse("Internal Server Error", "Failed to create todo")
-		c.JSON(http.StatusInternalServerError, response)
+		respondError(c, http.StatusInternalServerError, response)
 		return
 	}
 
@@ -89,7 +89,7 @@ func (h *TodoHandler) GetTodoByID(c *gin.Context) {
 			"Invalid todo ID format",
 			"INVALID_ID",
 		)
-		c.JSON(http.StatusBadRequest, response)
+		respondError(c, http.StatusBadRequest, response)
 		return
 	}
 
@@ -101,13 +101,13 @@ func (h *TodoHandler) GetTodoByID(c *gin.Context) {
 				fmt.Sprintf("Todo with ID %d not found", id),
 				"TODO_NOT_FOUND",
 			)
-			c.JSON(http.StatusNotFound, response)
+			respondError(c, http.StatusNotFound, response)
 			return
 		}
 
-		logger.Logger.WithError(err).Error("Failed to get todo")
+		logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to get todo")
 		response := dto.NewErrorResponse("Internal Server Error", "Failed to retrieve todo")
-		c.JSON(http.StatusInternalServerError, response)
+		respondError(c, http.StatusInternalServerError, response)
 		return
 	}
 
@@ -132,16 +132,16 @@ func (h *TodoHandler) GetAllTodos(c *gin.Context) {
 	var filter dto.TodoFilterRequest
 
 	if err := c.ShouldBindQuery(&filter); err != nil {
-		logger.Logger.WithError(err).Warn("Invalid query parameters")
+		logger.FromContext(c.Request.Context()).WithError(err).Warn("Invalid query parameters")
 		response := dto.NewErrorResponse("Bad Request", "Invalid query parameters")
-		c.JSON(http.StatusBadRequest, response)
+		respondError(c, http.StatusBadRequest, response)
 		return
 	}
 
 	if validationErrors := h.validator.ValidateFilter(filter); len(validationErrors) > 0 {
-		logger.Logger.WithField("errors", validationErrors).Warn("Filter validation failed")
+		logger.FromContext(c.Request.Context()).WithField("errors", validationErrors).Warn("Filter validation failed")
 		response := dto.NewValidationErrorResponse(validationErrors)
-		c.JSON(http.StatusBadRequest, response)
+		respondError(c, http.StatusBadRequest, response)
 		return
 	}
 
@@ -156,9 +156,9 @@ func (h *TodoHandler) GetAllTodos(c *gin.Context) {
 
 	todos, total, err := h.todoUseCase.GetAllTodos(c.Request.Context(), domainFilter)
 	if err != nil {
-		logger.Logger.WithError(err).Error("Failed to get todos")
+		logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to get todos")
 		response := dto.NewErrorResponse("Internal Server Error", "Failed to retrieve todos")
-		c.JSON(http.StatusInternalServerError, response)
+		respondError(c, http.StatusInternalServerError, response)
 		return
 	}
 
@@ -196,26 +196,26 @@ func (h *TodoHandler) UpdateTodo(c *gin.Context) {
 			"Invalid todo ID",
 			"INVALID_ID",
 		)
-		c.JSON(http.StatusBadRequest, response)
+		respondError(c, http.StatusBadRequest, response)
 		return
 	}
 
 	var req dto.UpdateTodoRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		logger.Logger.WithError(err).Warn("Failed to parse request body")
+		logger.FromContext(c.Request.Context()).WithError(err).Warn("Failed to parse request body")
 		response := dto.NewErrorResponseWithCode(
 			"Bad Request",
 			"Invalid request data",
 			"INVALID_JSON",
 		)
-		c.JSON(http.StatusBadRequest, response)
+		respondError(c, http.StatusBadRequest, response)
 		return
 	}
 
 	if validationErrors := h.validator.ValidateUpdateTodo(req); len(validationErrors) > 0 {
-		logger.Logger.WithField("errors", validationErrors).Warn("Update validation failed")
+		logger.FromContext(c.Request.Context()).WithField("errors", validationErrors).Warn("Update validation failed")
 		response := dto.NewValidationErrorResponse(validationErrors)
-		c.JSON(http.StatusBadRequest, response)
+		respondError(c, http.StatusBadRequest, response)
 		return
 	}
 
@@ -227,13 +227,13 @@ func (h *TodoHandler) UpdateTodo(c *gin.Context) {
 				fmt.Sprintf("Todo with ID %d not found", id),
 				"TODO_NOT_FOUND",
 			)
-			c.JSON(http.StatusNotFound, response)
+			respondError(c, http.StatusNotFound, response)
 			return
 		}
 
-		logger.Logger.WithError(err).Error("Failed to update todo")
+		logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to update todo")
 		response := dto.NewErrorResponse("Internal Server Error", "Failed to update todo")
-		c.JSON(http.StatusInternalServerError, response)
+		respondError(c, http.StatusInternalServerError, response)
 		return
 	}
 
@@ -260,7 +260,7 @@ func (h *TodoHandler) DeleteTodo(c *gin.Context) {
 			"Invalid todo ID",
 			"INVALID_ID",
 		)
-		c.JSON(http.StatusBadRequest, response)
+		respondError(c, http.StatusBadRequest, response)
 		return
 	}
 
@@ -271,13 +271,13 @@ func (h *TodoHandler) DeleteTodo(c *gin.Context) {
 				fmt.Sprintf("Todo with ID %d not found", id),
 				"TODO_NOT_FOUND",
 			)
-			c.JSON(http.StatusNotFound, response)
+			respondError(c, http.StatusNotFound, response)
 			return
 		}
 
-		logger.Logger.WithError(err).Error("Failed to delete todo")
+		logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to delete todo")
 		response := dto.NewErrorResponse("Internal Server Error", "Failed to delete todo")
-		c.JSON(http.StatusInternalServerError, response)
+		respondError(c, http.StatusInternalServerError, response)
 		return
 	}
 
@@ -302,7 +302,7 @@ func (h *TodoHandler) ToggleTodoComplete(c *gin.Context) {
 			"Invalid todo ID",
 			"INVALID_ID",
 		)
-		c.JSON(http.StatusBadRequest, response)
+		respondError(c, http.StatusBadRequest, response)
 		return
 	}
 
@@ -314,13 +314,13 @@ func (h *TodoHandler) ToggleTodoComplete(c *gin.Context) {
 				fmt.Sprintf("Todo with ID %d not found", id),
 				"TODO_NOT_FOUND",
 			)
-			c.JSON(http.StatusNotFound, response)
+			respondError(c, http.StatusNotFound, response)
 			return
 		}
 
-		logger.Logger.WithError(err).Error("Failed to toggle todo completion")
+		logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to toggle todo completion")
 		response := dto.NewErrorResponse("Internal Server Error", "Failed to toggle completion status")
-		c.JSON(http.StatusInternalServerError, response)
+		respondError(c, http.StatusInternalServerError, response)
 		return
 	}
 
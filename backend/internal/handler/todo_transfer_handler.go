@@ -0,0 +1,527 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rod1kutzyy/OnTrack/internal/domain"
+	"github.com/rod1kutzyy/OnTrack/internal/dto"
+	"github.com/rod1kutzyy/OnTrack/internal/format/ical"
+	"github.com/rod1kutzyy/OnTrack/internal/format/markdown"
+	"github.com/rod1kutzyy/OnTrack/internal/format/todocsv"
+	"github.com/rod1kutzyy/OnTrack/internal/format/todotxt"
+	"github.com/rod1kutzyy/OnTrack/internal/logger"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	formatCSV     = "csv"
+	formatICS     = "ics"
+	formatTodoTxt = "todotxt"
+	formatMD      = "markdown"
+
+	calendarName = "OnTrack"
+
+	maxImportSize      = 10 << 20
+	exportWriteTimeout = 5 * time.Minute
+	exportFlushEvery   = 100
+)
+
+type todoEncoder interface {
+	Write(todo *domain.Todo) error
+	Flush() error
+	Close() error
+}
+
+type exportFormat struct {
+	contentType string
+	fileName    string
+	newEncoder  func(w io.Writer) (todoEncoder, error)
+}
+
+type importFunc func(h *TodoHandler, c *gin.Context, body io.Reader, dryRun bool, summary *dto.ImportSummaryResponse) error
+
+var exportFormats = map[string]exportFormat{
+	formatCSV: {
+		contentType: "text/csv; charset=utf-8",
+		fileName:    "todos.csv",
+		newEncoder: func(w io.Writer) (todoEncoder, error) {
+			return todocsv.NewWriter(w)
+		},
+	},
+	formatICS: {
+		contentType: "text/calendar; charset=utf-8",
+		fileName:    "todos.ics",
+		newEncoder: func(w io.Writer) (todoEncoder, error) {
+			return ical.NewEncoder(w, calendarName)
+		},
+	},
+	formatTodoTxt: {
+		contentType: "text/plain; charset=utf-8",
+		fileName:    "todo.txt",
+		newEncoder: func(w io.Writer) (todoEncoder, error) {
+			return todotxt.NewEncoder(w), nil
+		},
+	},
+	formatMD: {
+		contentType: "text/markdown; charset=utf-8",
+		fileName:    "todos.md",
+		newEncoder: func(w io.Writer) (todoEncoder, error) {
+			return markdown.NewEncoder(w), nil
+		},
+	},
+}
+
+var importFormats = map[string]importFunc{
+	formatCSV:     (*TodoHandler).importCSV,
+	formatICS:     (*TodoHandler).importICS,
+	formatTodoTxt: (*TodoHandler).importTodoTxt,
+	formatMD:      (*TodoHandler).importMarkdown,
+}
+
+// @Summary Export Todos
+// @Description Streams all todos matching the filters in the requested format
+// @Tags todos
+// @Produce text/csv
+// @Produce text/calendar
+// @Param format query string false "Export format" Enums(csv, ics, todotxt, markdown) default(csv)
+// @Param completed query bool false "Filter by completion status"
+// @Param search query string false "Search keyword"
+// @Success 200 {string} string
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /todos/export [get]
+func (h *TodoHandler) ExportTodos(c *gin.Context) {
+	h.exportTodos(c, "attachment")
+}
+
+// @Summary Calendar feed
+// @Description Returns todos matching the filters as an iCalendar feed of VTODO components for calendar subscriptions
+// @Tags todos
+// @Produce text/calendar
+// @Param completed query bool false "Filter by completion status"
+// @Param search query string false "Search keyword"
+// @Success 200 {string} string
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /todos/calendar.ics [get]
+func (h *TodoHandler) GetCalendarFeed(c *gin.Context) {
+	c.Request.URL.RawQuery = setQueryValue(c, "format", formatICS)
+	h.exportTodos(c, "inline")
+}
+
+func (h *TodoHandler) exportTodos(c *gin.Context, disposition string) {
+	var req dto.TodoExportRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logger.FromContext(c.Request.Context()).WithError(err).Warn("Invalid query parameters")
+		response := dto.NewErrorResponse("Bad Request", "Invalid query parameters")
+		respondError(c, http.StatusBadRequest, response)
+		return
+	}
+
+	filterRequest := dto.TodoFilterRequest{
+		Completed: req.Completed,
+		Search:    req.Search,
+		Page:      1,
+		Limit:     100,
+	}
+	if validationErrors := h.validator.ValidateFilter(filterRequest); len(validationErrors) > 0 {
+		logger.FromContext(c.Request.Context()).WithField("errors", validationErrors).Warn("Filter validation failed")
+		response := dto.NewValidationErrorResponse(validationErrors)
+		respondError(c, http.StatusBadRequest, response)
+		return
+	}
+
+	format := strings.ToLower(req.Format)
+	if format == "" {
+		format = formatCSV
+	}
+
+	exporter, ok := exportFormats[format]
+	if !ok {
+		response := dto.NewErrorResponseWithCode(
+			"Bad Request",
+			fmt.Sprintf("Unsupported export format %q", req.Format),
+			"UNSUPPORTED_FORMAT",
+		)
+		respondError(c, http.StatusBadRequest, response)
+		return
+	}
+
+	filter := domain.TodoFilter{
+		Completed: req.Completed,
+		Search:    req.Search,
+	}
+
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(exportWriteTimeout)); err != nil {
+		logger.FromContext(c.Request.Context()).WithError(err).Debug("Failed to extend write deadline for export")
+	}
+
+	c.Header("Content-Type", exporter.contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`%s; filename="%s"`, disposition, exporter.fileName))
+	c.Status(http.StatusOK)
+
+	encoder, err := exporter.newEncoder(c.Writer)
+	if err != nil {
+		logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to start todo export")
+		return
+	}
+
+	written := 0
+	err = h.todoUseCase.IterateTodos(c.Request.Context(), filter, func(todo *domain.Todo) error {
+		if err := encoder.Write(todo); err != nil {
+			return err
+		}
+
+		written++
+		if written%exportFlushEvery == 0 {
+			if err := encoder.Flush(); err != nil {
+				return err
+			}
+			c.Writer.Flush()
+		}
+
+		return nil
+	})
+	if err == nil {
+		err = encoder.Close()
+	}
+
+	if err != nil {
+		// Headers are already sent, so the only thing left is to cut the stream short.
+		logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to export todos")
+		return
+	}
+
+	logger.FromContext(c.Request.Context()).WithFields(logrus.Fields{
+		"count":  written,
+		"format": format,
+	}).Info("Todos exported successfully")
+}
+
+// @Summary Import Todos
+// @Description Imports todos from an uploaded file. Each row is validated separately and the response reports created, updated, skipped and failed rows. iCalendar imports update existing todos with a matching UID.
+// @Tags todos
+// @Accept text/csv
+// @Accept text/calendar
+// @Accept multipart/form-data
+// @Produce json
+// @Param format query string false "Import format" Enums(csv, ics, todotxt, markdown) default(csv)
+// @Param dry_run query bool false "Validate without creating todos"
+// @Param mapping[title] query string false "CSV header holding the title"
+// @Param mapping[description] query string false "CSV header holding the description"
+// @Param mapping[completed] query string false "CSV header holding the completion status"
+// @Param mapping[created_at] query string false "CSV header holding the creation date"
+// @Param file formData file false "File to import (alternatively send it as the request body)"
+// @Success 200 {object} dto.SuccessResponse{data=dto.ImportSummaryResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /todos/import [post]
+func (h *TodoHandler) ImportTodos(c *gin.Context) {
+	var req dto.TodoImportRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logger.FromContext(c.Request.Context()).WithError(err).Warn("Invalid query parameters")
+		response := dto.NewErrorResponse("Bad Request", "Invalid query parameters")
+		respondError(c, http.StatusBadRequest, response)
+		return
+	}
+
+	format := strings.ToLower(req.Format)
+	if format == "" {
+		format = formatCSV
+	}
+
+	importer, ok := importFormats[format]
+	if !ok {
+		response := dto.NewErrorResponseWithCode(
+			"Bad Request",
+			fmt.Sprintf("Unsupported import format %q", req.Format),
+			"UNSUPPORTED_FORMAT",
+		)
+		respondError(c, http.StatusBadRequest, response)
+		return
+	}
+
+	body, err := openUploadedFile(c, maxImportSize)
+	if err != nil {
+		logger.FromContext(c.Request.Context()).WithError(err).Warn("Failed to read import file")
+		response := dto.NewErrorResponseWithCode("Bad Request", "Import file is missing or unreadable", "INVALID_FILE")
+		respondError(c, http.StatusBadRequest, response)
+		return
+	}
+	defer body.Close()
+
+	summary := &dto.ImportSummaryResponse{
+		DryRun: req.DryRun,
+		Rows:   []dto.ImportRowResult{},
+	}
+
+	if err := importer(h, c, body, req.DryRun, summary); err != nil {
+		logger.FromContext(c.Request.Context()).WithError(err).Warn("Failed to import todos")
+		response := dto.NewErrorResponseWithCode("Bad Request", err.Error(), "INVALID_FILE")
+		respondError(c, http.StatusBadRequest, response)
+		return
+	}
+
+	logger.FromContext(c.Request.Context()).WithFields(logrus.Fields{
+		"format":  format,
+		"created": summary.Created,
+		"updated": summary.Updated,
+		"skipped": summary.Skipped,
+		"failed":  summary.Failed,
+		"dry_run": summary.DryRun,
+	}).Info("Todo import finished")
+
+	response := dto.NewSuccessResponse(summary, "Import completed")
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *TodoHandler) importCSV(c *gin.Context, body io.Reader, dryRun bool, summary *dto.ImportSummaryResponse) error {
+	reader, err := todocsv.NewReader(body, c.QueryMap("mapping"))
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request.Context()
+
+	for {
+		record, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			if record == nil {
+				return fmt.Errorf("failed to read csv: %w", err)
+			}
+
+			summary.Add(formatErrorResult(record.Line, err))
+			continue
+		}
+
+		if record.Empty {
+			summary.Add(dto.ImportRowResult{Row: record.Line, Status: dto.ImportStatusSkipped})
+			continue
+		}
+
+		todo := domain.Todo{
+			Title:       record.Title,
+			Description: record.Description,
+			Completed:   record.Completed,
+		}
+		if record.CreatedAt != nil {
+			todo.CreatedAt = *record.CreatedAt
+		}
+
+		summary.Add(h.importTodo(ctx, record.Line, todo, dryRun))
+	}
+}
+
+func (h *TodoHandler) importICS(c *gin.Context, body io.Reader, dryRun bool, summary *dto.ImportSummaryResponse) error {
+	entries, err := ical.Decode(body)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request.Context()
+
+	for _, entry := range entries {
+		todo := icalEntryToTodo(entry)
+		if entry.UID == "" {
+			summary.Add(h.importTodo(ctx, entry.Line, todo, dryRun))
+			continue
+		}
+		todo.UID = &entry.UID
+
+		// The lookup only picks the validation rules and the dry-run
+		// status; ImportTodo looks the UID up again when storing the todo.
+		existing, err := h.todoUseCase.GetTodoByUID(ctx, entry.UID)
+		if err != nil {
+			if !strings.Contains(err.Error(), "not found") {
+				logger.FromContext(ctx).WithError(err).WithField("uid", entry.UID).Error("Failed to look up imported todo")
+				summary.Add(internalErrorResult(entry.Line))
+				continue
+			}
+
+			summary.Add(h.importTodo(ctx, entry.Line, todo, dryRun))
+			continue
+		}
+
+		summary.Add(h.updateImportedTodo(ctx, entry.Line, existing.ID, todo, dryRun))
+	}
+
+	return nil
+}
+
+func (h *TodoHandler) importTodoTxt(c *gin.Context, body io.Reader, dryRun bool, summary *dto.ImportSummaryResponse) error {
+	lines, err := todotxt.Decode(body)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request.Context()
+
+	for _, line := range lines {
+		if line.Err != nil {
+			summary.Add(formatErrorResult(line.Number, line.Err))
+			continue
+		}
+
+		summary.Add(h.importTodo(ctx, line.Number, line.Task.ToTodo(), dryRun))
+	}
+
+	return nil
+}
+
+// importMarkdown imports the task list items of a Markdown document. The
+// headings and list items an item is nested in become its description,
+// followed by the paragraphs indented under the item.
+func (h *TodoHandler) importMarkdown(c *gin.Context, body io.Reader, dryRun bool, summary *dto.ImportSummaryResponse) error {
+	items, err := markdown.Decode(body)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request.Context()
+
+	for _, item := range items {
+		todo := domain.Todo{
+			Title:     item.Title,
+			Completed: item.Completed,
+		}
+		description := item.Group()
+		if item.Description != "" {
+			if description != "" {
+				description += "\n\n"
+			}
+			description += item.Description
+		}
+		if description != "" {
+			todo.Description = &description
+		}
+
+		summary.Add(h.importTodo(ctx, item.Line, todo, dryRun))
+	}
+
+	return nil
+}
+
+// importTodo validates a single imported todo with the same rules as the
+// create endpoint and stores it unless running in dry-run mode.
+func (h *TodoHandler) importTodo(ctx context.Context, row int, todo domain.Todo, dryRun bool) dto.ImportRowResult {
+	createRequest := dto.CreateTodoRequest{
+		Title:       todo.Title,
+		Description: todo.Description,
+	}
+
+	if validationErrors := h.validator.ValidateCreateTodo(createRequest); len(validationErrors) > 0 {
+		return dto.ImportRowResult{Row: row, Status: dto.ImportStatusFailed, Errors: validationErrors}
+	}
+
+	if dryRun {
+		return dto.ImportRowResult{Row: row, Status: dto.ImportStatusCreated}
+	}
+
+	return h.storeImportedTodo(ctx, row, todo)
+}
+
+// updateImportedTodo validates a todo that replaces the todo with the given
+// ID with the same rules as the update endpoint.
+func (h *TodoHandler) updateImportedTodo(ctx context.Context, row int, id uint, todo domain.Todo, dryRun bool) dto.ImportRowResult {
+	description := ""
+	if todo.Description != nil {
+		description = *todo.Description
+	}
+
+	updateRequest := dto.UpdateTodoRequest{
+		Title:       &todo.Title,
+		Description: &description,
+		Completed:   &todo.Completed,
+	}
+
+	if validationErrors := h.validator.ValidateUpdateTodo(updateRequest); len(validationErrors) > 0 {
+		return dto.ImportRowResult{Row: row, Status: dto.ImportStatusFailed, TodoID: id, Errors: validationErrors}
+	}
+
+	if dryRun {
+		return dto.ImportRowResult{Row: row, Status: dto.ImportStatusUpdated, TodoID: id}
+	}
+
+	return h.storeImportedTodo(ctx, row, todo)
+}
+
+func (h *TodoHandler) storeImportedTodo(ctx context.Context, row int, todo domain.Todo) dto.ImportRowResult {
+	imported, created, err := h.todoUseCase.ImportTodo(ctx, todo)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).WithField("row", row).Error("Failed to import todo")
+		return internalErrorResult(row)
+	}
+
+	status := dto.ImportStatusUpdated
+	if created {
+		status = dto.ImportStatusCreated
+	}
+
+	return dto.ImportRowResult{Row: row, Status: status, TodoID: imported.ID}
+}
+
+// openUploadedFile returns the "file" part of a multipart request or, for any
+// other content type, the raw request body.
+func openUploadedFile(c *gin.Context, maxSize int64) (io.ReadCloser, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize)
+
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return nil, err
+		}
+
+		return fileHeader.Open()
+	}
+
+	if c.Request.ContentLength == 0 {
+		return nil, errors.New("request body is empty")
+	}
+
+	return c.Request.Body, nil
+}
+
+func icalEntryToTodo(entry ical.Todo) domain.Todo {
+	todo := domain.Todo{
+		Title:       entry.Summary,
+		Description: entry.Description,
+		Completed:   entry.Completed,
+	}
+	if entry.CreatedAt != nil {
+		todo.CreatedAt = *entry.CreatedAt
+	}
+
+	return todo
+}
+
+func formatErrorResult(row int, err error) dto.ImportRowResult {
+	return dto.ImportRowResult{
+		Row:    row,
+		Status: dto.ImportStatusFailed,
+		Errors: []dto.ValidationError{{Field: "row", Message: err.Error(), Tag: "format"}},
+	}
+}
+
+func internalErrorResult(row int) dto.ImportRowResult {
+	return dto.ImportRowResult{
+		Row:    row,
+		Status: dto.ImportStatusFailed,
+		Errors: []dto.ValidationError{{Field: "row", Message: "Failed to store todo", Tag: "internal"}},
+	}
+}
+
+func setQueryValue(c *gin.Context, key, value string) string {
+	query := c.Request.URL.Query()
+	query.Set(key, value)
+	return query.Encode()
+}
@@ -0,0 +1,145 @@
+// Package health runs the readiness checks that components of the service
+// register, e.g. the database connection.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Check reports whether a component can serve requests. It should return
+// once ctx is done.
+type Check func(ctx context.Context) error
+
+const (
+	StatusReady        = "ready"
+	StatusNotReady     = "not_ready"
+	StatusShuttingDown = "shutting_down"
+
+	CheckOK      = "ok"
+	CheckFailing = "failing"
+)
+
+type CheckResult struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status string        `json:"status"`
+	Checks []CheckResult `json:"checks"`
+	Time   time.Time     `json:"time"`
+}
+
+// Ready reports whether the service should receive traffic.
+func (r Report) Ready() bool {
+	return r.Status == StatusReady
+}
+
+type check struct {
+	name    string
+	timeout time.Duration
+	fn      Check
+}
+
+// Registry holds the readiness checks. It is safe for concurrent use.
+type Registry struct {
+	mu           sync.RWMutex
+	checks       []check
+	shuttingDown atomic.Bool
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a check that fails when fn returns an error or takes longer
+// than timeout.
+func (r *Registry) Register(name string, timeout time.Duration, fn Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checks = append(r.checks, check{name: name, timeout: timeout, fn: fn})
+}
+
+// SetShuttingDown makes the service unready for good, so that load balancers
+// stop sending requests while the servers drain.
+func (r *Registry) SetShuttingDown() {
+	r.shuttingDown.Store(true)
+}
+
+// Check runs all checks concurrently. The service is ready when every check
+// passes and it is not shutting down; a shutting down service skips the
+// checks.
+func (r *Registry) Check(ctx context.Context) Report {
+	report := Report{Status: StatusReady, Checks: []CheckResult{}, Time: time.Now().UTC()}
+
+	if r.shuttingDown.Load() {
+		report.Status = StatusShuttingDown
+		return report
+	}
+
+	r.mu.RLock()
+	checks := slices.Clone(r.checks)
+	r.mu.RUnlock()
+
+	report.Checks = make([]CheckResult, len(checks))
+
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Go(func() {
+			report.Checks[i] = c.run(ctx)
+		})
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != CheckOK {
+			report.Status = StatusNotReady
+		}
+	}
+
+	return report
+}
+
+// run waits at most for the timeout, even when the check ignores its
+// context; a late result is discarded.
+func (c check) run(ctx context.Context) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- c.fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = fmt.Errorf("timed out after %s", c.timeout)
+		}
+	}
+
+	result := CheckResult{
+		Name:      c.name,
+		Status:    CheckOK,
+		LatencyMS: float64(time.Since(start)) / float64(time.Millisecond),
+	}
+	if err != nil {
+		result.Status = CheckFailing
+		result.Error = err.Error()
+	}
+
+	return result
+}
@@ -0,0 +1,110 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rod1kutzyy/OnTrack/internal/health"
+)
+
+func passing(context.Context) error {
+	return nil
+}
+
+func failing(context.Context) error {
+	return errors.New("connection refused")
+}
+
+// stuck ignores its context, like a driver call without a deadline.
+func stuck(context.Context) error {
+	time.Sleep(time.Second)
+	return nil
+}
+
+func TestCheck(t *testing.T) {
+	type check struct {
+		name string
+		fn   health.Check
+	}
+
+	tests := []struct {
+		name         string
+		checks       []check
+		shuttingDown bool
+		wantStatus   string
+		wantChecks   map[string]string
+		wantErrors   map[string]string
+	}{
+		{
+			name:       "no checks",
+			wantStatus: health.StatusReady,
+			wantChecks: map[string]string{},
+		},
+		{
+			name:       "all passing",
+			checks:     []check{{"database", passing}, {"migrations", passing}},
+			wantStatus: health.StatusReady,
+			wantChecks: map[string]string{"database": health.CheckOK, "migrations": health.CheckOK},
+		},
+		{
+			name:       "one failing",
+			checks:     []check{{"database", failing}, {"migrations", passing}},
+			wantStatus: health.StatusNotReady,
+			wantChecks: map[string]string{"database": health.CheckFailing, "migrations": health.CheckOK},
+			wantErrors: map[string]string{"database": "connection refused"},
+		},
+		{
+			name:       "timeout",
+			checks:     []check{{"database", stuck}},
+			wantStatus: health.StatusNotReady,
+			wantChecks: map[string]string{"database": health.CheckFailing},
+			wantErrors: map[string]string{"database": "timed out after 50ms"},
+		},
+		{
+			name:         "shutting down",
+			checks:       []check{{"database", passing}},
+			shuttingDown: true,
+			wantStatus:   health.StatusShuttingDown,
+			wantChecks:   map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := health.NewRegistry()
+			for _, c := range tt.checks {
+				registry.Register(c.name, 50*time.Millisecond, c.fn)
+			}
+			if tt.shuttingDown {
+				registry.SetShuttingDown()
+			}
+
+			start := time.Now()
+			report := registry.Check(context.Background())
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Errorf("Check took %s, longer than the check timeouts", elapsed)
+			}
+
+			if report.Status != tt.wantStatus || report.Ready() != (tt.wantStatus == health.StatusReady) {
+				t.Errorf("Status = %q, Ready() = %t, want %q", report.Status, report.Ready(), tt.wantStatus)
+			}
+
+			if len(report.Checks) != len(tt.wantChecks) {
+				t.Fatalf("got %d check results, want %d", len(report.Checks), len(tt.wantChecks))
+			}
+			for _, result := range report.Checks {
+				if result.Status != tt.wantChecks[result.Name] {
+					t.Errorf("check %s has status %q, want %q", result.Name, result.Status, tt.wantChecks[result.Name])
+				}
+				if result.Error != tt.wantErrors[result.Name] {
+					t.Errorf("check %s has error %q, want %q", result.Name, result.Error, tt.wantErrors[result.Name])
+				}
+				if result.LatencyMS <= 0 {
+					t.Errorf("check %s has latency %v", result.Name, result.LatencyMS)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rod1kutzyy/OnTrack/internal/config"
+	"github.com/rod1kutzyy/OnTrack/internal/infrastructure/database/migrate"
+	appLogger "github.com/rod1kutzyy/OnTrack/internal/logger"
+	"gorm.io/gorm"
+)
+
+// DB is a connection to the database selected by DB_DRIVER.
+type DB struct {
+	DB      *gorm.DB
+	Dialect migrate.Dialect
+}
+
+// New connects to the configured database. The memory driver has no
+// database and must not be passed.
+func New(cfg *config.Config) (*DB, error) {
+	gormConfig := &gorm.Config{
+		Logger: newGormLogger(),
+		NowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+		PrepareStmt: true,
+	}
+
+	switch cfg.Database.Driver {
+	case config.DriverPostgres:
+		return openPostgres(cfg, gormConfig)
+	case config.DriverSQLite:
+		return openSQLite(cfg, gormConfig)
+	default:
+		return nil, fmt.Errorf("database driver %q has no database", cfg.Database.Driver)
+	}
+}
+
+func (d *DB) Close() error {
+	sqlDB, err := d.DB.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database instance: %w", err)
+	}
+
+	appLogger.Logger.Info("Closing database connection...")
+
+	if err := sqlDB.Close(); err != nil {
+		return fmt.Errorf("failed to close database connection: %w", err)
+	}
+
+	appLogger.Logger.Info("Database connection closed successfully")
+	return nil
+}
+
+// Ping checks that the database is reachable.
+func (d *DB) Ping(ctx context.Context) error {
+	sqlDB, err := d.DB.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database instance: %w", err)
+	}
+
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return nil
+}
+
+// CheckMigrations fails while migrations are pending, e.g. when the server
+// was started with -skip-migrations before the schema was migrated.
+func (d *DB) CheckMigrations(ctx context.Context) error {
+	migrator, err := d.Migrator()
+	if err != nil {
+		return err
+	}
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get migration status: %w", err)
+	}
+
+	pending := 0
+	for _, status := range statuses {
+		if !status.Applied {
+			pending++
+		}
+	}
+	if pending > 0 {
+		return fmt.Errorf("%d migrations pending", pending)
+	}
+
+	return nil
+}
+
+func (d *DB) GetDB() *gorm.DB {
+	return d.DB
+}
+
+func (d *DB) Migrator() (*migrate.Migrator, error) {
+	sqlDB, err := d.DB.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database instance: %w", err)
+	}
+
+	return migrate.New(sqlDB, d.Dialect)
+}
+
+// Migrate applies all pending schema migrations.
+func (d *DB) Migrate(ctx context.Context) error {
+	appLogger.Logger.Info("Running database migrations...")
+
+	migrator, err := d.Migrator()
+	if err != nil {
+		return err
+	}
+
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	appLogger.Logger.WithField("applied", len(applied)).Info("Database migrations completed successfully")
+	return nil
+}
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	appLogger "github.com/rod1kutzyy/OnTrack/internal/logger"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm/logger"
+)
+
+const slowQueryThreshold = time.Second
+
+// gormLogger writes GORM's messages through the application logger, so they
+// honour its output, format, levels and hooks. Statements are logged at
+// debug level, slow statements at warn and failed statements at error.
+// Statements are logged with placeholders instead of their bound values,
+// which hold todo content that redaction cannot recognise.
+type gormLogger struct {
+	level logger.LogLevel
+}
+
+func newGormLogger() logger.Interface {
+	return &gormLogger{level: logger.Info}
+}
+
+func (l *gormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return &gormLogger{level: level}
+}
+
+func (l *gormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Info {
+		appLogger.FromContext(ctx).Infof(msg, data...)
+	}
+}
+
+func (l *gormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Warn {
+		appLogger.FromContext(ctx).Warnf(msg, data...)
+	}
+}
+
+func (l *gormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Error {
+		appLogger.FromContext(ctx).Errorf(msg, data...)
+	}
+}
+
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	entry := appLogger.FromContext(ctx)
+
+	// fc renders the statement, so it is only called when the entry is
+	// logged. Per-package levels may still drop it later.
+	switch {
+	case err != nil && !errors.Is(err, logger.ErrRecordNotFound) && l.level >= logger.Error && entry.Logger.IsLevelEnabled(logrus.ErrorLevel):
+		queryEntry(entry, elapsed, fc).WithError(err).Error("Database query failed")
+	case elapsed > slowQueryThreshold && l.level >= logger.Warn && entry.Logger.IsLevelEnabled(logrus.WarnLevel):
+		queryEntry(entry, elapsed, fc).Warn(fmt.Sprintf("Slow database query (over %s)", slowQueryThreshold))
+	case l.level >= logger.Info && entry.Logger.IsLevelEnabled(logrus.DebugLevel):
+		queryEntry(entry, elapsed, fc).Debug("Database query")
+	}
+}
+
+// ParamsFilter drops the bound values before GORM renders a statement for
+// the log.
+func (l *gormLogger) ParamsFilter(_ context.Context, sql string, _ ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
+
+func queryEntry(entry *logrus.Entry, elapsed time.Duration, fc func() (string, int64)) *logrus.Entry {
+	sql, rows := fc()
+
+	fields := logrus.Fields{
+		"sql":     sql,
+		"latency": elapsed.Milliseconds(),
+	}
+	// GORM reports -1 rows for statements that do not count them.
+	if rows >= 0 {
+		fields["rows"] = rows
+	}
+
+	return entry.WithFields(fields)
+}
@@ -0,0 +1,81 @@
+package database_test
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rod1kutzyy/OnTrack/internal/config"
+	"github.com/rod1kutzyy/OnTrack/internal/infrastructure/database"
+	"github.com/rod1kutzyy/OnTrack/internal/logger"
+)
+
+func openDB(t *testing.T) *database.DB {
+	t.Helper()
+
+	db, err := database.New(&config.Config{Database: config.DatabaseConfig{
+		Driver: config.DriverSQLite,
+		Path:   filepath.Join(t.TempDir(), "ontrack.db"),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func TestGormLogger(t *testing.T) {
+	var buf bytes.Buffer
+	if err := logger.InitWithOutput("debug", &buf); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { logger.InitWithOutput("error", io.Discard) })
+
+	db := openDB(t)
+
+	tests := []struct {
+		name     string
+		level    string
+		packages map[string]string
+		query    string
+		args     []interface{}
+		want     string
+	}{
+		{name: "statement at debug", level: "debug", query: "SELECT 1", want: `"msg": "Database query"`},
+		{name: "statement at info", level: "info", query: "SELECT 1"},
+		{name: "package override", level: "info", packages: map[string]string{"infrastructure/database": "debug"}, query: "SELECT 1", want: `"msg": "Database query"`},
+		{name: "package override silences", level: "debug", packages: map[string]string{"database": "error"}, query: "SELECT 1"},
+		{name: "bound values", level: "debug", query: "SELECT ?", args: []interface{}{"Call the bank, PIN 1234"}, want: `"msg": "Database query"`},
+		{name: "failed statement", level: "error", query: "SELECT * FROM missing", want: `"msg": "Database query failed"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := logger.SetLevels(tt.level, tt.packages); err != nil {
+				t.Fatal(err)
+			}
+			buf.Reset()
+
+			db.DB.Exec(tt.query, tt.args...)
+
+			got := buf.String()
+			if tt.want == "" {
+				if got != "" {
+					t.Errorf("logged %s, want nothing", got)
+				}
+				return
+			}
+			if !strings.Contains(got, tt.want) || !strings.Contains(got, tt.query) {
+				t.Errorf("logged %s, want %s with the statement", got, tt.want)
+			}
+			for _, arg := range tt.args {
+				if strings.Contains(got, arg.(string)) {
+					t.Errorf("logged %s, want the bound value %q left out", got, arg)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package migrate_test
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/rod1kutzyy/OnTrack/internal/infrastructure/database"
+	"github.com/rod1kutzyy/OnTrack/internal/infrastructure/database/migrate"
+	"github.com/rod1kutzyy/OnTrack/internal/logger"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+func TestMain(m *testing.M) {
+	// The migrator logs through the application logger.
+	if err := logger.InitWithOutput("error", io.Discard); err != nil {
+		panic(err)
+	}
+
+	os.Exit(m.Run())
+}
+
+func openSQLite(t *testing.T) (*sql.DB, *migrate.Migrator) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(database.SQLiteDSN(filepath.Join(t.TempDir(), "ontrack.db"))), &gorm.Config{
+		Logger: gormLogger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	migrator, err := migrate.New(sqlDB, migrate.SQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return sqlDB, migrator
+}
+
+func versions(migrations []migrate.Migration) []int64 {
+	var versions []int64
+	for _, migration := range migrations {
+		versions = append(versions, migration.Version)
+	}
+	return versions
+}
+
+// appliedVersions lists the versions that Status reports as applied.
+func appliedVersions(t *testing.T, migrator *migrate.Migrator) []int64 {
+	t.Helper()
+
+	statuses, err := migrator.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+
+	var applied []int64
+	for _, status := range statuses {
+		if status.Applied != (status.AppliedAt != nil) {
+			t.Errorf("status %+v has applied %t and applied at %v", status.Migration, status.Applied, status.AppliedAt)
+		}
+		if status.Applied {
+			applied = append(applied, status.Version)
+		}
+	}
+	return applied
+}
+
+func hasColumn(t *testing.T, db *sql.DB, table, column string) bool {
+	t.Helper()
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM pragma_table_info($1) WHERE name = $2", table, column).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	return count > 0
+}
+
+func TestUpDownStatus(t *testing.T) {
+	db, migrator := openSQLite(t)
+	ctx := context.Background()
+
+	if applied := appliedVersions(t, migrator); len(applied) != 0 {
+		t.Errorf("applied versions of a new database = %v, want none", applied)
+	}
+
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+	if got := versions(applied); !slices.Equal(got, []int64{1, 2}) {
+		t.Errorf("Up() applied %v, want [1 2]", got)
+	}
+	if !hasColumn(t, db, "todos", "uid") {
+		t.Error("todos has no uid column after Up")
+	}
+
+	// Applied versions are skipped.
+	applied, err = migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("second Up failed: %v", err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("second Up() applied %v, want nothing", versions(applied))
+	}
+	if got := appliedVersions(t, migrator); !slices.Equal(got, []int64{1, 2}) {
+		t.Errorf("applied versions = %v, want [1 2]", got)
+	}
+
+	reverted, err := migrator.Down(ctx, 1)
+	if err != nil {
+		t.Fatalf("Down failed: %v", err)
+	}
+	if got := versions(reverted); !slices.Equal(got, []int64{2}) {
+		t.Errorf("Down(1) reverted %v, want [2]", got)
+	}
+	if hasColumn(t, db, "todos", "uid") || !hasColumn(t, db, "todos", "title") {
+		t.Error("Down(1) did not drop only the uid column")
+	}
+	if got := appliedVersions(t, migrator); !slices.Equal(got, []int64{1}) {
+		t.Errorf("applied versions = %v, want [1]", got)
+	}
+
+	// More steps than applied migrations roll back all of them.
+	reverted, err = migrator.Down(ctx, 10)
+	if err != nil {
+		t.Fatalf("Down failed: %v", err)
+	}
+	if got := versions(reverted); !slices.Equal(got, []int64{1}) {
+		t.Errorf("Down(10) reverted %v, want [1]", got)
+	}
+	if hasColumn(t, db, "todos", "id") {
+		t.Error("todos still exists after rolling back every migration")
+	}
+
+	applied, err = migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up after Down failed: %v", err)
+	}
+	if got := versions(applied); !slices.Equal(got, []int64{1, 2}) {
+		t.Errorf("Up() after Down applied %v, want [1 2]", got)
+	}
+}
+
+func TestUnknownVersion(t *testing.T) {
+	db, migrator := openSQLite(t)
+	ctx := context.Background()
+
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+	// A newer build applied a migration this one does not have.
+	if _, err := db.Exec("INSERT INTO schema_migrations (version, name) VALUES (99, 'from_the_future')"); err != nil {
+		t.Fatal(err)
+	}
+
+	const wantErr = "database has migration 99 applied, which this build does not know"
+
+	if _, err := migrator.Up(ctx); err == nil || !strings.Contains(err.Error(), wantErr) {
+		t.Errorf("Up() error = %v, want %q", err, wantErr)
+	}
+	if _, err := migrator.Down(ctx, 1); err == nil || !strings.Contains(err.Error(), wantErr) {
+		t.Errorf("Down() error = %v, want %q", err, wantErr)
+	}
+	if !hasColumn(t, db, "todos", "uid") {
+		t.Error("Down() rolled back a migration despite the unknown version")
+	}
+
+	// Status still lists the known migrations along with the error.
+	statuses, err := migrator.Status(ctx)
+	if err == nil || !strings.Contains(err.Error(), wantErr) {
+		t.Errorf("Status() error = %v, want %q", err, wantErr)
+	}
+	if len(statuses) != 2 || !statuses[0].Applied || !statuses[1].Applied {
+		t.Errorf("Status() = %+v, want both migrations applied", statuses)
+	}
+}
+
+func TestFailingMigration(t *testing.T) {
+	db, migrator := openSQLite(t)
+	ctx := context.Background()
+
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+	if _, err := migrator.Down(ctx, 1); err != nil {
+		t.Fatalf("Down failed: %v", err)
+	}
+
+	// Migration 2 adds the uid column and then fails to create its index,
+	// whose name is taken.
+	if _, err := db.Exec("CREATE INDEX idx_todos_uid ON todos (title)"); err != nil {
+		t.Fatal(err)
+	}
+
+	applied, err := migrator.Up(ctx)
+	if err == nil || !strings.Contains(err.Error(), "failed to apply migration 2_add_todo_uid") {
+		t.Errorf("Up() error = %v, want migration 2 to fail", err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("Up() applied %v, want nothing", versions(applied))
+	}
+	if got := appliedVersions(t, migrator); !slices.Equal(got, []int64{1}) {
+		t.Errorf("applied versions = %v, want [1]", got)
+	}
+	if hasColumn(t, db, "todos", "uid") {
+		t.Error("the failed migration left the uid column behind")
+	}
+}
+
+func TestMissingVersion(t *testing.T) {
+	db, migrator := openSQLite(t)
+	ctx := context.Background()
+
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+	// Version 1 is no longer recorded, although its table exists.
+	if _, err := db.Exec("DELETE FROM schema_migrations WHERE version = 1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := appliedVersions(t, migrator); !slices.Equal(got, []int64{2}) {
+		t.Errorf("applied versions = %v, want [2]", got)
+	}
+
+	// Up applies the missing version, which fails on the existing table and
+	// leaves the recorded versions as they were.
+	if _, err := migrator.Up(ctx); err == nil || !strings.Contains(err.Error(), "failed to apply migration 1_create_todos") {
+		t.Errorf("Up() error = %v, want migration 1 to fail", err)
+	}
+	if got := appliedVersions(t, migrator); !slices.Equal(got, []int64{2}) {
+		t.Errorf("applied versions = %v, want [2]", got)
+	}
+	if !hasColumn(t, db, "todos", "uid") {
+		t.Error("the failed migration changed the todos table")
+	}
+}
+
+func TestUnknownDialect(t *testing.T) {
+	if _, err := migrate.New(nil, "mysql"); err == nil || err.Error() != `no migrations for database dialect "mysql"` {
+		t.Errorf("New() error = %v, want the dialect rejected", err)
+	}
+}
@@ -0,0 +1,13 @@
+-- Databases created before versioned migrations already have this table from
+-- GORM's AutoMigrate, so every statement must tolerate existing objects.
+CREATE TABLE IF NOT EXISTS todos (
+    id          BIGSERIAL PRIMARY KEY,
+    title       VARCHAR(255) NOT NULL,
+    description TEXT,
+    completed   BOOLEAN DEFAULT FALSE,
+    created_at  TIMESTAMPTZ,
+    updated_at  TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_todos_completed ON todos (completed);
+CREATE INDEX IF NOT EXISTS idx_todos_created_at ON todos (created_at);
@@ -0,0 +1,3 @@
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS uid VARCHAR(255);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_todos_uid ON todos (uid);
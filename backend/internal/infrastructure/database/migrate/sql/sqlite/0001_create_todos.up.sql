@@ -0,0 +1,13 @@
+-- AUTOINCREMENT keeps IDs of deleted todos from being handed out again, as
+-- the PostgreSQL sequence does.
+CREATE TABLE todos (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    title       VARCHAR(255) NOT NULL,
+    description TEXT,
+    completed   BOOLEAN DEFAULT FALSE,
+    created_at  DATETIME,
+    updated_at  DATETIME
+);
+
+CREATE INDEX idx_todos_completed ON todos (completed);
+CREATE INDEX idx_todos_created_at ON todos (created_at);
@@ -0,0 +1,3 @@
+ALTER TABLE todos ADD COLUMN uid VARCHAR(255);
+
+CREATE UNIQUE INDEX idx_todos_uid ON todos (uid);
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/glebarez/sqlite"
+	"github.com/rod1kutzyy/OnTrack/internal/config"
+	"github.com/rod1kutzyy/OnTrack/internal/infrastructure/database/migrate"
+	appLogger "github.com/rod1kutzyy/OnTrack/internal/logger"
+	"gorm.io/gorm"
+)
+
+// sqlitePragmas are applied to every connection: wait for locks instead of
+// failing at once, allow readers during writes, and enforce foreign keys.
+var sqlitePragmas = []string{
+	"busy_timeout(5000)",
+	"journal_mode(WAL)",
+	"foreign_keys(1)",
+}
+
+func openSQLite(cfg *config.Config, gormConfig *gorm.Config) (*DB, error) {
+	appLogger.Logger.WithField("path", cfg.Database.Path).Info("Opening SQLite database...")
+
+	if dir := filepath.Dir(cfg.Database.Path); dir != "." {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return nil, fmt.Errorf("failed to create database directory: %w", err)
+		}
+	}
+
+	db, err := gorm.Open(sqlite.Open(SQLiteDSN(cfg.Database.Path)), gormConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database instance: %w", err)
+	}
+
+	// SQLite allows a single writer. One connection avoids "database is
+	// locked" errors entirely; the workloads it is meant for are small.
+	sqlDB.SetMaxOpenConns(1)
+
+	if err := sqlDB.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	appLogger.Logger.Info("Successfully opened SQLite database")
+	return &DB{DB: db, Dialect: migrate.SQLite}, nil
+}
+
+// SQLiteDSN returns the data source name for the database file at path.
+func SQLiteDSN(path string) string {
+	query := url.Values{"_pragma": sqlitePragmas}
+
+	return "file:" + path + "?" + query.Encode()
+}
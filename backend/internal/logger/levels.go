@@ -0,0 +1,136 @@
+package logger
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// levels is the level in effect and its per-package overrides. It is
+// replaced as a whole, so that logging needs no lock to read it.
+type levels struct {
+	base     logrus.Level
+	packages map[string]logrus.Level
+}
+
+var (
+	current atomic.Pointer[levels]
+
+	// reportCaller is the LOG_CALLER setting. Package overrides need the
+	// caller to find the package, so it is recorded for them regardless.
+	reportCaller atomic.Bool
+)
+
+// Levels returns the level in effect and the per-package overrides.
+func Levels() (string, map[string]string) {
+	l := current.Load()
+	if l == nil {
+		return Logger.GetLevel().String(), map[string]string{}
+	}
+
+	packages := make(map[string]string, len(l.packages))
+	for pkg, level := range l.packages {
+		packages[pkg] = level.String()
+	}
+
+	return l.base.String(), packages
+}
+
+// SetLevels changes the level and replaces the per-package overrides while
+// the service runs. Overrides apply to the packages whose import path ends
+// with the key, the longest matching key winning.
+func SetLevels(level string, packages map[string]string) error {
+	l, err := parseLevels(level, packages)
+	if err != nil {
+		return err
+	}
+
+	applyLevels(l)
+
+	return nil
+}
+
+func parseLevels(level string, packages map[string]string) (*levels, error) {
+	base, err := logrus.ParseLevel(level)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log level %q", level)
+	}
+
+	l := &levels{base: base, packages: make(map[string]logrus.Level, len(packages))}
+	for pkg, pkgLevel := range packages {
+		parsed, err := logrus.ParseLevel(pkgLevel)
+		if err != nil {
+			return nil, fmt.Errorf("invalid log level %q for package %q", pkgLevel, pkg)
+		}
+		l.packages[strings.Trim(pkg, "/")] = parsed
+	}
+
+	return l, nil
+}
+
+func applyLevels(l *levels) {
+	current.Store(l)
+
+	// Logger lets through everything that some package may log; the
+	// formatter drops the rest.
+	Logger.SetLevel(l.verbosest())
+	Logger.SetReportCaller(reportCaller.Load() || len(l.packages) > 0)
+}
+
+func (l *levels) verbosest() logrus.Level {
+	verbosest := l.base
+	for level := range maps.Values(l.packages) {
+		verbosest = max(verbosest, level)
+	}
+
+	return verbosest
+}
+
+func (l *levels) enabled(entry *logrus.Entry) bool {
+	if len(l.packages) == 0 || entry.Caller == nil {
+		return entry.Level <= l.base
+	}
+
+	pkg := packageOf(entry.Caller.Function)
+	level, longest := l.base, 0
+	for name, pkgLevel := range l.packages {
+		if len(name) > longest && (pkg == name || strings.HasSuffix(pkg, "/"+name)) {
+			level, longest = pkgLevel, len(name)
+		}
+	}
+
+	return entry.Level <= level
+}
+
+// packageOf returns the import path of a function name such as
+// "github.com/rod1kutzyy/OnTrack/internal/usecase.(*todoUseCase).CreateTodo".
+func packageOf(function string) string {
+	slash := strings.LastIndex(function, "/")
+	if dot := strings.Index(function[slash+1:], "."); dot >= 0 {
+		return function[:slash+1+dot]
+	}
+
+	return function
+}
+
+// formatter drops the entries that the per-package levels filter out and
+// hides the caller unless it was asked for.
+type formatter struct {
+	logrus.Formatter
+	showCaller bool
+}
+
+func (f *formatter) Format(entry *logrus.Entry) ([]byte, error) {
+	if l := current.Load(); l != nil && !l.enabled(entry) {
+		return nil, nil
+	}
+
+	if !f.showCaller {
+		entry.Caller = nil
+	}
+
+	return f.Formatter.Format(entry)
+}
@@ -1,33 +1,181 @@
 package logger
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 
+	"github.com/rod1kutzyy/OnTrack/internal/config"
 	"github.com/sirupsen/logrus"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-var Logger *logrus.Logger
+const timestampFormat = "2006-01-02 15:04:05"
+
+var (
+	Logger *logrus.Logger
+
+	// file is the rotated log file opened by Configure, if any.
+	file io.Closer
+)
 
 func Init(level string) error {
-	Logger = logrus.New()
+	return InitWithOutput(level, os.Stdout)
+}
+
+// InitWithOutput is like Init but writes log entries to out. Tests use it to
+// discard logs.
+func InitWithOutput(level string, out io.Writer) error {
+	return configure(config.LoggerConfig{
+		Level:        level,
+		Format:       config.LogFormatJSON,
+		ReportCaller: true,
+	}, out)
+}
 
-	Logger.SetOutput(os.Stdout)
+// Configure sets up Logger as cfg describes. Call Close before exiting to
+// release the log file.
+func Configure(cfg config.LoggerConfig) error {
+	var out io.Writer
+	var opened io.Closer
 
-	Logger.SetFormatter(&logrus.JSONFormatter{
-		TimestampFormat: "2006-01-02 15:04:05",
-		PrettyPrint:     true,
-	})
+	switch cfg.Output {
+	case config.LogOutputStdout, "":
+		out = os.Stdout
+	case config.LogOutputStderr:
+		out = os.Stderr
+	case config.LogOutputFile:
+		rotated, err := openFile(cfg.File)
+		if err != nil {
+			return err
+		}
+		out, opened = rotated, rotated
+	default:
+		return fmt.Errorf("unsupported log output %q", cfg.Output)
+	}
+
+	if err := configure(cfg, out); err != nil {
+		if opened != nil {
+			opened.Close()
+		}
+		return err
+	}
 
-	logLevel, err := logrus.ParseLevel(level)
+	// The previous file is closed only after Logger stops writing to it.
+	if file != nil {
+		file.Close()
+	}
+	file = opened
+
+	return nil
+}
+
+// Close closes the log file, if there is one.
+func Close() error {
+	if file == nil {
+		return nil
+	}
+
+	err := file.Close()
+	file = nil
+	return err
+}
+
+// openFile returns a writer that rotates the file at cfg.Path. The file is
+// opened once up front, so that a bad path fails at startup rather than on
+// the first log entry.
+func openFile(cfg config.LogFileConfig) (*lumberjack.Logger, error) {
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	f, err := os.OpenFile(cfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
 	if err != nil {
-		Logger.SetLevel(logrus.InfoLevel)
-		Logger.Warnf("Invalid log level '%s', using 'info' level", level)
-	} else {
-		Logger.SetLevel(logLevel)
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	f.Close()
+
+	rotated := &lumberjack.Logger{
+		Filename:   cfg.Path,
+		MaxSize:    cfg.MaxSizeMB,
+		MaxAge:     cfg.MaxAgeDays,
+		MaxBackups: cfg.MaxBackups,
+		Compress:   cfg.Compress,
+	}
+
+	return rotated, nil
+}
+
+// configure applies cfg to Logger. Logger is created once and changed in
+// place afterwards, so that reconfiguring it does not race with its users.
+func configure(cfg config.LoggerConfig, out io.Writer) error {
+	level := cfg.Level
+	_, levelErr := logrus.ParseLevel(level)
+	if levelErr != nil {
+		level = logrus.InfoLevel.String()
+	}
+
+	l, err := parseLevels(level, cfg.PackageLevels)
+	if err != nil {
+		return err
+	}
+
+	hooks := logrus.LevelHooks{}
+	// Redaction is on unless it is explicitly turned off.
+	if cfg.Redaction.Mode != config.RedactOff {
+		hook, err := NewRedactionHook(cfg.Redaction)
+		if err != nil {
+			return err
+		}
+		hooks.Add(hook)
+	}
+
+	var base logrus.Formatter
+	switch cfg.Format {
+	case config.LogFormatCompact:
+		base = &logrus.JSONFormatter{TimestampFormat: timestampFormat}
+	case config.LogFormatText:
+		base = &logrus.TextFormatter{TimestampFormat: timestampFormat, FullTimestamp: true}
+	default:
+		base = &logrus.JSONFormatter{TimestampFormat: timestampFormat, PrettyPrint: true}
+	}
+
+	if Logger == nil {
+		Logger = logrus.New()
+	}
+	Logger.SetOutput(out)
+	Logger.SetFormatter(&formatter{Formatter: base, showCaller: cfg.ReportCaller})
+	Logger.ReplaceHooks(hooks)
+
+	reportCaller.Store(cfg.ReportCaller)
+	applyLevels(l)
+
+	if levelErr != nil {
+		Logger.Warnf("Invalid log level '%s', using 'info' level", cfg.Level)
 	}
-	Logger.SetReportCaller(true)
 
 	Logger.Info("Logger initialized successfully")
 
 	return nil
 }
+
+type contextKey struct{}
+
+// WithContext returns a copy of ctx carrying entry. Middleware uses it to
+// attach request fields, such as the request ID, to every log line written
+// while handling the request.
+func WithContext(ctx context.Context, entry *logrus.Entry) context.Context {
+	return context.WithValue(ctx, contextKey{}, entry)
+}
+
+// FromContext returns the logger stored in ctx by WithContext, or Logger
+// when there is none.
+func FromContext(ctx context.Context) *logrus.Entry {
+	if entry, ok := ctx.Value(contextKey{}).(*logrus.Entry); ok {
+		return entry
+	}
+
+	return logrus.NewEntry(Logger)
+}
@@ -0,0 +1,91 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rod1kutzyy/OnTrack/internal/config"
+	"github.com/rod1kutzyy/OnTrack/internal/logger"
+)
+
+func TestConfigureFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "ontrack.log")
+
+	err := logger.Configure(config.LoggerConfig{
+		Level:  "info",
+		Format: config.LogFormatCompact,
+		Output: config.LogOutputFile,
+		File:   config.LogFileConfig{Path: path, MaxSizeMB: 1},
+	})
+	if err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+	logger.Logger.Debug("dropped")
+	logger.Logger.WithField("todo_id", 1).Info("Todo created")
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d log lines, want 2 single-line entries:\n%s", len(lines), data)
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatalf("invalid log line %q: %v", lines[1], err)
+	}
+	if entry["msg"] != "Todo created" || entry["todo_id"] != float64(1) {
+		t.Errorf("got entry %v", entry)
+	}
+	if _, ok := entry["func"]; ok {
+		t.Errorf("entry %v reports the caller, which is disabled", entry)
+	}
+}
+
+func TestPackageLevels(t *testing.T) {
+	var out bytes.Buffer
+	if err := logger.InitWithOutput("warn", &out); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		level    string
+		packages map[string]string
+		wantLogs bool
+	}{
+		{name: "base level", level: "warn", wantLogs: false},
+		{name: "more verbose package", level: "warn", packages: map[string]string{"logger_test": "debug"}, wantLogs: true},
+		{name: "quieter package", level: "debug", packages: map[string]string{"logger_test": "error"}, wantLogs: false},
+		{name: "longest match wins", level: "warn", packages: map[string]string{"internal/logger_test": "debug", "logger_test": "error"}, wantLogs: true},
+		{name: "other package", level: "warn", packages: map[string]string{"usecase": "debug"}, wantLogs: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := logger.SetLevels(tt.level, tt.packages); err != nil {
+				t.Fatalf("SetLevels failed: %v", err)
+			}
+
+			out.Reset()
+			logger.Logger.Debug("Debugging")
+			if logged := out.Len() > 0; logged != tt.wantLogs {
+				t.Errorf("logged %q, want output %t", out.String(), tt.wantLogs)
+			}
+		})
+	}
+
+	if err := logger.SetLevels("info", map[string]string{"usecase": "verbose"}); err == nil {
+		t.Error("SetLevels accepted an invalid package level")
+	}
+}
@@ -0,0 +1,163 @@
+package logger
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rod1kutzyy/OnTrack/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+const redacted = "[REDACTED]"
+
+// defaultRedactedFields name the fields that hold credentials or todo
+// content. A field is masked when its name contains one of them, so
+// "db_password" matches "password".
+var defaultRedactedFields = []string{
+	"password", "passwd", "secret", "token", "authorization", "cookie",
+	"api_key", "apikey", "dsn", "title", "description", "search", "content",
+}
+
+// defaultPatterns mask emails, bearer tokens, credentials in key=value
+// pairs such as query strings and DSNs, and passwords in URLs.
+var defaultPatterns = []string{
+	`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`,
+	`(?i)\bbearer\s+([A-Za-z0-9._~+/-]+=*)`,
+	`(?i)\b(?:password|passwd|secret|token|access_token|api_key|apikey|search|q)=([^&\s]+)`,
+	`://[^:/@\s]+:([^@/\s]+)@`,
+}
+
+// defaultAllowedFields are the fields kept in allowlist mode. Their string
+// values are still matched against the patterns.
+var defaultAllowedFields = []string{
+	logrus.ErrorKey, "request_id", "trace_id", "status_code", "latency",
+	"method", "path", "client_ip", "id", "count", "completed", "limit", "offset",
+}
+
+// RedactionHook masks sensitive data in log entries before they are
+// formatted.
+type RedactionHook struct {
+	fields   []string
+	patterns []*regexp.Regexp
+	// allowed is nil unless the hook runs in allowlist mode.
+	allowed map[string]bool
+}
+
+func NewRedactionHook(cfg config.RedactionConfig) (*RedactionHook, error) {
+	h := &RedactionHook{}
+
+	for _, field := range append(defaultRedactedFields, cfg.Fields...) {
+		h.fields = append(h.fields, strings.ToLower(field))
+	}
+
+	for _, pattern := range append(defaultPatterns, cfg.Patterns...) {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+		h.patterns = append(h.patterns, re)
+	}
+
+	if cfg.Mode == config.RedactAllowlist {
+		h.allowed = map[string]bool{}
+		for _, field := range append(defaultAllowedFields, cfg.AllowedFields...) {
+			h.allowed[field] = true
+		}
+	}
+
+	return h, nil
+}
+
+func (h *RedactionHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire rewrites the entry in place; logrus hands every hook its own copy of
+// the fields.
+func (h *RedactionHook) Fire(entry *logrus.Entry) error {
+	entry.Message = h.redactString(entry.Message)
+
+	for key, value := range entry.Data {
+		entry.Data[key] = h.redactField(key, value)
+	}
+
+	return nil
+}
+
+func (h *RedactionHook) redactField(key string, value any) any {
+	if h.allowed != nil {
+		if !h.allowed[key] {
+			return redacted
+		}
+	} else if h.sensitive(key) {
+		return redacted
+	}
+
+	var s string
+	switch v := value.(type) {
+	case string:
+		return h.redactString(v)
+	case error:
+		s = v.Error()
+	case fmt.Stringer:
+		s = v.String()
+	default:
+		return value
+	}
+
+	if masked := h.redactString(s); masked != s {
+		return masked
+	}
+	return value
+}
+
+func (h *RedactionHook) sensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, field := range h.fields {
+		if strings.Contains(key, field) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (h *RedactionHook) redactString(s string) string {
+	for _, re := range h.patterns {
+		s = mask(re, s)
+	}
+
+	return s
+}
+
+// mask replaces the groups of every match of re, or the whole match when re
+// has no groups.
+func mask(re *regexp.Regexp, s string) string {
+	matches := re.FindAllStringSubmatchIndex(s, -1)
+	if matches == nil {
+		return s
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, match := range matches {
+		spans := match[:2]
+		if len(match) > 2 {
+			spans = match[2:]
+		}
+
+		for i := 0; i < len(spans); i += 2 {
+			start, end := spans[i], spans[i+1]
+			if start < last {
+				continue
+			}
+			b.WriteString(s[last:start])
+			b.WriteString(redacted)
+			last = end
+		}
+	}
+	b.WriteString(s[last:])
+
+	return b.String()
+}
@@ -0,0 +1,107 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/rod1kutzyy/OnTrack/internal/config"
+	"github.com/rod1kutzyy/OnTrack/internal/logger"
+	"github.com/sirupsen/logrus"
+)
+
+func TestRedactionHook(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.RedactionConfig
+		message string
+		fields  logrus.Fields
+		want    map[string]any
+	}{
+		{
+			name:   "sensitive fields",
+			cfg:    config.RedactionConfig{Mode: config.RedactDenylist},
+			fields: logrus.Fields{"title": "See doctor", "DB_Password": "hunter2", "id": 7},
+			want:   map[string]any{"title": "[REDACTED]", "DB_Password": "[REDACTED]", "id": float64(7)},
+		},
+		{
+			name:   "query string",
+			cfg:    config.RedactionConfig{Mode: config.RedactDenylist},
+			fields: logrus.Fields{"query": "page=2&search=divorce+lawyer&token=abc"},
+			want:   map[string]any{"query": "page=2&search=[REDACTED]&token=[REDACTED]"},
+		},
+		{
+			name:    "dsn and email in message",
+			cfg:     config.RedactionConfig{Mode: config.RedactDenylist},
+			message: "cannot connect with host=db password=hunter2 as jane.doe@example.com",
+			want:    map[string]any{"msg": "cannot connect with host=db password=[REDACTED] as [REDACTED]"},
+		},
+		{
+			name:   "error values",
+			cfg:    config.RedactionConfig{Mode: config.RedactDenylist},
+			fields: logrus.Fields{logrus.ErrorKey: errors.New("dial postgres://app:hunter2@db:5432: Bearer eyJhbGciOi.x")},
+			want:   map[string]any{logrus.ErrorKey: "dial postgres://app:[REDACTED]@db:5432: Bearer [REDACTED]"},
+		},
+		{
+			name: "configured rules",
+			cfg: config.RedactionConfig{
+				Mode:     config.RedactDenylist,
+				Fields:   []string{"ssn"},
+				Patterns: []string{`\d{4}-\d{4}-\d{4}-\d{4}`},
+			},
+			message: "charged card 4111-1111-1111-1111",
+			fields:  logrus.Fields{"user_ssn": "123-45-6789"},
+			want:    map[string]any{"msg": "charged card [REDACTED]", "user_ssn": "[REDACTED]"},
+		},
+		{
+			name: "allowlist",
+			cfg:  config.RedactionConfig{Mode: config.RedactAllowlist, AllowedFields: []string{"attempt"}},
+			fields: logrus.Fields{
+				"request_id": "req-1",
+				"attempt":    2,
+				"filter":     map[string]string{"search": "private"},
+				"path":       "/api/v1/todos?q=private",
+			},
+			want: map[string]any{
+				"request_id": "req-1",
+				"attempt":    float64(2),
+				"filter":     "[REDACTED]",
+				"path":       "/api/v1/todos?q=[REDACTED]",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook, err := logger.NewRedactionHook(tt.cfg)
+			if err != nil {
+				t.Fatalf("NewRedactionHook failed: %v", err)
+			}
+
+			var out bytes.Buffer
+			log := logrus.New()
+			log.SetOutput(&out)
+			log.SetFormatter(&logrus.JSONFormatter{})
+			log.AddHook(hook)
+
+			log.WithFields(tt.fields).Info(tt.message)
+
+			var entry map[string]any
+			if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+				t.Fatalf("invalid log line %q: %v", out.String(), err)
+			}
+			for key, want := range tt.want {
+				if got := entry[key]; got != want {
+					t.Errorf("%s = %v, want %v", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewRedactionHookRejectsInvalidPattern(t *testing.T) {
+	if _, err := logger.NewRedactionHook(config.RedactionConfig{Patterns: []string{"("}}); err == nil {
+		t.Error("NewRedactionHook accepted an invalid pattern")
+	}
+}
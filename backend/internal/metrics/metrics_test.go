@@ -0,0 +1,94 @@
+package metrics_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/rod1kutzyy/OnTrack/internal/domain"
+	"github.com/rod1kutzyy/OnTrack/internal/logger"
+	"github.com/rod1kutzyy/OnTrack/internal/metrics"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+func TestMain(m *testing.M) {
+	if err := logger.InitWithOutput("error", io.Discard); err != nil {
+		panic(err)
+	}
+
+	os.Exit(m.Run())
+}
+
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("scrape status = %d: %s", rec.Code, rec.Body)
+	}
+
+	return rec.Body.String()
+}
+
+func TestInstrumentDB(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "ontrack.db")), &gorm.Config{
+		Logger: gormLogger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&domain.Todo{}); err != nil {
+		t.Fatal(err)
+	}
+
+	m := metrics.New()
+	if err := m.InstrumentDB(db); err != nil {
+		t.Fatalf("InstrumentDB failed: %v", err)
+	}
+
+	if err := db.Create(&domain.Todo{Title: "Buy milk"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	var todos []domain.Todo
+	if err := db.Find(&todos).Error; err != nil {
+		t.Fatal(err)
+	}
+	db.Exec("SELECT * FROM missing_table")
+
+	body := scrape(t, m)
+	for _, want := range []string{
+		`ontrack_db_query_duration_seconds_count{operation="create",status="ok",table="todos"} 1`,
+		`ontrack_db_query_duration_seconds_count{operation="query",status="ok",table="todos"} 1`,
+		`ontrack_db_query_duration_seconds_count{operation="raw",status="error",table="unknown"} 1`,
+		`go_sql_max_open_connections{db_name="sqlite"}`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics do not contain %s", want)
+		}
+	}
+}
+
+type failingCounter struct{}
+
+func (failingCounter) Count(context.Context, domain.TodoFilter) (int64, error) {
+	return 0, context.DeadlineExceeded
+}
+
+func TestTodoCountsSkipFailures(t *testing.T) {
+	m := metrics.New()
+	if err := m.RegisterTodoCounts(failingCounter{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if body := scrape(t, m); strings.Contains(body, "ontrack_todos{") {
+		t.Errorf("metrics contain todo counts although counting failed:\n%s", body)
+	}
+}
@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rod1kutzyy/OnTrack/internal/domain"
+	"github.com/rod1kutzyy/OnTrack/internal/logger"
+)
+
+// countTimeout bounds the queries run on each scrape.
+const countTimeout = 5 * time.Second
+
+// TodoCounter counts todos; repository.TodoRepository implements it.
+type TodoCounter interface {
+	Count(ctx context.Context, filter domain.TodoFilter) (int64, error)
+}
+
+// RegisterTodoCounts exports the number of open and completed todos. They
+// are counted when Prometheus scrapes, so the values are never stale.
+func (m *Metrics) RegisterTodoCounts(counter TodoCounter) error {
+	if err := m.registry.Register(&todoCollector{counter: counter}); err != nil {
+		return fmt.Errorf("failed to register todo metrics: %w", err)
+	}
+
+	return nil
+}
+
+var todosDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "todos"),
+	"Number of todos by completion status.",
+	[]string{"status"}, nil,
+)
+
+type todoCollector struct {
+	counter TodoCounter
+}
+
+func (c *todoCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- todosDesc
+}
+
+func (c *todoCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), countTimeout)
+	defer cancel()
+
+	for _, status := range []struct {
+		name      string
+		completed bool
+	}{
+		{"open", false},
+		{"completed", true},
+	} {
+		count, err := c.counter.Count(ctx, domain.TodoFilter{Completed: &status.completed})
+		if err != nil {
+			// Leaving the series out makes the gap visible without failing
+			// the whole scrape.
+			logger.Logger.WithError(err).WithField("status", status.name).Warn("Failed to count todos for metrics")
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(todosDesc, prometheus.GaugeValue, float64(count), status.name)
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rod1kutzyy/OnTrack/internal/dto"
+	"github.com/rod1kutzyy/OnTrack/internal/logger"
+)
+
+// AdminAuth requires the admin token as a bearer token. An empty token
+// disables the protected routes altogether.
+func AdminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			response := dto.NewErrorResponseWithCode("Forbidden", "The admin API is disabled", "ADMIN_DISABLED")
+			response.RequestID = GetRequestID(c)
+			c.AbortWithStatusJSON(http.StatusForbidden, response)
+			return
+		}
+
+		provided, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			logger.FromContext(c.Request.Context()).WithField("client_ip", c.ClientIP()).Warn("Rejected admin request")
+			c.Header("WWW-Authenticate", `Bearer realm="admin"`)
+			response := dto.NewErrorResponseWithCode("Unauthorized", "A valid admin token is required", "UNAUTHORIZED")
+			response.RequestID = GetRequestID(c)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, response)
+			return
+		}
+
+		c.Next()
+	}
+}
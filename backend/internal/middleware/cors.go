@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// CORS answers cross-origin requests from a set of origins that can be
+// changed while the server runs, e.g. on a configuration reload.
+type CORS struct {
+	handler atomic.Pointer[gin.HandlerFunc]
+}
+
+func NewCORS(origins []string) *CORS {
+	m := &CORS{}
+	m.SetOrigins(origins)
+
+	return m
+}
+
+// SetOrigins replaces the allowed origins. Requests in flight finish with
+// the old ones.
+func (m *CORS) SetOrigins(origins []string) {
+	handler := cors.New(cors.Config{
+		AllowOrigins:  origins,
+		AllowMethods:  []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"},
+		AllowHeaders:  []string{"Origin", "Content-Type", "Authorization", "traceparent", "tracestate", RequestIDHeader},
+		ExposeHeaders: []string{"Content-Length", RequestIDHeader},
+		MaxAge:        12 * time.Hour,
+	})
+	m.handler.Store(&handler)
+}
+
+func (m *CORS) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		(*m.handler.Load())(c)
+	}
+}
@@ -0,0 +1,42 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rod1kutzyy/OnTrack/internal/middleware"
+)
+
+func TestCORSSetOrigins(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cors := middleware.NewCORS([]string{"http://localhost:5173"})
+	router := gin.New()
+	router.Use(cors.Handler())
+	router.GET("/", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	allowedOrigin := func(origin string) string {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Origin", origin)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Header().Get("Access-Control-Allow-Origin")
+	}
+
+	if got := allowedOrigin("http://localhost:5173"); got != "http://localhost:5173" {
+		t.Errorf("allowed origin = %q before the change", got)
+	}
+
+	cors.SetOrigins([]string{"https://todo.example.com"})
+
+	if got := allowedOrigin("https://todo.example.com"); got != "https://todo.example.com" {
+		t.Errorf("new origin is not allowed, got %q", got)
+	}
+	if got := allowedOrigin("http://localhost:5173"); got != "" {
+		t.Errorf("old origin is still allowed, got %q", got)
+	}
+}
@@ -25,7 +25,7 @@ func Logger() gin.HandlerFunc {
 		method := c.Request.Method
 		userAgent := c.Request.UserAgent()
 
-		logEntry := logger.Logger.WithFields(logrus.Fields{
+		logEntry := logger.FromContext(c.Request.Context()).WithFields(logrus.Fields{
 			"status_code": statusCode,
 			"latency":     latency.Milliseconds(),
 			"client_ip":   clientIP,
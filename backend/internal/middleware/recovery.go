@@ -14,10 +14,11 @@ func Recovery() gin.HandlerFunc {
 		defer func() {
 			if err := recover(); err != nil {
 				stack := string(debug.Stack())
-				logger.Logger.WithField("stack", stack).Errorf("Panic recovered: %v", err)
+				logger.FromContext(c.Request.Context()).WithField("stack", stack).Errorf("Panic recovered: %v", err)
 				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-					"error":   "Internal server error",
-					"message": fmt.Sprintf("%v", err),
+					"error":      "Internal server error",
+					"message":    fmt.Sprintf("%v", err),
+					"request_id": GetRequestID(c),
 				})
 			}
 		}()
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rod1kutzyy/OnTrack/internal/logger"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader carries the request ID in requests and responses.
+const RequestIDHeader = "X-Request-ID"
+
+const (
+	requestIDKey       = "request_id"
+	maxRequestIDLength = 128
+)
+
+// RequestID accepts the X-Request-ID of the caller, e.g. a proxy, or
+// generates one, and returns it in the response header. The request context
+// gets a logger that adds the ID, and the trace ID when tracing, to every
+// line, see logger.FromContext.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := AcceptRequestID(c.GetHeader(RequestIDHeader))
+
+		c.Set(requestIDKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		c.Request = c.Request.WithContext(WithRequestID(c.Request.Context(), requestID))
+
+		c.Next()
+	}
+}
+
+// AcceptRequestID returns the request ID sent by the caller, or a new one
+// when it sent none or one that is not valid.
+func AcceptRequestID(requestID string) string {
+	if !validRequestID(requestID) {
+		return uuid.NewString()
+	}
+
+	return requestID
+}
+
+// WithRequestID returns a context whose logger adds the request ID, and the
+// trace ID when tracing, to every line. The gRPC server uses it as well.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	fields := logrus.Fields{"request_id": requestID}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		fields["trace_id"] = spanContext.TraceID().String()
+	}
+
+	return logger.WithContext(ctx, logger.Logger.WithFields(fields))
+}
+
+// GetRequestID returns the ID assigned by RequestID, or "" outside of it.
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// validRequestID accepts short IDs made of characters that cannot forge
+// log lines or headers.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,464 @@
+// Package memory keeps todos in process memory. It is meant for tests and
+// local demos: todos are lost when the process exits.
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rod1kutzyy/OnTrack/internal/domain"
+	"github.com/rod1kutzyy/OnTrack/internal/repository"
+)
+
+type todoRepository struct {
+	// txMu is held exclusively by a running transaction and shared by calls
+	// made outside of it, which wait for the transaction to end.
+	txMu sync.RWMutex
+
+	mu     sync.RWMutex
+	todos  map[uint]*domain.Todo
+	uids   map[string]uint
+	lastID uint
+}
+
+func NewTodoRepository() repository.TodoRepository {
+	return &todoRepository{
+		todos: make(map[uint]*domain.Todo),
+		uids:  make(map[string]uint),
+	}
+}
+
+func (r *todoRepository) Create(ctx context.Context, todo *domain.Todo) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to create todo: %w", err)
+	}
+
+	defer r.enter(ctx)()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkUnique(todo, 0); err != nil {
+		return fmt.Errorf("failed to create todo: %w", err)
+	}
+
+	r.insert(todo, time.Now())
+
+	return nil
+}
+
+func (r *todoRepository) GetByID(ctx context.Context, id uint) (*domain.Todo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get todo: %w", err)
+	}
+
+	defer r.enter(ctx)()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	todo, ok := r.todos[id]
+	if !ok {
+		return nil, fmt.Errorf("todo with id %d not found", id)
+	}
+
+	return cloneTodo(todo), nil
+}
+
+func (r *todoRepository) GetByIDForUpdate(ctx context.Context, id uint) (*domain.Todo, error) {
+	// A transaction locks the whole repository already.
+	return r.GetByID(ctx, id)
+}
+
+func (r *todoRepository) GetByUID(ctx context.Context, uid string) (*domain.Todo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get todo: %w", err)
+	}
+
+	defer r.enter(ctx)()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.uids[uid]
+	if !ok {
+		return nil, fmt.Errorf("todo with uid %q not found", uid)
+	}
+
+	return cloneTodo(r.todos[id]), nil
+}
+
+func (r *todoRepository) GetAll(ctx context.Context, filter domain.TodoFilter) ([]domain.Todo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get todos: %w", err)
+	}
+
+	defer r.enter(ctx)()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	todos := r.filter(filter)
+
+	slices.SortFunc(todos, func(a, b domain.Todo) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		// The database leaves the order of equal timestamps undefined; newest
+		// ID first keeps pages stable here.
+		return cmp.Compare(b.ID, a.ID)
+	})
+
+	return paginate(todos, filter.Offset, filter.Limit), nil
+}
+
+func (r *todoRepository) Update(ctx context.Context, todo *domain.Todo) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to update todo: %w", err)
+	}
+
+	defer r.enter(ctx)()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.todos[todo.ID]
+	if !ok {
+		return fmt.Errorf("todo with id %d not found", todo.ID)
+	}
+
+	if err := r.checkUnique(todo, todo.ID); err != nil {
+		return fmt.Errorf("failed to update todo: %w", err)
+	}
+
+	if existing.UID != nil {
+		delete(r.uids, *existing.UID)
+	}
+
+	todo.UpdatedAt = time.Now()
+	r.store(todo)
+
+	return nil
+}
+
+func (r *todoRepository) UpdateFields(ctx context.Context, id uint, update domain.TodoUpdate) (*domain.Todo, error) {
+	if update.IsEmpty() {
+		return r.GetByID(ctx, id)
+	}
+
+	return r.modify(ctx, id, func(todo *domain.Todo) {
+		if update.Title != nil {
+			todo.Title = *update.Title
+		}
+
+		if update.Description != nil {
+			if *update.Description == "" {
+				todo.Description = nil
+			} else {
+				description := *update.Description
+				todo.Description = &description
+			}
+		}
+
+		if update.Completed != nil {
+			todo.Completed = *update.Completed
+		}
+	})
+}
+
+func (r *todoRepository) ToggleCompleted(ctx context.Context, id uint) (*domain.Todo, error) {
+	return r.modify(ctx, id, func(todo *domain.Todo) {
+		todo.Completed = !todo.Completed
+	})
+}
+
+// modify applies fn to a copy of the stored todo and stores the result, all
+// under the write lock, like a single UPDATE statement.
+func (r *todoRepository) modify(ctx context.Context, id uint, fn func(todo *domain.Todo)) (*domain.Todo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to update todo: %w", err)
+	}
+
+	defer r.enter(ctx)()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.todos[id]
+	if !ok {
+		return nil, fmt.Errorf("todo with id %d not found", id)
+	}
+
+	todo := cloneTodo(existing)
+	fn(todo)
+	todo.UpdatedAt = time.Now()
+	r.store(todo)
+
+	return cloneTodo(todo), nil
+}
+
+func (r *todoRepository) Delete(ctx context.Context, id uint) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to delete todo: %w", err)
+	}
+
+	defer r.enter(ctx)()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	todo, ok := r.todos[id]
+	if !ok {
+		return fmt.Errorf("todo with id %d not found", id)
+	}
+
+	if todo.UID != nil {
+		delete(r.uids, *todo.UID)
+	}
+	delete(r.todos, id)
+
+	return nil
+}
+
+func (r *todoRepository) Count(ctx context.Context, filter domain.TodoFilter) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, fmt.Errorf("failed to count todos: %w", err)
+	}
+
+	defer r.enter(ctx)()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return int64(len(r.filter(filter))), nil
+}
+
+func (r *todoRepository) ListAfterID(ctx context.Context, afterID uint, filter domain.TodoFilter) ([]domain.Todo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list todos: %w", err)
+	}
+
+	defer r.enter(ctx)()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	todos := slices.DeleteFunc(r.filter(filter), func(todo domain.Todo) bool {
+		return todo.ID <= afterID
+	})
+
+	slices.SortFunc(todos, func(a, b domain.Todo) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return paginate(todos, 0, filter.Limit), nil
+}
+
+func (r *todoRepository) Restore(ctx context.Context, todos []domain.Todo, remapIDs bool) (map[uint]uint, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to restore todos: %w", err)
+	}
+
+	defer r.enter(ctx)()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !remapIDs && len(r.todos) > 0 {
+		return nil, fmt.Errorf("%w: %d todos exist", repository.ErrNotEmpty, len(r.todos))
+	}
+
+	// Check everything up front so that a failed restore leaves no todos behind.
+	ids := make(map[uint]bool, len(todos))
+	uids := make(map[string]bool, len(todos))
+	for i := range todos {
+		todo := &todos[i]
+
+		if !remapIDs && todo.ID != 0 {
+			if ids[todo.ID] {
+				return nil, fmt.Errorf("failed to restore todos: duplicate id %d", todo.ID)
+			}
+			ids[todo.ID] = true
+		}
+
+		if todo.UID != nil {
+			if _, exists := r.uids[*todo.UID]; exists || uids[*todo.UID] {
+				return nil, fmt.Errorf("%w: %q", repository.ErrUIDConflict, *todo.UID)
+			}
+			uids[*todo.UID] = true
+		}
+	}
+
+	now := time.Now()
+	restored := make(map[uint]uint, len(todos))
+	for i := range todos {
+		todo := &todos[i]
+
+		originalID := todo.ID
+		if remapIDs {
+			todo.ID = 0
+		}
+
+		r.insert(todo, now)
+		restored[originalID] = todo.ID
+	}
+
+	return restored, nil
+}
+
+// checkUnique reports a UID already used by a todo other than exceptID, and
+// an explicit ID that is taken when creating.
+func (r *todoRepository) checkUnique(todo *domain.Todo, exceptID uint) error {
+	if exceptID == 0 && todo.ID != 0 {
+		if _, exists := r.todos[todo.ID]; exists {
+			return fmt.Errorf("duplicate id %d", todo.ID)
+		}
+	}
+
+	if todo.UID != nil {
+		if id, exists := r.uids[*todo.UID]; exists && id != exceptID {
+			return fmt.Errorf("duplicate uid %q", *todo.UID)
+		}
+	}
+
+	return nil
+}
+
+// insert stores a new todo, assigning an ID and timestamps like the database
+// does: explicit IDs are kept and later IDs continue after them.
+func (r *todoRepository) insert(todo *domain.Todo, now time.Time) {
+	if todo.ID == 0 {
+		r.lastID++
+		todo.ID = r.lastID
+	} else if todo.ID > r.lastID {
+		r.lastID = todo.ID
+	}
+
+	if todo.CreatedAt.IsZero() {
+		todo.CreatedAt = now
+	}
+	if todo.UpdatedAt.IsZero() {
+		todo.UpdatedAt = now
+	}
+
+	r.store(todo)
+}
+
+func (r *todoRepository) store(todo *domain.Todo) {
+	stored := cloneTodo(todo)
+
+	r.todos[stored.ID] = stored
+	if stored.UID != nil {
+		r.uids[*stored.UID] = stored.ID
+	}
+}
+
+func (r *todoRepository) filter(filter domain.TodoFilter) []domain.Todo {
+	var pattern []rune
+	if filter.Search != "" {
+		pattern = []rune(strings.ToLower(repository.SearchPattern(filter.Search)))
+	}
+
+	var todos []domain.Todo
+	for _, todo := range r.todos {
+		if filter.Completed != nil && todo.Completed != *filter.Completed {
+			continue
+		}
+
+		if pattern != nil && !matchesSearch(todo, pattern) {
+			continue
+		}
+
+		todos = append(todos, *cloneTodo(todo))
+	}
+
+	return todos
+}
+
+// matchesSearch mirrors "title ILIKE ? ESCAPE '\' OR description ILIKE ? ESCAPE '\'".
+func matchesSearch(todo *domain.Todo, pattern []rune) bool {
+	if like([]rune(strings.ToLower(todo.Title)), pattern) {
+		return true
+	}
+
+	return todo.Description != nil && like([]rune(strings.ToLower(*todo.Description)), pattern)
+}
+
+// like reports whether s matches a SQL LIKE pattern, where % matches any
+// sequence, _ any single character and \ escapes the next character.
+func like(s, pattern []rune) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '%':
+			for len(pattern) > 0 && pattern[0] == '%' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := range len(s) + 1 {
+				if like(s[i:], pattern) {
+					return true
+				}
+			}
+			return false
+		case '_':
+			if len(s) == 0 {
+				return false
+			}
+		default:
+			if pattern[0] == '\\' && len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			if len(s) == 0 || s[0] != pattern[0] {
+				return false
+			}
+		}
+
+		s, pattern = s[1:], pattern[1:]
+	}
+
+	return len(s) == 0
+}
+
+// paginate applies LIMIT and OFFSET the way GORM builds them: a negative
+// limit means no limit and a non-positive offset none.
+func paginate(todos []domain.Todo, offset, limit int) []domain.Todo {
+	if offset > 0 {
+		if offset >= len(todos) {
+			return nil
+		}
+		todos = todos[offset:]
+	}
+
+	if limit >= 0 && limit < len(todos) {
+		todos = todos[:limit]
+	}
+
+	if len(todos) == 0 {
+		return nil
+	}
+
+	return todos
+}
+
+func cloneTodo(todo *domain.Todo) *domain.Todo {
+	clone := *todo
+
+	if todo.Description != nil {
+		description := *todo.Description
+		clone.Description = &description
+	}
+
+	if todo.UID != nil {
+		uid := *todo.UID
+		clone.UID = &uid
+	}
+
+	return &clone
+}
@@ -0,0 +1,22 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/rod1kutzyy/OnTrack/internal/repository"
+	"github.com/rod1kutzyy/OnTrack/internal/repository/memory"
+	"github.com/rod1kutzyy/OnTrack/internal/repository/repositorytest"
+)
+
+func TestTodoRepository(t *testing.T) {
+	repositorytest.TestTodoRepository(t, func(t *testing.T) repository.TodoRepository {
+		return memory.NewTodoRepository()
+	})
+}
+
+func TestTransactor(t *testing.T) {
+	repositorytest.TestTransactor(t, func(t *testing.T) (repository.TodoRepository, repository.Transactor) {
+		repo := memory.NewTodoRepository()
+		return repo, memory.NewTransactor(repo)
+	})
+}
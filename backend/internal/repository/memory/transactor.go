@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/rod1kutzyy/OnTrack/internal/domain"
+	"github.com/rod1kutzyy/OnTrack/internal/repository"
+)
+
+type txKey struct{}
+
+type transactor struct {
+	repo *todoRepository
+}
+
+// NewTransactor returns a transactor for repo, which must have been created
+// by NewTodoRepository. Transactions run one at a time and block all other
+// calls to repo while they run.
+func NewTransactor(repo repository.TodoRepository) repository.Transactor {
+	r, ok := repo.(*todoRepository)
+	if !ok {
+		panic(fmt.Sprintf("memory: NewTransactor called with a %T", repo))
+	}
+
+	return &transactor{repo: r}
+}
+
+func (t *transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	r := t.repo
+
+	if !r.inTransaction(ctx) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		r.txMu.Lock()
+		defer r.txMu.Unlock()
+
+		ctx = context.WithValue(ctx, txKey{}, r)
+	}
+
+	saved := r.snapshot()
+	committed := false
+	defer func() {
+		if !committed {
+			r.restore(saved)
+		}
+	}()
+
+	if err := fn(ctx); err != nil {
+		return err
+	}
+
+	committed = true
+	return nil
+}
+
+// WithinSnapshot is WithinTransaction, which already keeps all other calls
+// out while fn runs.
+func (t *transactor) WithinSnapshot(ctx context.Context, fn func(ctx context.Context) error) error {
+	return t.WithinTransaction(ctx, fn)
+}
+
+func (r *todoRepository) inTransaction(ctx context.Context) bool {
+	return ctx.Value(txKey{}) == r
+}
+
+// enter waits for a running transaction to end unless ctx belongs to it,
+// and returns the function that ends the call.
+func (r *todoRepository) enter(ctx context.Context) func() {
+	if r.inTransaction(ctx) {
+		return func() {}
+	}
+
+	r.txMu.RLock()
+	return r.txMu.RUnlock
+}
+
+type snapshot struct {
+	todos  map[uint]*domain.Todo
+	uids   map[string]uint
+	lastID uint
+}
+
+// snapshot copies the maps only: stored todos are replaced on update, never
+// modified in place.
+func (r *todoRepository) snapshot() snapshot {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return snapshot{
+		todos:  maps.Clone(r.todos),
+		uids:   maps.Clone(r.uids),
+		lastID: r.lastID,
+	}
+}
+
+func (r *todoRepository) restore(s snapshot) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.todos, r.uids, r.lastID = s.todos, s.uids, s.lastID
+}
@@ -0,0 +1,602 @@
+// Package repositorytest holds the contract every repository.TodoRepository
+// implementation must fulfil.
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rod1kutzyy/OnTrack/internal/domain"
+	"github.com/rod1kutzyy/OnTrack/internal/repository"
+)
+
+// NewTodoRepository returns an empty repository. It is called once per test.
+type NewTodoRepository func(t *testing.T) repository.TodoRepository
+
+// TestTodoRepository runs the contract tests against the repositories
+// returned by newRepo.
+func TestTodoRepository(t *testing.T, newRepo NewTodoRepository) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, repo repository.TodoRepository)
+	}{
+		{"CreateAndGet", testCreateAndGet},
+		{"GetMissing", testGetMissing},
+		{"DuplicateUID", testDuplicateUID},
+		{"GetAllFilters", testGetAllFilters},
+		{"SearchIsLiteral", testSearchIsLiteral},
+		{"GetAllOrderAndPagination", testGetAllOrderAndPagination},
+		{"Update", testUpdate},
+		{"UpdateMissing", testUpdateMissing},
+		{"UpdateFields", testUpdateFields},
+		{"UpdateFieldsMissing", testUpdateFieldsMissing},
+		{"ToggleCompleted", testToggleCompleted},
+		{"ConcurrentToggles", testConcurrentToggles},
+		{"Delete", testDelete},
+		{"ListAfterID", testListAfterID},
+		{"RestoreKeepsIDs", testRestoreKeepsIDs},
+		{"RestoreIntoNonEmpty", testRestoreIntoNonEmpty},
+		{"RestoreUIDConflict", testRestoreUIDConflict},
+		{"ConcurrentCreates", testConcurrentCreates},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newRepo(t))
+		})
+	}
+}
+
+var baseTime = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func create(t *testing.T, repo repository.TodoRepository, todo domain.Todo) domain.Todo {
+	t.Helper()
+
+	if err := repo.Create(context.Background(), &todo); err != nil {
+		t.Fatalf("Create(%q) failed: %v", todo.Title, err)
+	}
+
+	return todo
+}
+
+func ids(todos []domain.Todo) []uint {
+	ids := make([]uint, len(todos))
+	for i, todo := range todos {
+		ids[i] = todo.ID
+	}
+
+	return ids
+}
+
+func assertIDs(t *testing.T, what string, got []domain.Todo, want ...uint) {
+	t.Helper()
+
+	if fmt.Sprint(ids(got)) != fmt.Sprint(want) {
+		t.Errorf("%s returned IDs %v, want %v", what, ids(got), want)
+	}
+}
+
+func assertNotFound(t *testing.T, what string, err error) {
+	t.Helper()
+
+	// Handlers detect missing todos by the error message.
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("%s returned error %v, want a not found error", what, err)
+	}
+}
+
+func testCreateAndGet(t *testing.T, repo repository.TodoRepository) {
+	ctx := context.Background()
+
+	todo := create(t, repo, domain.Todo{Title: "Buy milk", Description: ptr("2 liters"), UID: ptr("abc@example.com")})
+	if todo.ID == 0 {
+		t.Fatal("Create did not assign an ID")
+	}
+	if todo.CreatedAt.IsZero() || todo.UpdatedAt.IsZero() {
+		t.Error("Create did not set the timestamps")
+	}
+
+	other := create(t, repo, domain.Todo{Title: "Write report", Completed: true})
+	if other.ID == todo.ID {
+		t.Errorf("Create assigned ID %d twice", todo.ID)
+	}
+
+	got, err := repo.GetByID(ctx, todo.ID)
+	if err != nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+	if got.Title != "Buy milk" || got.Description == nil || *got.Description != "2 liters" || got.Completed {
+		t.Errorf("GetByID returned %+v", got)
+	}
+	// Databases may store timestamps with microsecond precision.
+	if got.CreatedAt.Sub(todo.CreatedAt).Abs() >= time.Microsecond {
+		t.Errorf("GetByID returned created_at %v, want %v", got.CreatedAt, todo.CreatedAt)
+	}
+
+	byUID, err := repo.GetByUID(ctx, "abc@example.com")
+	if err != nil {
+		t.Fatalf("GetByUID failed: %v", err)
+	}
+	if byUID.ID != todo.ID {
+		t.Errorf("GetByUID returned todo %d, want %d", byUID.ID, todo.ID)
+	}
+
+	// The repository must not share memory with the caller.
+	*got.Description = "changed"
+	again, _ := repo.GetByID(ctx, todo.ID)
+	if *again.Description != "2 liters" {
+		t.Error("changing a returned todo changed the stored one")
+	}
+}
+
+func testGetMissing(t *testing.T, repo repository.TodoRepository) {
+	ctx := context.Background()
+
+	_, err := repo.GetByID(ctx, 4242)
+	assertNotFound(t, "GetByID", err)
+
+	_, err = repo.GetByUID(ctx, "missing@example.com")
+	assertNotFound(t, "GetByUID", err)
+}
+
+func testDuplicateUID(t *testing.T, repo repository.TodoRepository) {
+	create(t, repo, domain.Todo{Title: "first", UID: ptr("same@example.com")})
+
+	todo := domain.Todo{Title: "second", UID: ptr("same@example.com")}
+	if err := repo.Create(context.Background(), &todo); err == nil {
+		t.Error("Create accepted a duplicate UID")
+	}
+}
+
+func testGetAllFilters(t *testing.T, repo repository.TodoRepository) {
+	ctx := context.Background()
+
+	milk := create(t, repo, domain.Todo{Title: "Buy MILK", CreatedAt: baseTime})
+	report := create(t, repo, domain.Todo{Title: "Write report", Description: ptr("Quarterly milk sales"), Completed: true, CreatedAt: baseTime.Add(time.Hour)})
+	invoice := create(t, repo, domain.Todo{Title: "Pay invoice", CreatedAt: baseTime.Add(2 * time.Hour)})
+
+	tests := []struct {
+		name   string
+		filter domain.TodoFilter
+		want   []uint
+	}{
+		{"all", domain.TodoFilter{Limit: 10}, []uint{invoice.ID, report.ID, milk.ID}},
+		{"completed", domain.TodoFilter{Completed: ptr(true), Limit: 10}, []uint{report.ID}},
+		{"open", domain.TodoFilter{Completed: ptr(false), Limit: 10}, []uint{invoice.ID, milk.ID}},
+		{"search title and description ignoring case", domain.TodoFilter{Search: "milk", Limit: 10}, []uint{report.ID, milk.ID}},
+		{"search and completed", domain.TodoFilter{Search: "milk", Completed: ptr(false), Limit: 10}, []uint{milk.ID}},
+		{"search without match", domain.TodoFilter{Search: "bread", Limit: 10}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todos, err := repo.GetAll(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("GetAll failed: %v", err)
+			}
+			assertIDs(t, "GetAll", todos, tt.want...)
+
+			count, err := repo.Count(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("Count failed: %v", err)
+			}
+			if count != int64(len(tt.want)) {
+				t.Errorf("Count returned %d, want %d", count, len(tt.want))
+			}
+		})
+	}
+}
+
+func testSearchIsLiteral(t *testing.T, repo repository.TodoRepository) {
+	ctx := context.Background()
+
+	percent := create(t, repo, domain.Todo{Title: "100% done"})
+	create(t, repo, domain.Todo{Title: "1000 done"})
+	underscore := create(t, repo, domain.Todo{Title: "snake_case"})
+	create(t, repo, domain.Todo{Title: "snakeXcase"})
+	backslash := create(t, repo, domain.Todo{Title: `C:\temp`})
+
+	tests := []struct {
+		search string
+		want   []uint
+	}{
+		{"100%", []uint{percent.ID}},
+		{"_", []uint{underscore.ID}},
+		{"snake_case", []uint{underscore.ID}},
+		{`:\t`, []uint{backslash.ID}},
+		{`\%`, nil},
+	}
+
+	for _, tt := range tests {
+		todos, err := repo.GetAll(ctx, domain.TodoFilter{Search: tt.search, Limit: 10})
+		if err != nil {
+			t.Fatalf("GetAll(%q) failed: %v", tt.search, err)
+		}
+		assertIDs(t, fmt.Sprintf("GetAll(%q)", tt.search), todos, tt.want...)
+	}
+}
+
+func testGetAllOrderAndPagination(t *testing.T, repo repository.TodoRepository) {
+	ctx := context.Background()
+
+	var created []uint
+	for i := range 7 {
+		// Created out of order so that the result is not simply by ID.
+		todo := create(t, repo, domain.Todo{Title: fmt.Sprintf("todo %d", i), CreatedAt: baseTime.Add(time.Duration((i*3)%7) * time.Minute)})
+		created = append(created, todo.ID)
+	}
+
+	// Newest first: minutes 6, 5, ... 0 belong to todos 2, 4, 6, 1, 3, 5, 0.
+	newestFirst := []uint{created[2], created[4], created[6], created[1], created[3], created[5], created[0]}
+
+	pages := []struct {
+		offset, limit int
+		want          []uint
+	}{
+		{0, 3, newestFirst[0:3]},
+		{3, 3, newestFirst[3:6]},
+		{6, 3, newestFirst[6:]},
+		{9, 3, nil},
+	}
+
+	for _, page := range pages {
+		todos, err := repo.GetAll(ctx, domain.TodoFilter{Offset: page.offset, Limit: page.limit})
+		if err != nil {
+			t.Fatalf("GetAll failed: %v", err)
+		}
+		assertIDs(t, fmt.Sprintf("GetAll(offset %d, limit %d)", page.offset, page.limit), todos, page.want...)
+	}
+
+	count, err := repo.Count(ctx, domain.TodoFilter{Offset: 3, Limit: 3})
+	if err != nil {
+		t.Fatalf("Count failed: %v", err)
+	}
+	if count != 7 {
+		t.Errorf("Count returned %d, want 7 regardless of pagination", count)
+	}
+}
+
+func testUpdate(t *testing.T, repo repository.TodoRepository) {
+	ctx := context.Background()
+
+	todo := create(t, repo, domain.Todo{Title: "Buy milk", CreatedAt: baseTime, UpdatedAt: baseTime})
+
+	todo.Title = "Buy oat milk"
+	todo.Description = ptr("the barista one")
+	todo.Completed = true
+	if err := repo.Update(ctx, &todo); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	got, err := repo.GetByID(ctx, todo.ID)
+	if err != nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+	if got.Title != "Buy oat milk" || got.Description == nil || *got.Description != "the barista one" || !got.Completed {
+		t.Errorf("GetByID after Update returned %+v", got)
+	}
+	if !got.CreatedAt.Equal(baseTime) {
+		t.Errorf("Update changed created_at to %v", got.CreatedAt)
+	}
+	if !got.UpdatedAt.After(baseTime) {
+		t.Errorf("Update did not advance updated_at, got %v", got.UpdatedAt)
+	}
+}
+
+func testUpdateMissing(t *testing.T, repo repository.TodoRepository) {
+	ctx := context.Background()
+
+	todo := domain.Todo{ID: 4242, Title: "ghost", CreatedAt: baseTime}
+	assertNotFound(t, "Update", repo.Update(ctx, &todo))
+
+	if _, err := repo.GetByID(ctx, 4242); err == nil {
+		t.Error("Update of a missing todo created it")
+	}
+}
+
+func testUpdateFields(t *testing.T, repo repository.TodoRepository) {
+	ctx := context.Background()
+
+	todo := create(t, repo, domain.Todo{Title: "Buy milk", Description: ptr("2 liters"), UID: ptr("milk@example.com"), CreatedAt: baseTime, UpdatedAt: baseTime})
+
+	got, err := repo.UpdateFields(ctx, todo.ID, domain.TodoUpdate{Completed: ptr(true)})
+	if err != nil {
+		t.Fatalf("UpdateFields failed: %v", err)
+	}
+	if got.Title != "Buy milk" || got.Description == nil || *got.Description != "2 liters" || !got.Completed {
+		t.Errorf("UpdateFields(completed) returned %+v", got)
+	}
+	if got.UID == nil || *got.UID != "milk@example.com" || !got.CreatedAt.Equal(baseTime) {
+		t.Errorf("UpdateFields changed fields it was not given: %+v", got)
+	}
+	if !got.UpdatedAt.After(baseTime) {
+		t.Errorf("UpdateFields did not advance updated_at, got %v", got.UpdatedAt)
+	}
+
+	got, err = repo.UpdateFields(ctx, todo.ID, domain.TodoUpdate{Title: ptr("Buy oat milk"), Description: ptr("")})
+	if err != nil {
+		t.Fatalf("UpdateFields failed: %v", err)
+	}
+	if got.Title != "Buy oat milk" || got.Description != nil || !got.Completed {
+		t.Errorf("UpdateFields(title, no description) returned %+v", got)
+	}
+
+	stored, err := repo.GetByID(ctx, todo.ID)
+	if err != nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+	if stored.Title != got.Title || stored.Description != nil || !stored.Completed || !stored.UpdatedAt.Equal(got.UpdatedAt) {
+		t.Errorf("GetByID after UpdateFields returned %+v, want %+v", stored, got)
+	}
+
+	unchanged, err := repo.UpdateFields(ctx, todo.ID, domain.TodoUpdate{})
+	if err != nil {
+		t.Fatalf("UpdateFields without fields failed: %v", err)
+	}
+	if unchanged.Title != "Buy oat milk" || !unchanged.UpdatedAt.Equal(got.UpdatedAt) {
+		t.Errorf("UpdateFields without fields returned %+v", unchanged)
+	}
+}
+
+func testUpdateFieldsMissing(t *testing.T, repo repository.TodoRepository) {
+	ctx := context.Background()
+
+	_, err := repo.UpdateFields(ctx, 4242, domain.TodoUpdate{Title: ptr("ghost")})
+	assertNotFound(t, "UpdateFields", err)
+
+	_, err = repo.UpdateFields(ctx, 4242, domain.TodoUpdate{})
+	assertNotFound(t, "UpdateFields without fields", err)
+
+	_, err = repo.ToggleCompleted(ctx, 4242)
+	assertNotFound(t, "ToggleCompleted", err)
+
+	if _, err := repo.GetByID(ctx, 4242); err == nil {
+		t.Error("updating a missing todo created it")
+	}
+}
+
+func testToggleCompleted(t *testing.T, repo repository.TodoRepository) {
+	ctx := context.Background()
+
+	todo := create(t, repo, domain.Todo{Title: "Buy milk", Description: ptr("2 liters"), CreatedAt: baseTime, UpdatedAt: baseTime})
+	other := create(t, repo, domain.Todo{Title: "Write report"})
+
+	for _, want := range []bool{true, false} {
+		got, err := repo.ToggleCompleted(ctx, todo.ID)
+		if err != nil {
+			t.Fatalf("ToggleCompleted failed: %v", err)
+		}
+		if got.Completed != want || got.Title != "Buy milk" || got.Description == nil || *got.Description != "2 liters" {
+			t.Errorf("ToggleCompleted returned %+v, want completed %t", got, want)
+		}
+		if !got.CreatedAt.Equal(baseTime) || !got.UpdatedAt.After(baseTime) {
+			t.Errorf("ToggleCompleted returned created_at %v, updated_at %v", got.CreatedAt, got.UpdatedAt)
+		}
+	}
+
+	if got, _ := repo.GetByID(ctx, other.ID); got == nil || got.Completed {
+		t.Errorf("ToggleCompleted changed another todo: %+v", got)
+	}
+}
+
+// testConcurrentToggles toggles a todo an even number of times from several
+// goroutines. The todo ends up open unless a toggle was lost.
+func testConcurrentToggles(t *testing.T, repo repository.TodoRepository) {
+	ctx := context.Background()
+
+	todo := create(t, repo, domain.Todo{Title: "Buy milk"})
+
+	const workers, perWorker = 8, 5
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Go(func() {
+			for range perWorker {
+				if _, err := repo.ToggleCompleted(ctx, todo.ID); err != nil {
+					t.Errorf("ToggleCompleted failed: %v", err)
+					return
+				}
+			}
+		})
+	}
+
+	wg.Wait()
+
+	got, err := repo.GetByID(ctx, todo.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Completed {
+		t.Errorf("todo is completed after %d toggles, a toggle was lost", workers*perWorker)
+	}
+}
+
+func testDelete(t *testing.T, repo repository.TodoRepository) {
+	ctx := context.Background()
+
+	todo := create(t, repo, domain.Todo{Title: "Buy milk", UID: ptr("milk@example.com")})
+
+	if err := repo.Delete(ctx, todo.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	_, err := repo.GetByID(ctx, todo.ID)
+	assertNotFound(t, "GetByID after Delete", err)
+
+	assertNotFound(t, "second Delete", repo.Delete(ctx, todo.ID))
+
+	// The UID is free again.
+	create(t, repo, domain.Todo{Title: "Buy milk again", UID: ptr("milk@example.com")})
+}
+
+func testListAfterID(t *testing.T, repo repository.TodoRepository) {
+	ctx := context.Background()
+
+	var created []uint
+	for i := range 5 {
+		todo := create(t, repo, domain.Todo{Title: fmt.Sprintf("todo %d", i), Completed: i%2 == 1, CreatedAt: baseTime.Add(-time.Duration(i) * time.Hour)})
+		created = append(created, todo.ID)
+	}
+
+	todos, err := repo.ListAfterID(ctx, 0, domain.TodoFilter{Limit: 3})
+	if err != nil {
+		t.Fatalf("ListAfterID failed: %v", err)
+	}
+	assertIDs(t, "ListAfterID(0, 3)", todos, created[:3]...)
+
+	todos, err = repo.ListAfterID(ctx, created[2], domain.TodoFilter{Limit: 3})
+	if err != nil {
+		t.Fatalf("ListAfterID failed: %v", err)
+	}
+	assertIDs(t, "ListAfterID after the first page", todos, created[3:]...)
+
+	todos, err = repo.ListAfterID(ctx, created[4], domain.TodoFilter{Limit: 3})
+	if err != nil {
+		t.Fatalf("ListAfterID failed: %v", err)
+	}
+	assertIDs(t, "ListAfterID after the last todo", todos)
+
+	todos, err = repo.ListAfterID(ctx, created[0], domain.TodoFilter{Completed: ptr(true), Search: "todo", Limit: 3, Offset: 1})
+	if err != nil {
+		t.Fatalf("ListAfterID failed: %v", err)
+	}
+	assertIDs(t, "ListAfterID(completed)", todos, created[1], created[3])
+}
+
+func testRestoreKeepsIDs(t *testing.T, repo repository.TodoRepository) {
+	ctx := context.Background()
+
+	todos := []domain.Todo{
+		{ID: 7, Title: "seven", CreatedAt: baseTime, UpdatedAt: baseTime},
+		{ID: 3, Title: "three", Completed: true, UID: ptr("three@example.com"), CreatedAt: baseTime, UpdatedAt: baseTime},
+	}
+
+	restored, err := repo.Restore(ctx, todos, false)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if restored[7] != 7 || restored[3] != 3 {
+		t.Errorf("Restore returned IDs %v, want the original ones", restored)
+	}
+
+	got, err := repo.GetByID(ctx, 3)
+	if err != nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+	if got.Title != "three" || !got.Completed || !got.CreatedAt.Equal(baseTime) {
+		t.Errorf("GetByID returned %+v", got)
+	}
+
+	// New todos continue after the highest restored ID.
+	next := create(t, repo, domain.Todo{Title: "next"})
+	if next.ID <= 7 {
+		t.Errorf("Create after Restore assigned ID %d, want more than 7", next.ID)
+	}
+}
+
+func testRestoreIntoNonEmpty(t *testing.T, repo repository.TodoRepository) {
+	ctx := context.Background()
+
+	existing := create(t, repo, domain.Todo{Title: "existing"})
+
+	_, err := repo.Restore(ctx, []domain.Todo{{ID: existing.ID, Title: "clash"}}, false)
+	if !errors.Is(err, repository.ErrNotEmpty) {
+		t.Fatalf("Restore without remapping returned %v, want ErrNotEmpty", err)
+	}
+
+	todos := []domain.Todo{
+		{ID: existing.ID, Title: "clash"},
+		{ID: 100, Title: "hundred"},
+	}
+
+	restored, err := repo.Restore(ctx, todos, true)
+	if err != nil {
+		t.Fatalf("Restore with remapping failed: %v", err)
+	}
+	if len(restored) != 2 || restored[existing.ID] == existing.ID || restored[100] == 0 {
+		t.Errorf("Restore returned IDs %v, want new IDs for both todos", restored)
+	}
+
+	got, err := repo.GetByID(ctx, restored[existing.ID])
+	if err != nil || got.Title != "clash" {
+		t.Errorf("GetByID(%d) returned %+v, %v", restored[existing.ID], got, err)
+	}
+
+	count, err := repo.Count(ctx, domain.TodoFilter{})
+	if err != nil || count != 3 {
+		t.Errorf("Count returned %d, %v, want 3", count, err)
+	}
+}
+
+func testRestoreUIDConflict(t *testing.T, repo repository.TodoRepository) {
+	ctx := context.Background()
+
+	create(t, repo, domain.Todo{Title: "existing", UID: ptr("taken@example.com")})
+
+	tests := []struct {
+		name  string
+		todos []domain.Todo
+	}{
+		{"stored uid", []domain.Todo{{ID: 1, Title: "fresh", UID: ptr("fresh@example.com")}, {ID: 2, Title: "clash", UID: ptr("taken@example.com")}}},
+		{"duplicate in backup", []domain.Todo{{ID: 1, Title: "one", UID: ptr("twice@example.com")}, {ID: 2, Title: "two", UID: ptr("twice@example.com")}}},
+	}
+
+	for _, tt := range tests {
+		_, err := repo.Restore(ctx, tt.todos, true)
+		if !errors.Is(err, repository.ErrUIDConflict) {
+			t.Errorf("%s: Restore returned %v, want ErrUIDConflict", tt.name, err)
+		}
+	}
+
+	count, err := repo.Count(ctx, domain.TodoFilter{})
+	if err != nil || count != 1 {
+		t.Errorf("Count returned %d, %v, want only the existing todo", count, err)
+	}
+}
+
+func testConcurrentCreates(t *testing.T, repo repository.TodoRepository) {
+	ctx := context.Background()
+
+	const workers, perWorker = 8, 10
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		seen = map[uint]bool{}
+	)
+
+	for w := range workers {
+		wg.Go(func() {
+			for i := range perWorker {
+				todo := domain.Todo{Title: fmt.Sprintf("worker %d todo %d", w, i)}
+				if err := repo.Create(ctx, &todo); err != nil {
+					t.Errorf("Create failed: %v", err)
+					return
+				}
+
+				mu.Lock()
+				if seen[todo.ID] {
+					t.Errorf("ID %d was assigned twice", todo.ID)
+				}
+				seen[todo.ID] = true
+				mu.Unlock()
+			}
+		})
+	}
+
+	wg.Wait()
+
+	count, err := repo.Count(ctx, domain.TodoFilter{})
+	if err != nil || count != workers*perWorker {
+		t.Errorf("Count returned %d, %v, want %d", count, err, workers*perWorker)
+	}
+}
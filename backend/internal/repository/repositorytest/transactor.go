@@ -0,0 +1,274 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rod1kutzyy/OnTrack/internal/domain"
+	"github.com/rod1kutzyy/OnTrack/internal/repository"
+)
+
+// NewTransactionalTodoRepository returns an empty repository and the
+// transactor for it. It is called once per test.
+type NewTransactionalTodoRepository func(t *testing.T) (repository.TodoRepository, repository.Transactor)
+
+// TestTransactor runs the contract tests for repository.Transactor against
+// the repositories returned by newRepo.
+func TestTransactor(t *testing.T, newRepo NewTransactionalTodoRepository) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, repo repository.TodoRepository, tx repository.Transactor)
+	}{
+		{"Commit", testCommit},
+		{"Rollback", testRollback},
+		{"RollbackOnPanic", testRollbackOnPanic},
+		{"NestedRollback", testNestedRollback},
+		{"GetForUpdateMissing", testGetForUpdateMissing},
+		{"ConcurrentReadModifyWrite", testConcurrentReadModifyWrite},
+		{"Snapshot", testSnapshot},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, tx := newRepo(t)
+			tt.test(t, repo, tx)
+		})
+	}
+}
+
+var errAbort = errors.New("abort")
+
+func testCommit(t *testing.T, repo repository.TodoRepository, tx repository.Transactor) {
+	existing := create(t, repo, domain.Todo{Title: "Buy milk", CreatedAt: baseTime})
+
+	var created domain.Todo
+	err := tx.WithinTransaction(context.Background(), func(ctx context.Context) error {
+		created = domain.Todo{Title: "Write report", CreatedAt: baseTime.Add(time.Hour)}
+		if err := repo.Create(ctx, &created); err != nil {
+			return err
+		}
+
+		// Reads in the transaction see its own writes.
+		if _, err := repo.GetByID(ctx, created.ID); err != nil {
+			return err
+		}
+
+		todo, err := repo.GetByIDForUpdate(ctx, existing.ID)
+		if err != nil {
+			return err
+		}
+		todo.Completed = true
+		return repo.Update(ctx, todo)
+	})
+	if err != nil {
+		t.Fatalf("WithinTransaction failed: %v", err)
+	}
+
+	todos, err := repo.GetAll(context.Background(), domain.TodoFilter{Completed: ptr(true), Limit: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertIDs(t, "GetAll(completed) after commit", todos, existing.ID)
+
+	if _, err := repo.GetByID(context.Background(), created.ID); err != nil {
+		t.Errorf("todo created in the transaction is missing: %v", err)
+	}
+}
+
+func testRollback(t *testing.T, repo repository.TodoRepository, tx repository.Transactor) {
+	ctx := context.Background()
+	kept := create(t, repo, domain.Todo{Title: "Buy milk", CreatedAt: baseTime})
+	deleted := create(t, repo, domain.Todo{Title: "Write report", CreatedAt: baseTime.Add(time.Hour)})
+
+	err := tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := repo.Create(ctx, &domain.Todo{Title: "Call mom"}); err != nil {
+			return err
+		}
+
+		todo, err := repo.GetByIDForUpdate(ctx, kept.ID)
+		if err != nil {
+			return err
+		}
+		todo.Title = "Buy oat milk"
+		if err := repo.Update(ctx, todo); err != nil {
+			return err
+		}
+
+		if err := repo.Delete(ctx, deleted.ID); err != nil {
+			return err
+		}
+
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("WithinTransaction returned %v, want %v", err, errAbort)
+	}
+
+	todos, err := repo.GetAll(ctx, domain.TodoFilter{Limit: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertIDs(t, "GetAll after rollback", todos, deleted.ID, kept.ID)
+
+	if todos[1].Title != "Buy milk" {
+		t.Errorf("update was not rolled back, title is %q", todos[1].Title)
+	}
+}
+
+func testRollbackOnPanic(t *testing.T, repo repository.TodoRepository, tx repository.Transactor) {
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("WithinTransaction did not propagate the panic")
+			}
+		}()
+
+		tx.WithinTransaction(context.Background(), func(ctx context.Context) error {
+			if err := repo.Create(ctx, &domain.Todo{Title: "Buy milk"}); err != nil {
+				return err
+			}
+			panic("boom")
+		})
+	}()
+
+	count, err := repo.Count(context.Background(), domain.TodoFilter{Search: "milk"})
+	if err != nil || count != 0 {
+		t.Errorf("Count after panic returned %d, %v, want 0", count, err)
+	}
+}
+
+func testNestedRollback(t *testing.T, repo repository.TodoRepository, tx repository.Transactor) {
+	ctx := context.Background()
+
+	err := tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := repo.Create(ctx, &domain.Todo{Title: "outer"}); err != nil {
+			return err
+		}
+
+		err := tx.WithinTransaction(ctx, func(ctx context.Context) error {
+			if err := repo.Create(ctx, &domain.Todo{Title: "inner"}); err != nil {
+				return err
+			}
+			return errAbort
+		})
+		if !errors.Is(err, errAbort) {
+			t.Errorf("nested WithinTransaction returned %v, want %v", err, errAbort)
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithinTransaction failed: %v", err)
+	}
+
+	todos, err := repo.GetAll(ctx, domain.TodoFilter{Limit: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(todos) != 1 || todos[0].Title != "outer" {
+		t.Errorf("GetAll returned %+v, want only the outer todo", todos)
+	}
+}
+
+func testGetForUpdateMissing(t *testing.T, repo repository.TodoRepository, tx repository.Transactor) {
+	_, err := repo.GetByIDForUpdate(context.Background(), 42)
+	assertNotFound(t, "GetByIDForUpdate outside a transaction", err)
+
+	err = tx.WithinTransaction(context.Background(), func(ctx context.Context) error {
+		_, err := repo.GetByIDForUpdate(ctx, 42)
+		return err
+	})
+	assertNotFound(t, "GetByIDForUpdate", err)
+}
+
+// testConcurrentReadModifyWrite increments a counter kept in the title from
+// several goroutines. Without the lock taken by GetByIDForUpdate increments
+// would be lost.
+func testConcurrentReadModifyWrite(t *testing.T, repo repository.TodoRepository, tx repository.Transactor) {
+	todo := create(t, repo, domain.Todo{Title: "0"})
+
+	const workers = 10
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Go(func() {
+			err := tx.WithinTransaction(context.Background(), func(ctx context.Context) error {
+				current, err := repo.GetByIDForUpdate(ctx, todo.ID)
+				if err != nil {
+					return err
+				}
+
+				n, err := strconv.Atoi(current.Title)
+				if err != nil {
+					return err
+				}
+
+				// Give the other goroutines a chance to read the same value.
+				time.Sleep(5 * time.Millisecond)
+
+				current.Title = strconv.Itoa(n + 1)
+				return repo.Update(ctx, current)
+			})
+			if err != nil {
+				t.Errorf("WithinTransaction failed: %v", err)
+			}
+		})
+	}
+
+	wg.Wait()
+
+	got, err := repo.GetByID(context.Background(), todo.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != strconv.Itoa(workers) {
+		t.Errorf("counter is %s after %d increments", got.Title, workers)
+	}
+}
+
+// testSnapshot creates a todo outside a snapshot while it runs. Reads in the
+// snapshot must not see it, whether the write waits for the snapshot to end
+// or not.
+func testSnapshot(t *testing.T, repo repository.TodoRepository, tx repository.Transactor) {
+	first := create(t, repo, domain.Todo{Title: "first"})
+
+	written := make(chan error, 1)
+	err := tx.WithinSnapshot(context.Background(), func(ctx context.Context) error {
+		before, err := repo.ListAfterID(ctx, 0, domain.TodoFilter{Limit: 10})
+		if err != nil {
+			return err
+		}
+		assertIDs(t, "ListAfterID before the write", before, first.ID)
+
+		go func() {
+			written <- repo.Create(context.Background(), &domain.Todo{Title: "second"})
+		}()
+		select {
+		case err := <-written:
+			written <- err
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		after, err := repo.ListAfterID(ctx, 0, domain.TodoFilter{Limit: 10})
+		if err != nil {
+			return err
+		}
+		assertIDs(t, "ListAfterID after the write", after, first.ID)
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithinSnapshot failed: %v", err)
+	}
+
+	if err := <-written; err != nil {
+		t.Fatalf("Create outside the snapshot failed: %v", err)
+	}
+	if count, err := repo.Count(context.Background(), domain.TodoFilter{}); err != nil || count != 2 {
+		t.Errorf("Count after the snapshot returned %d, %v, want 2", count, err)
+	}
+}
@@ -0,0 +1,333 @@
+// Package sqlstore stores todos in PostgreSQL or SQLite through GORM.
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/rod1kutzyy/OnTrack/internal/domain"
+	"github.com/rod1kutzyy/OnTrack/internal/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const restoreBatchSize = 500
+
+const dialectSQLite = "sqlite"
+
+type todoRepository struct {
+	db     *gorm.DB
+	sqlite bool
+}
+
+// NewTodoRepository returns a repository for db, which must have been
+// opened with the postgres or sqlite dialector.
+func NewTodoRepository(db *gorm.DB) repository.TodoRepository {
+	return &todoRepository{
+		db:     db,
+		sqlite: db.Dialector.Name() == dialectSQLite,
+	}
+}
+
+func (r *todoRepository) Create(ctx context.Context, todo *domain.Todo) error {
+	r.normalizeTimes(todo)
+
+	if err := r.conn(ctx).Create(todo).Error; err != nil {
+		return fmt.Errorf("failed to create todo: %w", err)
+	}
+
+	return nil
+}
+
+func (r *todoRepository) GetByID(ctx context.Context, id uint) (*domain.Todo, error) {
+	var todo domain.Todo
+
+	if err := r.conn(ctx).First(&todo, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("todo with id %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to get todo: %w", err)
+	}
+
+	return &todo, nil
+}
+
+func (r *todoRepository) GetByIDForUpdate(ctx context.Context, id uint) (*domain.Todo, error) {
+	var todo domain.Todo
+
+	query := r.conn(ctx)
+	// SQLite has no row locks. Its databases are opened with a single
+	// connection, which a transaction holds until it ends, so transactions
+	// are serialized instead.
+	if !r.sqlite {
+		query = query.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate})
+	}
+
+	if err := query.First(&todo, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("todo with id %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to get todo: %w", err)
+	}
+
+	return &todo, nil
+}
+
+func (r *todoRepository) GetByUID(ctx context.Context, uid string) (*domain.Todo, error) {
+	var todo domain.Todo
+
+	if err := r.conn(ctx).Where("uid = ?", uid).First(&todo).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("todo with uid %q not found", uid)
+		}
+		return nil, fmt.Errorf("failed to get todo: %w", err)
+	}
+
+	return &todo, nil
+}
+
+func (r *todoRepository) GetAll(ctx context.Context, filter domain.TodoFilter) ([]domain.Todo, error) {
+	var todos []domain.Todo
+
+	query := r.applyFilter(r.conn(ctx).Model(&domain.Todo{}), filter)
+
+	query = query.Order("created_at DESC")
+
+	query = query.Limit(filter.Limit).Offset(filter.Offset)
+
+	if err := query.Find(&todos).Error; err != nil {
+		return nil, fmt.Errorf("failed to get todos: %w", err)
+	}
+
+	return todos, nil
+}
+
+func (r *todoRepository) Update(ctx context.Context, todo *domain.Todo) error {
+	r.normalizeTimes(todo)
+
+	// Without an explicit Select, Save inserts the todo when no row matches.
+	result := r.conn(ctx).Select("*").Save(todo)
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to update todo: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("todo with id %d not found", todo.ID)
+	}
+
+	return nil
+}
+
+func (r *todoRepository) UpdateFields(ctx context.Context, id uint, update domain.TodoUpdate) (*domain.Todo, error) {
+	if update.IsEmpty() {
+		return r.GetByID(ctx, id)
+	}
+
+	columns := make(map[string]any, 3)
+
+	if update.Title != nil {
+		columns["title"] = *update.Title
+	}
+
+	if update.Description != nil {
+		if *update.Description == "" {
+			columns["description"] = nil
+		} else {
+			columns["description"] = *update.Description
+		}
+	}
+
+	if update.Completed != nil {
+		columns["completed"] = *update.Completed
+	}
+
+	return r.updateColumns(ctx, id, columns)
+}
+
+func (r *todoRepository) ToggleCompleted(ctx context.Context, id uint) (*domain.Todo, error) {
+	return r.updateColumns(ctx, id, map[string]any{"completed": gorm.Expr("NOT completed")})
+}
+
+// updateColumns runs UPDATE ... RETURNING *, so that concurrent changes to
+// other columns are kept and the returned todo is the row as written. GORM
+// sets updated_at.
+func (r *todoRepository) updateColumns(ctx context.Context, id uint, columns map[string]any) (*domain.Todo, error) {
+	var todo domain.Todo
+
+	result := r.conn(ctx).Model(&todo).Clauses(clause.Returning{}).Where("id = ?", id).Updates(columns)
+
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to update todo: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("todo with id %d not found", id)
+	}
+
+	return &todo, nil
+}
+
+func (r *todoRepository) Delete(ctx context.Context, id uint) error {
+	result := r.conn(ctx).Delete(&domain.Todo{}, id)
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete todo: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("todo with id %d not found", id)
+	}
+
+	return nil
+}
+
+func (r *todoRepository) Count(ctx context.Context, filter domain.TodoFilter) (int64, error) {
+	var count int64
+
+	query := r.applyFilter(r.conn(ctx).Model(&domain.Todo{}), filter)
+
+	if err := query.Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count todos: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *todoRepository) ListAfterID(ctx context.Context, afterID uint, filter domain.TodoFilter) ([]domain.Todo, error) {
+	var todos []domain.Todo
+
+	err := r.applyFilter(r.conn(ctx), filter).
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(filter.Limit).
+		Find(&todos).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list todos: %w", err)
+	}
+
+	return todos, nil
+}
+
+func (r *todoRepository) Restore(ctx context.Context, todos []domain.Todo, remapIDs bool) (map[uint]uint, error) {
+	originalIDs := make([]uint, len(todos))
+	for i := range todos {
+		r.normalizeTimes(&todos[i])
+		originalIDs[i] = todos[i].ID
+		if remapIDs {
+			todos[i].ID = 0
+		}
+	}
+
+	err := r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		if !remapIDs {
+			var count int64
+			if err := tx.Model(&domain.Todo{}).Count(&count).Error; err != nil {
+				return fmt.Errorf("failed to count todos: %w", err)
+			}
+
+			if count > 0 {
+				return fmt.Errorf("%w: %d todos exist", repository.ErrNotEmpty, count)
+			}
+		}
+
+		if err := checkRestoredUIDs(tx, todos); err != nil {
+			return err
+		}
+
+		if len(todos) > 0 {
+			if err := tx.CreateInBatches(todos, restoreBatchSize).Error; err != nil {
+				return fmt.Errorf("failed to restore todos: %w", err)
+			}
+		}
+
+		// SQLite's AUTOINCREMENT counter follows explicit IDs by itself.
+		if !remapIDs && !r.sqlite {
+			// Explicit IDs bypass the sequence, which would otherwise hand them out again.
+			err := tx.Exec("SELECT setval(pg_get_serial_sequence('todos', 'id'), COALESCE((SELECT MAX(id) FROM todos), 0) + 1, false)").Error
+			if err != nil {
+				return fmt.Errorf("failed to reset todo id sequence: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[uint]uint, len(todos))
+	for i := range todos {
+		ids[originalIDs[i]] = todos[i].ID
+	}
+
+	return ids, nil
+}
+
+// checkRestoredUIDs reports UIDs that occur twice in todos or are already
+// stored, which the unique index would otherwise reject with a driver error.
+func checkRestoredUIDs(tx *gorm.DB, todos []domain.Todo) error {
+	seen := make(map[string]bool, len(todos))
+	var uids []string
+	for _, todo := range todos {
+		if todo.UID == nil {
+			continue
+		}
+		if seen[*todo.UID] {
+			return fmt.Errorf("%w: %q", repository.ErrUIDConflict, *todo.UID)
+		}
+		seen[*todo.UID] = true
+		uids = append(uids, *todo.UID)
+	}
+
+	for batch := range slices.Chunk(uids, restoreBatchSize) {
+		var existing []string
+		if err := tx.Model(&domain.Todo{}).Where("uid IN ?", batch).Limit(1).Pluck("uid", &existing).Error; err != nil {
+			return fmt.Errorf("failed to check todo uids: %w", err)
+		}
+		if len(existing) > 0 {
+			return fmt.Errorf("%w: %q", repository.ErrUIDConflict, existing[0])
+		}
+	}
+
+	return nil
+}
+
+func (r *todoRepository) conn(ctx context.Context) *gorm.DB {
+	return conn(ctx, r.db)
+}
+
+func (r *todoRepository) applyFilter(query *gorm.DB, filter domain.TodoFilter) *gorm.DB {
+	if filter.Completed != nil {
+		query = query.Where("completed = ?", *filter.Completed)
+	}
+
+	if filter.Search != "" {
+		searchPattern := repository.SearchPattern(filter.Search)
+		query = query.Where(r.searchCondition(), searchPattern, searchPattern)
+	}
+
+	return query
+}
+
+// searchCondition matches title or description case-insensitively. ILIKE is
+// PostgreSQL only; SQLite's LIKE ignores case by itself, though only for
+// ASCII letters.
+func (r *todoRepository) searchCondition() string {
+	if r.sqlite {
+		return `title LIKE ? ESCAPE '\' OR description LIKE ? ESCAPE '\'`
+	}
+
+	return `title ILIKE ? ESCAPE '\' OR description ILIKE ? ESCAPE '\'`
+}
+
+// normalizeTimes stores timestamps in UTC on SQLite, which keeps them as text
+// and would otherwise sort times with different offsets incorrectly.
+func (r *todoRepository) normalizeTimes(todo *domain.Todo) {
+	if r.sqlite {
+		todo.CreatedAt = todo.CreatedAt.UTC()
+		todo.UpdatedAt = todo.UpdatedAt.UTC()
+	}
+}
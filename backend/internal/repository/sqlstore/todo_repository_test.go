@@ -0,0 +1,247 @@
+package sqlstore_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
+	"github.com/glebarez/sqlite"
+	"github.com/rod1kutzyy/OnTrack/internal/domain"
+	"github.com/rod1kutzyy/OnTrack/internal/infrastructure/database"
+	"github.com/rod1kutzyy/OnTrack/internal/infrastructure/database/migrate"
+	appLogger "github.com/rod1kutzyy/OnTrack/internal/logger"
+	"github.com/rod1kutzyy/OnTrack/internal/repository"
+	"github.com/rod1kutzyy/OnTrack/internal/repository/repositorytest"
+	"github.com/rod1kutzyy/OnTrack/internal/repository/sqlstore"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testDSNEnv names a PostgreSQL database the tests may wipe, e.g.
+// "host=localhost user=postgres password=password dbname=ontrack_test sslmode=disable".
+// Without it the tests start a throwaway server from the binaries cached by
+// embedded-postgres.
+const testDSNEnv = "ONTRACK_TEST_POSTGRES_DSN"
+
+// testCacheEnv names the directory holding the embedded-postgres binaries
+// archive, ~/.embedded-postgres-go by default. The tests only download the
+// archive into it when testDownloadEnv is set, so that they run offline.
+const (
+	testCacheEnv    = "ONTRACK_TEST_POSTGRES_CACHE"
+	testDownloadEnv = "ONTRACK_TEST_POSTGRES_DOWNLOAD"
+)
+
+const testPostgresVersion = embeddedpostgres.V15
+
+func TestMain(m *testing.M) {
+	// The migrator logs through the application logger.
+	if err := appLogger.InitWithOutput("error", io.Discard); err != nil {
+		panic(err)
+	}
+
+	os.Exit(m.Run())
+}
+
+func TestTodoRepositoryPostgres(t *testing.T) {
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		dsn = startPostgres(t)
+	}
+
+	db := openMigrated(t, postgres.Open(dsn), migrate.Postgres)
+
+	newRepo := func(t *testing.T) (repository.TodoRepository, repository.Transactor) {
+		if err := db.Exec("TRUNCATE todos RESTART IDENTITY").Error; err != nil {
+			t.Fatalf("failed to empty todos: %v", err)
+		}
+
+		return sqlstore.NewTodoRepository(db), sqlstore.NewTransactor(db)
+	}
+
+	repositorytest.TestTodoRepository(t, func(t *testing.T) repository.TodoRepository {
+		repo, _ := newRepo(t)
+		return repo
+	})
+	repositorytest.TestTransactor(t, newRepo)
+}
+
+func TestTodoRepositorySQLite(t *testing.T) {
+	newRepo := func(t *testing.T) (repository.TodoRepository, repository.Transactor) {
+		path := filepath.Join(t.TempDir(), "ontrack.db")
+		db := openMigrated(t, sqlite.Open(database.SQLiteDSN(path)), migrate.SQLite)
+
+		return sqlstore.NewTodoRepository(db), sqlstore.NewTransactor(db)
+	}
+
+	repositorytest.TestTodoRepository(t, func(t *testing.T) repository.TodoRepository {
+		repo, _ := newRepo(t)
+		return repo
+	})
+	repositorytest.TestTransactor(t, newRepo)
+}
+
+func TestSearchSQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ontrack.db")
+	repo := sqlstore.NewTodoRepository(openMigrated(t, sqlite.Open(database.SQLiteDSN(path)), migrate.SQLite))
+	ctx := context.Background()
+
+	for _, title := range []string{"100% done", "1000 done", "snake_case", "snakeXcase"} {
+		todo := domain.Todo{Title: title}
+		if err := repo.Create(ctx, &todo); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		search string
+		want   int64
+	}{
+		{"DONE", 2},
+		{"100%", 1},
+		{`100\%`, 0},
+		{"snake_case", 1},
+		{"_", 1},
+	}
+
+	for _, tt := range tests {
+		count, err := repo.Count(ctx, domain.TodoFilter{Search: tt.search})
+		if err != nil {
+			t.Fatalf("Count(%q) failed: %v", tt.search, err)
+		}
+		if count != tt.want {
+			t.Errorf("Count(%q) = %d, want %d", tt.search, count, tt.want)
+		}
+	}
+}
+
+// startPostgres runs a PostgreSQL server in a temporary directory for the
+// duration of the test and returns its DSN. See unavailable for when the
+// binaries are not cached or the server cannot be started, e.g. as root.
+func startPostgres(t *testing.T) string {
+	t.Helper()
+
+	if testing.Short() {
+		t.Skipf("skipping embedded PostgreSQL in short mode; set %s to use an existing server", testDSNEnv)
+	}
+
+	cache := postgresCache(t)
+
+	port, err := freePort()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var logs bytes.Buffer
+	dir := t.TempDir()
+	server := embeddedpostgres.NewDatabase(embeddedpostgres.DefaultConfig().
+		Version(testPostgresVersion).
+		CachePath(cache).
+		Port(port).
+		Database("ontrack_test").
+		RuntimePath(filepath.Join(dir, "runtime")).
+		DataPath(filepath.Join(dir, "data")).
+		StartTimeout(time.Minute).
+		Logger(&logs))
+
+	if err := server.Start(); err != nil {
+		unavailable(t, "failed to start embedded PostgreSQL, set %s to use an existing server: %v\n%s", testDSNEnv, err, logs.String())
+	}
+	t.Cleanup(func() {
+		if err := server.Stop(); err != nil {
+			t.Errorf("failed to stop embedded PostgreSQL: %v\n%s", err, logs.String())
+		}
+	})
+
+	return fmt.Sprintf("host=localhost port=%d user=postgres password=postgres dbname=ontrack_test sslmode=disable", port)
+}
+
+// postgresCache returns the directory of the cached binaries archive. See
+// unavailable for when the archive is missing and may not be downloaded.
+func postgresCache(t *testing.T) string {
+	t.Helper()
+
+	cache := os.Getenv(testCacheEnv)
+	if cache == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			unavailable(t, "failed to locate the embedded PostgreSQL cache, set %s: %v", testCacheEnv, err)
+		}
+		cache = filepath.Join(home, ".embedded-postgres-go")
+	}
+
+	// embedded-postgres names the archive after the platform and version.
+	archives, err := filepath.Glob(filepath.Join(cache, fmt.Sprintf("embedded-postgres-binaries-*-%s.txz", testPostgresVersion)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archives) == 0 && os.Getenv(testDownloadEnv) == "" {
+		unavailable(t, "PostgreSQL %s binaries are not cached in %s; run the tests once online with %s=1, set %s to a directory holding them, or set %s to use an existing server",
+			testPostgresVersion, cache, testDownloadEnv, testCacheEnv, testDSNEnv)
+	}
+
+	return cache
+}
+
+// unavailable skips the test when the embedded PostgreSQL server cannot run,
+// so that the default test run passes without it. The test fails instead
+// when testCacheEnv or testDownloadEnv explicitly ask for the server.
+func unavailable(t *testing.T, format string, args ...interface{}) {
+	t.Helper()
+
+	if os.Getenv(testCacheEnv) != "" || os.Getenv(testDownloadEnv) != "" {
+		t.Fatalf(format, args...)
+	}
+	t.Skipf(format, args...)
+}
+
+func freePort() (uint32, error) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+
+	return uint32(listener.Addr().(*net.TCPAddr).Port), nil
+}
+
+func openMigrated(t *testing.T, dialector gorm.Dialector, dialect migrate.Dialect) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger:  logger.Discard,
+		NowFunc: func() time.Time { return time.Now().UTC() },
+	})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	// Like database.New, which relies on it to serialize transactions.
+	if dialect == migrate.SQLite {
+		sqlDB.SetMaxOpenConns(1)
+	}
+
+	migrator, err := migrate.New(sqlDB, dialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	return db
+}
@@ -2,15 +2,67 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"strings"
 
 	"github.com/rod1kutzyy/OnTrack/internal/domain"
 )
 
+// ErrNotEmpty is returned by Restore when todos would be restored with their
+// original IDs into a database that already contains todos.
+var ErrNotEmpty = errors.New("database is not empty")
+
+// ErrUIDConflict is returned by Restore when a restored todo has the UID of
+// another restored or stored todo.
+var ErrUIDConflict = errors.New("uid already exists")
+
+// likeEscaper escapes the characters LIKE treats specially, with \ as the
+// escape character.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// SearchPattern returns a LIKE pattern, to be used with ESCAPE '\', that
+// matches text containing search literally.
+func SearchPattern(search string) string {
+	return "%" + likeEscaper.Replace(search) + "%"
+}
+
+// Transactor runs several repository calls atomically. Calls made with the
+// context passed to fn take part in the transaction, which is committed when
+// fn returns nil and rolled back otherwise. Calls made with any other context
+// do not see the transaction and may block until it ends. A nested
+// WithinTransaction rolls back only its own changes when it fails.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	// WithinSnapshot is WithinTransaction for reads that must see the
+	// todos as they were when fn started, even across several calls.
+	WithinSnapshot(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 type TodoRepository interface {
 	Create(ctx context.Context, todo *domain.Todo) error
 	GetByID(ctx context.Context, id uint) (*domain.Todo, error)
+	// GetByIDForUpdate is GetByID that also locks the todo against concurrent
+	// changes until the transaction in ctx ends. Outside a transaction it
+	// does not lock.
+	GetByIDForUpdate(ctx context.Context, id uint) (*domain.Todo, error)
+	GetByUID(ctx context.Context, uid string) (*domain.Todo, error)
 	GetAll(ctx context.Context, filter domain.TodoFilter) ([]domain.Todo, error)
 	Update(ctx context.Context, todo *domain.Todo) error
+	// UpdateFields writes only the fields set in update, in a single
+	// statement, and returns the updated todo.
+	UpdateFields(ctx context.Context, id uint, update domain.TodoUpdate) (*domain.Todo, error)
+	// ToggleCompleted flips the completion status in a single statement and
+	// returns the updated todo.
+	ToggleCompleted(ctx context.Context, id uint) (*domain.Todo, error)
 	Delete(ctx context.Context, id uint) error
 	Count(ctx context.Context, filter domain.TodoFilter) (int64, error)
+	// ListAfterID returns up to filter.Limit todos matching filter with an ID
+	// greater than afterID in ascending ID order; filter.Offset is ignored.
+	// Unlike GetAll it is stable under concurrent writes.
+	ListAfterID(ctx context.Context, afterID uint, filter domain.TodoFilter) ([]domain.Todo, error)
+	// Restore inserts todos in a single transaction. Unless remapIDs is set, the
+	// todos keep their IDs and the database must be empty. UIDs are kept
+	// either way and must be unique. It returns the ID each todo was stored
+	// with, keyed by its original ID.
+	Restore(ctx context.Context, todos []domain.Todo, remapIDs bool) (map[uint]uint, error)
 }
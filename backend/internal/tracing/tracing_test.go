@@ -0,0 +1,69 @@
+package tracing_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/rod1kutzyy/OnTrack/internal/domain"
+	"github.com/rod1kutzyy/OnTrack/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+func TestInstrumentDB(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "ontrack.db")), &gorm.Config{
+		Logger: gormLogger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&domain.Todo{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tracing.InstrumentDB(db); err != nil {
+		t.Fatalf("InstrumentDB failed: %v", err)
+	}
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+	db.WithContext(ctx).Create(&domain.Todo{Title: "Buy milk"})
+	db.WithContext(ctx).Exec("SELECT * FROM missing_table")
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("recorded %d spans, want 3", len(spans))
+	}
+
+	create, raw := spans[0], spans[1]
+	for _, span := range []sdktrace.ReadOnlySpan{create, raw} {
+		if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("span %s is not a child of the request span", span.Name())
+		}
+	}
+
+	if create.Name() != "gorm.create" || create.Status().Code == codes.Error {
+		t.Errorf("got span %s with status %v, want a successful gorm.create", create.Name(), create.Status())
+	}
+	attrs := map[string]string{}
+	for _, attr := range create.Attributes() {
+		attrs[string(attr.Key)] = attr.Value.Emit()
+	}
+	if attrs["db.system.name"] != "sqlite" || attrs["db.collection.name"] != "todos" || attrs["db.query.text"] == "" {
+		t.Errorf("gorm.create span has attributes %v", attrs)
+	}
+
+	if raw.Name() != "gorm.raw" || raw.Status().Code != codes.Error {
+		t.Errorf("got span %s with status %v, want a failed gorm.raw", raw.Name(), raw.Status())
+	}
+}
@@ -0,0 +1,18 @@
+package usecase
+
+import (
+	"context"
+	"io"
+)
+
+type RestoreResult struct {
+	SchemaVersion int
+	Restored      int
+	// IDs maps the ID of every restored todo in the backup to its new ID.
+	IDs map[uint]uint
+}
+
+type BackupUseCase interface {
+	Backup(ctx context.Context, w io.Writer) (int, error)
+	Restore(ctx context.Context, r io.Reader, remapIDs bool) (*RestoreResult, error)
+}
@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/rod1kutzyy/OnTrack/internal/domain"
+	"github.com/rod1kutzyy/OnTrack/internal/format/backup"
+	"github.com/rod1kutzyy/OnTrack/internal/logger"
+	"github.com/rod1kutzyy/OnTrack/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrInvalidBackup is returned by Restore when the backup document is
+// malformed or was written with an unsupported schema version.
+var ErrInvalidBackup = errors.New("invalid backup")
+
+type backupUseCase struct {
+	todoRepo   repository.TodoRepository
+	transactor repository.Transactor
+}
+
+func NewBackupUseCase(todoRepo repository.TodoRepository, transactor repository.Transactor) BackupUseCase {
+	return &backupUseCase{
+		todoRepo:   todoRepo,
+		transactor: transactor,
+	}
+}
+
+// Backup reads the todos in one snapshot, so that the backup is consistent
+// even while todos are written.
+func (uc *backupUseCase) Backup(ctx context.Context, w io.Writer) (int, error) {
+	log := logger.FromContext(ctx)
+	log.Info("Creating backup")
+
+	encoder, err := backup.NewEncoder(w)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	err = uc.transactor.WithinSnapshot(ctx, func(ctx context.Context) error {
+		var err error
+		count, err = uc.writeTodos(ctx, encoder)
+		return err
+	})
+	if err != nil {
+		return count, err
+	}
+
+	if err := encoder.Close(); err != nil {
+		return count, fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	log.WithField("count", count).Info("Backup created successfully")
+	return count, nil
+}
+
+func (uc *backupUseCase) writeTodos(ctx context.Context, encoder *backup.Encoder) (int, error) {
+	var (
+		afterID uint
+		count   int
+	)
+
+	for {
+		todos, err := uc.todoRepo.ListAfterID(ctx, afterID, domain.TodoFilter{Limit: iterateBatchSize})
+		if err != nil {
+			return count, fmt.Errorf("failed to read todos: %w", err)
+		}
+
+		for i := range todos {
+			if err := encoder.Write(&todos[i]); err != nil {
+				return count, err
+			}
+			count++
+		}
+
+		if err := encoder.Flush(); err != nil {
+			return count, fmt.Errorf("failed to write backup: %w", err)
+		}
+
+		if len(todos) < iterateBatchSize {
+			return count, nil
+		}
+		afterID = todos[len(todos)-1].ID
+	}
+}
+
+func (uc *backupUseCase) Restore(ctx context.Context, r io.Reader, remapIDs bool) (*RestoreResult, error) {
+	log := logger.FromContext(ctx)
+	log.WithField("remap_ids", remapIDs).Info("Restoring backup")
+
+	snapshot, err := backup.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+
+	todos := make([]domain.Todo, len(snapshot.Todos))
+	for i, todo := range snapshot.Todos {
+		todos[i] = todo.ToDomain()
+	}
+
+	var ids map[uint]uint
+	err = uc.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		ids, err = uc.todoRepo.Restore(ctx, todos, remapIDs)
+		return err
+	})
+	if err != nil {
+		log.WithError(err).Error("Failed to restore backup")
+		return nil, fmt.Errorf("failed to restore backup: %w", err)
+	}
+
+	log.WithFields(logrus.Fields{
+		"count":     len(todos),
+		"remap_ids": remapIDs,
+	}).Info("Backup restored successfully")
+
+	return &RestoreResult{
+		SchemaVersion: snapshot.SchemaVersion,
+		Restored:      len(todos),
+		IDs:           ids,
+	}, nil
+}
@@ -10,8 +10,11 @@ import (
 type TodoUseCase interface {
 	CreateTodo(ctx context.Context, req dto.CreateTodoRequest) (*domain.Todo, error)
 	GetTodoByID(ctx context.Context, id uint) (*domain.Todo, error)
+	GetTodoByUID(ctx context.Context, uid string) (*domain.Todo, error)
 	GetAllTodos(ctx context.Context, filter domain.TodoFilter) ([]domain.Todo, int64, error)
 	UpdateTodo(ctx context.Context, id uint, req dto.UpdateTodoRequest) (*domain.Todo, error)
 	DeleteTodo(ctx context.Context, id uint) error
 	ToggleTodoComplete(ctx context.Context, id uint) (*domain.Todo, error)
+	IterateTodos(ctx context.Context, filter domain.TodoFilter, fn func(todo *domain.Todo) error) error
+	ImportTodo(ctx context.Context, todo domain.Todo) (*domain.Todo, bool, error)
 }
@@ -7,22 +7,31 @@ import (
 
 	"github.com/rod1kutzyy/OnTrack/internal/domain"
 	"github.com/rod1kutzyy/OnTrack/internal/dto"
+	"github.com/rod1kutzyy/OnTrack/internal/event"
 	"github.com/rod1kutzyy/OnTrack/internal/logger"
 	"github.com/rod1kutzyy/OnTrack/internal/repository"
+	"github.com/sirupsen/logrus"
 )
 
+const iterateBatchSize = 100
+
 type todoUseCase struct {
-	todoRepo repository.TodoRepository
+	todoRepo   repository.TodoRepository
+	transactor repository.Transactor
+	publisher  event.Publisher
 }
 
-func NewTodoUseCase(todoRepo repository.TodoRepository) TodoUseCase {
+func NewTodoUseCase(todoRepo repository.TodoRepository, transactor repository.Transactor, publisher event.Publisher) TodoUseCase {
 	return &todoUseCase{
-		todoRepo: todoRepo,
+		todoRepo:   todoRepo,
+		transactor: transactor,
+		publisher:  publisher,
 	}
 }
 
 func (uc *todoUseCase) CreateTodo(ctx context.Context, req dto.CreateTodoRequest) (*domain.Todo, error) {
-	logger.Logger.WithField("title", req.Title).Info("Creating new todo")
+	log := logger.FromContext(ctx)
+	log.WithField("title", req.Title).Info("Creating new todo")
 
 	title := strings.TrimSpace(req.Title)
 	if title == "" {
@@ -44,112 +53,288 @@ func (uc *todoUseCase) CreateTodo(ctx context.Context, req dto.CreateTodoRequest
 	}
 
 	if err := uc.todoRepo.Create(ctx, todo); err != nil {
-		logger.Logger.WithError(err).Error("Failed to create todo")
+		log.WithError(err).Error("Failed to create todo")
 		return nil, fmt.Errorf("failed to create todo: %w", err)
 	}
 
-	logger.Logger.WithField("id", todo.ID).Info("Todo created successfully")
+	uc.publisher.Publish(ctx, event.NewTodoEvent(event.TodoCreated, *todo))
+
+	log.WithField("id", todo.ID).Info("Todo created successfully")
 	return todo, nil
 }
 
 func (uc *todoUseCase) GetTodoByID(ctx context.Context, id uint) (*domain.Todo, error) {
-	logger.Logger.WithField("id", id).Debug("Fetching todo by ID")
+	log := logger.FromContext(ctx)
+	log.WithField("id", id).Debug("Fetching todo by ID")
 
 	todo, err := uc.todoRepo.GetByID(ctx, id)
 	if err != nil {
-		logger.Logger.WithError(err).WithField("id", id).Warn("Todo not found")
+		log.WithError(err).WithField("id", id).Warn("Todo not found")
 		return nil, err
 	}
 
 	return todo, nil
 }
 
+// GetTodoByUID looks a todo up by the UID it was imported with, falling back
+// to UIDs generated from the IDs of todos that have no imported UID.
+func (uc *todoUseCase) GetTodoByUID(ctx context.Context, uid string) (*domain.Todo, error) {
+	log := logger.FromContext(ctx)
+	log.WithField("uid", uid).Debug("Fetching todo by UID")
+
+	todo, err := uc.todoRepo.GetByUID(ctx, uid)
+	if err == nil {
+		return todo, nil
+	}
+
+	if !strings.Contains(err.Error(), "not found") {
+		log.WithError(err).WithField("uid", uid).Error("Failed to fetch todo by UID")
+		return nil, err
+	}
+
+	if id, ok := domain.ParseGeneratedUID(uid); ok {
+		todo, getErr := uc.GetTodoByID(ctx, id)
+		if getErr != nil {
+			return nil, getErr
+		}
+		// A todo imported with a UID of its own is exported with that one.
+		if todo.CalendarUID() == uid {
+			return todo, nil
+		}
+	}
+
+	return nil, err
+}
+
 func (uc *todoUseCase) GetAllTodos(ctx context.Context, filter domain.TodoFilter) ([]domain.Todo, int64, error) {
-	logger.Logger.WithField("filter", filter).Debug("Fetching todos with filter")
+	log := logger.FromContext(ctx)
+	log.WithFields(filterFields(filter)).Debug("Fetching todos with filter")
 
 	filter.Validate()
 
 	todos, err := uc.todoRepo.GetAll(ctx, filter)
 	if err != nil {
-		logger.Logger.WithError(err).Error("Failed to fetch todos")
+		log.WithError(err).Error("Failed to fetch todos")
 		return nil, 0, fmt.Errorf("failed to fetch todos: %w", err)
 	}
 
 	count, err := uc.todoRepo.Count(ctx, filter)
 	if err != nil {
-		logger.Logger.WithError(err).Error("Failed to count todos")
+		log.WithError(err).Error("Failed to count todos")
 		return nil, 0, fmt.Errorf("failed to count todos: %w", err)
 	}
 
-	logger.Logger.WithField("count", len(todos)).Debug("Todos fetched successfully")
+	log.WithField("count", len(todos)).Debug("Todos fetched successfully")
 	return todos, count, nil
 }
 
 func (uc *todoUseCase) UpdateTodo(ctx context.Context, id uint, req dto.UpdateTodoRequest) (*domain.Todo, error) {
-	logger.Logger.WithField("id", id).Info("Updating todo")
+	log := logger.FromContext(ctx)
+	log.WithField("id", id).Info("Updating todo")
 
-	todo, err := uc.todoRepo.GetByID(ctx, id)
-	if err != nil {
-		return nil, err
-	}
+	update := domain.TodoUpdate{Completed: req.Completed}
 
 	if req.Title != nil {
 		title := strings.TrimSpace(*req.Title)
 		if title == "" {
 			return nil, fmt.Errorf("title cannot be empty")
 		}
-		todo.Title = title
+		update.Title = &title
 	}
 
 	if req.Description != nil {
 		trimmedDescription := strings.TrimSpace(*req.Description)
-		if trimmedDescription == "" {
-			todo.Description = nil
-		} else {
-			todo.Description = &trimmedDescription
-		}
-	}
-
-	if req.Completed != nil {
-		todo.Completed = *req.Completed
+		update.Description = &trimmedDescription
 	}
 
-	if err := uc.todoRepo.Update(ctx, todo); err != nil {
-		logger.Logger.WithError(err).Error("Failed to update todo")
+	todo, err := uc.todoRepo.UpdateFields(ctx, id, update)
+	if err != nil {
+		log.WithError(err).Error("Failed to update todo")
 		return nil, fmt.Errorf("failed to update todo: %w", err)
 	}
 
-	logger.Logger.WithField("id", id).Info("Todo updated successfully")
+	uc.publisher.Publish(ctx, event.NewTodoEvent(event.TodoUpdated, *todo))
+
+	log.WithField("id", id).Info("Todo updated successfully")
 	return todo, nil
 }
 
 func (uc *todoUseCase) DeleteTodo(ctx context.Context, id uint) error {
-	logger.Logger.WithField("id", id).Info("Deleting todo")
+	log := logger.FromContext(ctx)
+	log.WithField("id", id).Info("Deleting todo")
 
 	if err := uc.todoRepo.Delete(ctx, id); err != nil {
-		logger.Logger.WithError(err).Error("Failed to delete todo")
+		log.WithError(err).Error("Failed to delete todo")
 		return fmt.Errorf("failed to delete todo: %w", err)
 	}
 
-	logger.Logger.WithField("id", id).Info("Todo deleted successfully")
+	uc.publisher.Publish(ctx, event.NewTodoEvent(event.TodoDeleted, domain.Todo{ID: id}))
+
+	log.WithField("id", id).Info("Todo deleted successfully")
 	return nil
 }
 
 func (uc *todoUseCase) ToggleTodoComplete(ctx context.Context, id uint) (*domain.Todo, error) {
-	logger.Logger.WithField("id", id).Info("Toggling todo completion status")
+	log := logger.FromContext(ctx)
+	log.WithField("id", id).Info("Toggling todo completion status")
 
-	todo, err := uc.todoRepo.GetByID(ctx, id)
+	todo, err := uc.todoRepo.ToggleCompleted(ctx, id)
+	if err != nil {
+		log.WithError(err).Error("Failed to toggle todo completion")
+		return nil, fmt.Errorf("failed to toggle todo completion: %w", err)
+	}
+
+	uc.publisher.Publish(ctx, event.NewTodoEvent(event.TodoUpdated, *todo))
+
+	log.WithField("id", id).WithField("completed", todo.Completed).Info("Todo completion status toggled")
+	return todo, nil
+}
+
+// IterateTodos calls fn for every todo matching the filter in ID order,
+// fetching them in batches so that callers can stream arbitrarily large
+// result sets. Batches continue after the last ID seen, so todos written
+// meanwhile are neither skipped nor repeated. The filter's Limit is used as
+// the batch size and its Offset is ignored.
+func (uc *todoUseCase) IterateTodos(ctx context.Context, filter domain.TodoFilter, fn func(todo *domain.Todo) error) error {
+	log := logger.FromContext(ctx)
+	log.WithFields(filterFields(filter)).Debug("Iterating todos with filter")
+
+	if filter.Limit <= 0 {
+		filter.Limit = iterateBatchSize
+	}
+	filter.Offset = 0
+	filter.Validate()
+
+	var afterID uint
+	for {
+		todos, err := uc.todoRepo.ListAfterID(ctx, afterID, filter)
+		if err != nil {
+			log.WithError(err).Error("Failed to fetch todos")
+			return fmt.Errorf("failed to fetch todos: %w", err)
+		}
+
+		for i := range todos {
+			if err := fn(&todos[i]); err != nil {
+				return err
+			}
+		}
+
+		if len(todos) < filter.Limit {
+			return nil
+		}
+
+		afterID = todos[len(todos)-1].ID
+	}
+}
+
+// ImportTodo stores a todo coming from an external source, preserving its
+// completion status and creation time when they are set. A todo with a UID
+// replaces the title, description and completion status of the todo found by
+// GetTodoByUID, if any; created reports whether a new todo was stored. The
+// lookup and the write run in one transaction, so that concurrent imports of
+// the same UID do not both create it.
+func (uc *todoUseCase) ImportTodo(ctx context.Context, todo domain.Todo) (*domain.Todo, bool, error) {
+	log := logger.FromContext(ctx)
+	log.WithField("title", todo.Title).Info("Importing todo")
+
+	todo.ID = 0
+	todo.Title = strings.TrimSpace(todo.Title)
+	if todo.Title == "" {
+		return nil, false, fmt.Errorf("title cannot be empty or contain only spaces")
+	}
+
+	if todo.Description != nil {
+		trimmedDescription := strings.TrimSpace(*todo.Description)
+		if trimmedDescription == "" {
+			todo.Description = nil
+		} else {
+			todo.Description = &trimmedDescription
+		}
+	}
+
+	var (
+		imported *domain.Todo
+		created  bool
+	)
+	err := uc.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		existing, err := uc.findImported(ctx, todo.UID)
+		if err != nil {
+			return err
+		}
+
+		if existing == nil {
+			// A UID generated from an ID that matches no todo, e.g. of a
+			// deleted one, is dropped. Stored, it could equal the UID
+			// generated for the todo with that ID.
+			if todo.UID != nil {
+				if _, ok := domain.ParseGeneratedUID(*todo.UID); ok {
+					todo.UID = nil
+				}
+			}
+
+			if err := uc.todoRepo.Create(ctx, &todo); err != nil {
+				return err
+			}
+			imported, created = &todo, true
+			return nil
+		}
+
+		description := ""
+		if todo.Description != nil {
+			description = *todo.Description
+		}
+
+		imported, err = uc.todoRepo.UpdateFields(ctx, existing.ID, domain.TodoUpdate{
+			Title:       &todo.Title,
+			Description: &description,
+			Completed:   &todo.Completed,
+		})
+		return err
+	})
 	if err != nil {
+		log.WithError(err).Error("Failed to import todo")
+		return nil, false, fmt.Errorf("failed to import todo: %w", err)
+	}
+
+	if created {
+		uc.publisher.Publish(ctx, event.NewTodoEvent(event.TodoCreated, *imported))
+	} else {
+		uc.publisher.Publish(ctx, event.NewTodoEvent(event.TodoUpdated, *imported))
+	}
+
+	log.WithField("id", imported.ID).WithField("created", created).Info("Todo imported successfully")
+	return imported, created, nil
+}
+
+// findImported returns the todo a UID was imported with, locked until the
+// transaction in ctx ends, or nil when uid is nil or unknown.
+func (uc *todoUseCase) findImported(ctx context.Context, uid *string) (*domain.Todo, error) {
+	if uid == nil {
+		return nil, nil
+	}
+
+	existing, err := uc.GetTodoByUID(ctx, *uid)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, nil
+		}
 		return nil, err
 	}
 
-	todo.Completed = !todo.Completed
+	return uc.todoRepo.GetByIDForUpdate(ctx, existing.ID)
+}
 
-	if err := uc.todoRepo.Update(ctx, todo); err != nil {
-		logger.Logger.WithError(err).Error("Failed to toggle todo completion")
-		return nil, fmt.Errorf("failed to toggle todo completion: %w", err)
+// filterFields logs the filter field by field, so that redaction can mask the
+// search term.
+func filterFields(filter domain.TodoFilter) logrus.Fields {
+	fields := logrus.Fields{
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+		"search": filter.Search,
+	}
+	if filter.Completed != nil {
+		fields["completed"] = *filter.Completed
 	}
 
-	logger.Logger.WithField("id", id).WithField("completed", todo.Completed).Info("Todo completion status toggled")
-	return todo, nil
+	return fields
 }
@@ -0,0 +1,910 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rod1kutzyy/OnTrack/internal/domain"
+	"github.com/rod1kutzyy/OnTrack/internal/dto"
+	"github.com/rod1kutzyy/OnTrack/internal/event"
+	"github.com/rod1kutzyy/OnTrack/internal/logger"
+	"github.com/rod1kutzyy/OnTrack/internal/repository"
+	"github.com/rod1kutzyy/OnTrack/internal/repository/memory"
+	"github.com/rod1kutzyy/OnTrack/internal/usecase"
+)
+
+func TestMain(m *testing.M) {
+	if err := logger.InitWithOutput("error", io.Discard); err != nil {
+		panic(err)
+	}
+
+	os.Exit(m.Run())
+}
+
+var errDatabase = errors.New("connection refused")
+
+// fakeRepository stores todos in memory and fails the methods named in
+// failing, so that tests can exercise the error paths of the use case.
+// readDelay widens the window between reading and writing a todo.
+type fakeRepository struct {
+	repository.TodoRepository
+	transactor repository.Transactor
+	failing    map[string]bool
+	filters    []domain.TodoFilter
+	readDelay  time.Duration
+}
+
+func newFakeRepository(todos ...domain.Todo) *fakeRepository {
+	store := memory.NewTodoRepository()
+	repo := &fakeRepository{
+		TodoRepository: store,
+		transactor:     memory.NewTransactor(store),
+		failing:        make(map[string]bool),
+	}
+
+	for _, todo := range todos {
+		if err := repo.TodoRepository.Create(context.Background(), &todo); err != nil {
+			panic(err)
+		}
+	}
+
+	return repo
+}
+
+func (r *fakeRepository) fail(method string) error {
+	if r.failing[method] {
+		return errDatabase
+	}
+	return nil
+}
+
+func (r *fakeRepository) Create(ctx context.Context, todo *domain.Todo) error {
+	if err := r.fail("Create"); err != nil {
+		return err
+	}
+	return r.TodoRepository.Create(ctx, todo)
+}
+
+func (r *fakeRepository) GetByID(ctx context.Context, id uint) (*domain.Todo, error) {
+	if err := r.fail("GetByID"); err != nil {
+		return nil, err
+	}
+	return r.TodoRepository.GetByID(ctx, id)
+}
+
+func (r *fakeRepository) GetByIDForUpdate(ctx context.Context, id uint) (*domain.Todo, error) {
+	if err := r.fail("GetByID"); err != nil {
+		return nil, err
+	}
+	todo, err := r.TodoRepository.GetByIDForUpdate(ctx, id)
+	time.Sleep(r.readDelay)
+	return todo, err
+}
+
+func (r *fakeRepository) GetByUID(ctx context.Context, uid string) (*domain.Todo, error) {
+	if err := r.fail("GetByUID"); err != nil {
+		return nil, err
+	}
+	todo, err := r.TodoRepository.GetByUID(ctx, uid)
+	time.Sleep(r.readDelay)
+	return todo, err
+}
+
+func (r *fakeRepository) GetAll(ctx context.Context, filter domain.TodoFilter) ([]domain.Todo, error) {
+	r.filters = append(r.filters, filter)
+
+	if err := r.fail("GetAll"); err != nil {
+		return nil, err
+	}
+	return r.TodoRepository.GetAll(ctx, filter)
+}
+
+func (r *fakeRepository) ListAfterID(ctx context.Context, afterID uint, filter domain.TodoFilter) ([]domain.Todo, error) {
+	r.filters = append(r.filters, filter)
+
+	if err := r.fail("ListAfterID"); err != nil {
+		return nil, err
+	}
+	return r.TodoRepository.ListAfterID(ctx, afterID, filter)
+}
+
+func (r *fakeRepository) Update(ctx context.Context, todo *domain.Todo) error {
+	if err := r.fail("Update"); err != nil {
+		return err
+	}
+	return r.TodoRepository.Update(ctx, todo)
+}
+
+func (r *fakeRepository) UpdateFields(ctx context.Context, id uint, update domain.TodoUpdate) (*domain.Todo, error) {
+	if err := r.fail("UpdateFields"); err != nil {
+		return nil, err
+	}
+	return r.TodoRepository.UpdateFields(ctx, id, update)
+}
+
+func (r *fakeRepository) ToggleCompleted(ctx context.Context, id uint) (*domain.Todo, error) {
+	if err := r.fail("ToggleCompleted"); err != nil {
+		return nil, err
+	}
+	return r.TodoRepository.ToggleCompleted(ctx, id)
+}
+
+func (r *fakeRepository) Delete(ctx context.Context, id uint) error {
+	if err := r.fail("Delete"); err != nil {
+		return err
+	}
+	return r.TodoRepository.Delete(ctx, id)
+}
+
+func (r *fakeRepository) Count(ctx context.Context, filter domain.TodoFilter) (int64, error) {
+	if err := r.fail("Count"); err != nil {
+		return 0, err
+	}
+	return r.TodoRepository.Count(ctx, filter)
+}
+
+type recordingPublisher struct {
+	events []event.TodoEvent
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, e event.TodoEvent) {
+	p.events = append(p.events, e)
+}
+
+func (p *recordingPublisher) types() []event.TodoEventType {
+	var types []event.TodoEventType
+	for _, e := range p.events {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestCreateTodo(t *testing.T) {
+	tests := []struct {
+		name            string
+		req             dto.CreateTodoRequest
+		failing         string
+		wantErr         string
+		wantTitle       string
+		wantDescription *string
+	}{
+		{
+			name:      "trims title",
+			req:       dto.CreateTodoRequest{Title: "  Buy milk  "},
+			wantTitle: "Buy milk",
+		},
+		{
+			name:            "trims description",
+			req:             dto.CreateTodoRequest{Title: "Buy milk", Description: ptr(" 2 litres ")},
+			wantTitle:       "Buy milk",
+			wantDescription: ptr("2 litres"),
+		},
+		{
+			name:      "drops blank description",
+			req:       dto.CreateTodoRequest{Title: "Buy milk", Description: ptr("   ")},
+			wantTitle: "Buy milk",
+		},
+		{
+			name:    "rejects blank title",
+			req:     dto.CreateTodoRequest{Title: " \t "},
+			wantErr: "title cannot be empty",
+		},
+		{
+			name:    "repository error",
+			req:     dto.CreateTodoRequest{Title: "Buy milk"},
+			failing: "Create",
+			wantErr: "failed to create todo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepository()
+			repo.failing[tt.failing] = true
+			publisher := &recordingPublisher{}
+			uc := usecase.NewTodoUseCase(repo, repo.transactor, publisher)
+
+			todo, err := uc.CreateTodo(context.Background(), tt.req)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("CreateTodo() error = %v, want %q", err, tt.wantErr)
+				}
+				if len(publisher.events) != 0 {
+					t.Errorf("published %v after a failed create", publisher.types())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateTodo() failed: %v", err)
+			}
+
+			if todo.ID == 0 || todo.Completed {
+				t.Errorf("CreateTodo() = %+v, want a stored incomplete todo", todo)
+			}
+			if todo.Title != tt.wantTitle {
+				t.Errorf("Title = %q, want %q", todo.Title, tt.wantTitle)
+			}
+			if !equalPtr(todo.Description, tt.wantDescription) {
+				t.Errorf("Description = %v, want %v", deref(todo.Description), deref(tt.wantDescription))
+			}
+
+			stored, err := repo.GetByID(context.Background(), todo.ID)
+			if err != nil {
+				t.Fatalf("todo was not stored: %v", err)
+			}
+			if stored.Title != tt.wantTitle {
+				t.Errorf("stored Title = %q, want %q", stored.Title, tt.wantTitle)
+			}
+
+			if len(publisher.events) != 1 || publisher.events[0].Type != event.TodoCreated || publisher.events[0].Todo.ID != todo.ID {
+				t.Errorf("published %+v, want one %s event for todo %d", publisher.events, event.TodoCreated, todo.ID)
+			}
+		})
+	}
+}
+
+func TestGetTodoByID(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      uint
+		failing string
+		wantErr string
+	}{
+		{name: "found", id: 1},
+		{name: "missing", id: 42, wantErr: "not found"},
+		{name: "repository error", id: 1, failing: "GetByID", wantErr: errDatabase.Error()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepository(domain.Todo{Title: "Buy milk"})
+			repo.failing[tt.failing] = true
+			uc := usecase.NewTodoUseCase(repo, repo.transactor, &recordingPublisher{})
+
+			todo, err := uc.GetTodoByID(context.Background(), tt.id)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("GetTodoByID() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetTodoByID() failed: %v", err)
+			}
+			if todo.ID != tt.id || todo.Title != "Buy milk" {
+				t.Errorf("GetTodoByID() = %+v", todo)
+			}
+		})
+	}
+}
+
+func TestGetTodoByUID(t *testing.T) {
+	tests := []struct {
+		name    string
+		uid     string
+		failing string
+		wantID  uint
+		wantErr string
+	}{
+		{name: "imported uid", uid: "imported@example.com", wantID: 1},
+		{name: "generated uid", uid: (&domain.Todo{ID: 2}).CalendarUID(), wantID: 2},
+		{name: "generated uid of missing todo", uid: (&domain.Todo{ID: 42}).CalendarUID(), wantErr: "not found"},
+		{name: "generated uid of imported todo", uid: (&domain.Todo{ID: 1}).CalendarUID(), wantErr: "not found"},
+		{name: "unknown uid", uid: "unknown@example.com", wantErr: "not found"},
+		{name: "repository error", uid: "imported@example.com", failing: "GetByUID", wantErr: errDatabase.Error()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepository(
+				domain.Todo{Title: "Imported", UID: ptr("imported@example.com")},
+				domain.Todo{Title: "Created here"},
+			)
+			repo.failing[tt.failing] = true
+			uc := usecase.NewTodoUseCase(repo, repo.transactor, &recordingPublisher{})
+
+			todo, err := uc.GetTodoByUID(context.Background(), tt.uid)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("GetTodoByUID() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetTodoByUID() failed: %v", err)
+			}
+			if todo.ID != tt.wantID {
+				t.Errorf("GetTodoByUID() returned todo %d, want %d", todo.ID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestGetAllTodos(t *testing.T) {
+	var seed []domain.Todo
+	for i := range 7 {
+		seed = append(seed, domain.Todo{
+			Title:     fmt.Sprintf("Todo %d", i+1),
+			Completed: i%2 == 0,
+			CreatedAt: time.Date(2024, 1, i+1, 0, 0, 0, 0, time.UTC),
+		})
+	}
+
+	tests := []struct {
+		name      string
+		filter    domain.TodoFilter
+		failing   string
+		wantLimit int
+		wantIDs   []uint
+		wantTotal int64
+		wantErr   string
+	}{
+		{
+			name:      "defaults limit",
+			filter:    domain.TodoFilter{},
+			wantLimit: 5,
+			wantIDs:   []uint{7, 6, 5, 4, 3},
+			wantTotal: 7,
+		},
+		{
+			name:      "caps limit",
+			filter:    domain.TodoFilter{Limit: 1000},
+			wantLimit: 100,
+			wantIDs:   []uint{7, 6, 5, 4, 3, 2, 1},
+			wantTotal: 7,
+		},
+		{
+			name:      "filters and pages",
+			filter:    domain.TodoFilter{Completed: ptr(true), Limit: 2, Offset: 2},
+			wantLimit: 2,
+			wantIDs:   []uint{3, 1},
+			wantTotal: 4,
+		},
+		{
+			name:      "total ignores paging",
+			filter:    domain.TodoFilter{Search: "todo 1", Limit: 2, Offset: 5},
+			wantLimit: 2,
+			wantTotal: 1,
+		},
+		{
+			name:    "list error",
+			failing: "GetAll",
+			wantErr: "failed to fetch todos",
+		},
+		{
+			name:    "count error",
+			failing: "Count",
+			wantErr: "failed to count todos",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepository(seed...)
+			repo.failing[tt.failing] = true
+			uc := usecase.NewTodoUseCase(repo, repo.transactor, &recordingPublisher{})
+
+			todos, total, err := uc.GetAllTodos(context.Background(), tt.filter)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !errors.Is(err, errDatabase) {
+					t.Fatalf("GetAllTodos() error = %v, want %q wrapping %v", err, tt.wantErr, errDatabase)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetAllTodos() failed: %v", err)
+			}
+
+			if got := repo.filters[0].Limit; got != tt.wantLimit {
+				t.Errorf("repository was asked for %d todos, want %d", got, tt.wantLimit)
+			}
+			if got := ids(todos); !slices.Equal(got, tt.wantIDs) {
+				t.Errorf("GetAllTodos() returned todos %v, want %v", got, tt.wantIDs)
+			}
+			if total != tt.wantTotal {
+				t.Errorf("total = %d, want %d", total, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestUpdateTodo(t *testing.T) {
+	tests := []struct {
+		name            string
+		id              uint
+		req             dto.UpdateTodoRequest
+		failing         string
+		wantErr         string
+		wantTitle       string
+		wantDescription *string
+		wantCompleted   bool
+	}{
+		{
+			name:            "no changes",
+			id:              1,
+			wantTitle:       "Buy milk",
+			wantDescription: ptr("2 litres"),
+		},
+		{
+			name:            "title only",
+			id:              1,
+			req:             dto.UpdateTodoRequest{Title: ptr("  Buy oat milk ")},
+			wantTitle:       "Buy oat milk",
+			wantDescription: ptr("2 litres"),
+		},
+		{
+			name:      "clears description",
+			id:        1,
+			req:       dto.UpdateTodoRequest{Description: ptr("  ")},
+			wantTitle: "Buy milk",
+		},
+		{
+			name:            "completes",
+			id:              1,
+			req:             dto.UpdateTodoRequest{Completed: ptr(true)},
+			wantTitle:       "Buy milk",
+			wantDescription: ptr("2 litres"),
+			wantCompleted:   true,
+		},
+		{
+			name:    "rejects blank title",
+			id:      1,
+			req:     dto.UpdateTodoRequest{Title: ptr(" ")},
+			wantErr: "title cannot be empty",
+		},
+		{
+			name:    "missing",
+			id:      42,
+			req:     dto.UpdateTodoRequest{Completed: ptr(true)},
+			wantErr: "not found",
+		},
+		{
+			name:    "repository error",
+			id:      1,
+			req:     dto.UpdateTodoRequest{Completed: ptr(true)},
+			failing: "UpdateFields",
+			wantErr: "failed to update todo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepository(domain.Todo{Title: "Buy milk", Description: ptr("2 litres")})
+			repo.failing[tt.failing] = true
+			publisher := &recordingPublisher{}
+			uc := usecase.NewTodoUseCase(repo, repo.transactor, publisher)
+
+			todo, err := uc.UpdateTodo(context.Background(), tt.id, tt.req)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("UpdateTodo() error = %v, want %q", err, tt.wantErr)
+				}
+				if len(publisher.events) != 0 {
+					t.Errorf("published %v after a failed update", publisher.types())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateTodo() failed: %v", err)
+			}
+
+			stored, err := repo.GetByID(context.Background(), tt.id)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, got := range []*domain.Todo{todo, stored} {
+				if got.Title != tt.wantTitle || !equalPtr(got.Description, tt.wantDescription) || got.Completed != tt.wantCompleted {
+					t.Errorf("todo = {%q %v %t}, want {%q %v %t}",
+						got.Title, deref(got.Description), got.Completed,
+						tt.wantTitle, deref(tt.wantDescription), tt.wantCompleted)
+				}
+			}
+
+			if got := publisher.types(); len(got) != 1 || got[0] != event.TodoUpdated {
+				t.Errorf("published %v, want [%s]", got, event.TodoUpdated)
+			}
+		})
+	}
+}
+
+func TestDeleteTodo(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      uint
+		failing string
+		wantErr string
+	}{
+		{name: "deletes", id: 1},
+		{name: "missing", id: 42, wantErr: "not found"},
+		{name: "repository error", id: 1, failing: "Delete", wantErr: "failed to delete todo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepository(domain.Todo{Title: "Buy milk"})
+			repo.failing[tt.failing] = true
+			publisher := &recordingPublisher{}
+			uc := usecase.NewTodoUseCase(repo, repo.transactor, publisher)
+
+			err := uc.DeleteTodo(context.Background(), tt.id)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("DeleteTodo() error = %v, want %q", err, tt.wantErr)
+				}
+				if len(publisher.events) != 0 {
+					t.Errorf("published %v after a failed delete", publisher.types())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DeleteTodo() failed: %v", err)
+			}
+
+			if _, err := repo.GetByID(context.Background(), tt.id); err == nil {
+				t.Error("todo still exists after DeleteTodo()")
+			}
+			if len(publisher.events) != 1 || publisher.events[0].Type != event.TodoDeleted || publisher.events[0].Todo.ID != tt.id {
+				t.Errorf("published %+v, want one %s event for todo %d", publisher.events, event.TodoDeleted, tt.id)
+			}
+		})
+	}
+}
+
+func TestToggleTodoComplete(t *testing.T) {
+	tests := []struct {
+		name          string
+		completed     bool
+		id            uint
+		failing       string
+		wantCompleted bool
+		wantErr       string
+	}{
+		{name: "completes", id: 1, wantCompleted: true},
+		{name: "reopens", id: 1, completed: true, wantCompleted: false},
+		{name: "missing", id: 42, wantErr: "not found"},
+		{name: "repository error", id: 1, failing: "ToggleCompleted", wantErr: "failed to toggle todo completion"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepository(domain.Todo{Title: "Buy milk", Completed: tt.completed})
+			repo.failing[tt.failing] = true
+			publisher := &recordingPublisher{}
+			uc := usecase.NewTodoUseCase(repo, repo.transactor, publisher)
+
+			todo, err := uc.ToggleTodoComplete(context.Background(), tt.id)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ToggleTodoComplete() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ToggleTodoComplete() failed: %v", err)
+			}
+
+			stored, err := repo.GetByID(context.Background(), tt.id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if todo.Completed != tt.wantCompleted || stored.Completed != tt.wantCompleted {
+				t.Errorf("Completed = %t, stored %t, want %t", todo.Completed, stored.Completed, tt.wantCompleted)
+			}
+			if got := publisher.types(); len(got) != 1 || got[0] != event.TodoUpdated {
+				t.Errorf("published %v, want [%s]", got, event.TodoUpdated)
+			}
+		})
+	}
+}
+
+func TestConcurrentToggles(t *testing.T) {
+	repo := newFakeRepository(domain.Todo{Title: "Buy milk"})
+	uc := usecase.NewTodoUseCase(repo, repo.transactor, event.NewBroker())
+
+	const toggles = 50
+
+	var wg sync.WaitGroup
+	for range toggles {
+		wg.Go(func() {
+			if _, err := uc.ToggleTodoComplete(context.Background(), 1); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+
+	todo, err := repo.GetByID(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if todo.Completed {
+		t.Errorf("todo is completed after %d toggles, an update was lost", toggles)
+	}
+}
+
+func TestIterateTodos(t *testing.T) {
+	var seed []domain.Todo
+	for i := range 250 {
+		seed = append(seed, domain.Todo{
+			Title:     fmt.Sprintf("Todo %d", i+1),
+			Completed: i%5 == 0,
+			CreatedAt: time.Date(2024, 1, 1, 0, i, 0, 0, time.UTC),
+		})
+	}
+
+	stop := errors.New("stop")
+
+	tests := []struct {
+		name        string
+		filter      domain.TodoFilter
+		stopAfter   int
+		failing     string
+		wantCount   int
+		wantBatches int
+		wantErr     error
+	}{
+		{name: "all todos in default batches", wantCount: 250, wantBatches: 3},
+		{name: "batch size from limit", filter: domain.TodoFilter{Limit: 50}, wantCount: 250, wantBatches: 6},
+		{name: "ignores offset", filter: domain.TodoFilter{Limit: 100, Offset: 200}, wantCount: 250, wantBatches: 3},
+		{name: "exact multiple needs a final empty batch", filter: domain.TodoFilter{Completed: ptr(true), Limit: 25}, wantCount: 50, wantBatches: 3},
+		{name: "callback error stops iteration", stopAfter: 10, wantCount: 10, wantBatches: 1, wantErr: stop},
+		{name: "repository error", failing: "ListAfterID", wantBatches: 1, wantErr: errDatabase},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepository(seed...)
+			repo.failing[tt.failing] = true
+			uc := usecase.NewTodoUseCase(repo, repo.transactor, &recordingPublisher{})
+
+			seen := make(map[uint]bool)
+			err := uc.IterateTodos(context.Background(), tt.filter, func(todo *domain.Todo) error {
+				if seen[todo.ID] {
+					t.Errorf("todo %d visited twice", todo.ID)
+				}
+				seen[todo.ID] = true
+
+				if len(seen) == tt.stopAfter {
+					return stop
+				}
+				return nil
+			})
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("IterateTodos() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(seen) != tt.wantCount {
+				t.Errorf("visited %d todos, want %d", len(seen), tt.wantCount)
+			}
+			if len(repo.filters) != tt.wantBatches {
+				t.Errorf("fetched %d batches, want %d", len(repo.filters), tt.wantBatches)
+			}
+		})
+	}
+}
+
+// TestIterateTodosWithConcurrentWrites deletes visited todos and creates new
+// ones while iterating, which shifts the todos that offset paging would see.
+func TestIterateTodosWithConcurrentWrites(t *testing.T) {
+	var seed []domain.Todo
+	for i := range 30 {
+		seed = append(seed, domain.Todo{Title: fmt.Sprintf("Todo %d", i+1)})
+	}
+	repo := newFakeRepository(seed...)
+	uc := usecase.NewTodoUseCase(repo, repo.transactor, &recordingPublisher{})
+
+	var visited []uint
+	err := uc.IterateTodos(context.Background(), domain.TodoFilter{Limit: 10}, func(todo *domain.Todo) error {
+		visited = append(visited, todo.ID)
+		if todo.Title == "Added meanwhile" {
+			return nil
+		}
+		if err := repo.Delete(context.Background(), todo.ID); err != nil {
+			return err
+		}
+		return repo.Create(context.Background(), &domain.Todo{Title: "Added meanwhile"})
+	})
+	if err != nil {
+		t.Fatalf("IterateTodos() failed: %v", err)
+	}
+
+	// The todos added meanwhile come last, as their IDs are higher.
+	var want []uint
+	for id := range uint(2 * len(seed)) {
+		want = append(want, id+1)
+	}
+	if !slices.Equal(visited, want) {
+		t.Errorf("visited %v, want every todo once in ID order", visited)
+	}
+}
+
+func TestImportTodo(t *testing.T) {
+	createdAt := time.Date(2023, 5, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		todo        domain.Todo
+		failing     string
+		wantErr     string
+		wantCreated bool
+		check       func(t *testing.T, todo *domain.Todo)
+	}{
+		{
+			name:        "keeps status and creation time",
+			todo:        domain.Todo{ID: 99, Title: " Imported ", Description: ptr(" "), Completed: true, CreatedAt: createdAt, UID: ptr("new@example.com")},
+			wantCreated: true,
+			check: func(t *testing.T, todo *domain.Todo) {
+				if todo.ID != 3 {
+					t.Errorf("ID = %d, want a new ID", todo.ID)
+				}
+				if todo.Title != "Imported" || todo.Description != nil {
+					t.Errorf("todo = {%q %v}, want trimmed fields", todo.Title, deref(todo.Description))
+				}
+				if !todo.Completed || !todo.CreatedAt.Equal(createdAt) || deref(todo.UID) != "new@example.com" {
+					t.Errorf("todo = %+v, want completion, creation time and UID preserved", todo)
+				}
+			},
+		},
+		{
+			name: "updates the todo with the same UID",
+			todo: domain.Todo{Title: "Renamed", Completed: true, UID: ptr("a@example.com")},
+			check: func(t *testing.T, todo *domain.Todo) {
+				if todo.ID != 1 || todo.Title != "Renamed" || todo.Description != nil || !todo.Completed {
+					t.Errorf("todo = %+v, want todo 1 renamed, completed and without description", todo)
+				}
+			},
+		},
+		{
+			name: "updates the todo with a generated UID",
+			todo: domain.Todo{Title: "Renamed", UID: ptr((&domain.Todo{ID: 2}).CalendarUID())},
+			check: func(t *testing.T, todo *domain.Todo) {
+				if todo.ID != 2 || todo.Title != "Renamed" {
+					t.Errorf("todo = %+v, want todo 2 renamed", todo)
+				}
+			},
+		},
+		{
+			name:        "drops a generated UID of a missing todo",
+			todo:        domain.Todo{Title: "Deleted here", UID: ptr((&domain.Todo{ID: 42}).CalendarUID())},
+			wantCreated: true,
+			check: func(t *testing.T, todo *domain.Todo) {
+				if todo.ID != 3 || todo.UID != nil {
+					t.Errorf("todo = %+v, want a new todo without UID", todo)
+				}
+			},
+		},
+		{
+			name:        "drops a generated UID of a todo with an imported UID",
+			todo:        domain.Todo{Title: "Renamed", UID: ptr((&domain.Todo{ID: 1}).CalendarUID())},
+			wantCreated: true,
+			check: func(t *testing.T, todo *domain.Todo) {
+				if todo.ID != 3 || todo.UID != nil {
+					t.Errorf("todo = %+v, want a new todo without UID", todo)
+				}
+			},
+		},
+		{
+			name:    "rejects blank title",
+			todo:    domain.Todo{Title: "  "},
+			wantErr: "title cannot be empty",
+		},
+		{
+			name:    "repository error",
+			todo:    domain.Todo{Title: "Imported", UID: ptr("a@example.com")},
+			failing: "UpdateFields",
+			wantErr: "failed to import todo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &recordingPublisher{}
+			repo := newFakeRepository(
+				domain.Todo{Title: "Buy milk", Description: ptr("2 litres"), UID: ptr("a@example.com")},
+				domain.Todo{Title: "Created here"},
+			)
+			repo.failing[tt.failing] = true
+			uc := usecase.NewTodoUseCase(repo, repo.transactor, publisher)
+
+			todo, created, err := uc.ImportTodo(context.Background(), tt.todo)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ImportTodo() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ImportTodo() failed: %v", err)
+			}
+
+			tt.check(t, todo)
+
+			wantEvent := event.TodoUpdated
+			if tt.wantCreated {
+				wantEvent = event.TodoCreated
+			}
+			if created != tt.wantCreated {
+				t.Errorf("created = %t, want %t", created, tt.wantCreated)
+			}
+			if got := publisher.types(); len(got) != 1 || got[0] != wantEvent {
+				t.Errorf("published %v, want [%s]", got, wantEvent)
+			}
+		})
+	}
+}
+
+// TestConcurrentImports imports the same new UID concurrently. Without the
+// transaction around the lookup several imports would miss the todo and fail
+// to create it a second time.
+func TestConcurrentImports(t *testing.T) {
+	repo := newFakeRepository()
+	repo.readDelay = time.Millisecond
+	uc := usecase.NewTodoUseCase(repo, repo.transactor, event.NewBroker())
+
+	const imports = 20
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		created int
+	)
+	for i := range imports {
+		wg.Go(func() {
+			todo := domain.Todo{Title: fmt.Sprintf("Import %d", i), UID: ptr("same@example.com")}
+			_, wasCreated, err := uc.ImportTodo(context.Background(), todo)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if wasCreated {
+				mu.Lock()
+				created++
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+
+	count, err := repo.Count(context.Background(), domain.TodoFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 || created != 1 {
+		t.Errorf("stored %d todos and created %d, want the UID imported once and updated afterwards", count, created)
+	}
+}
+
+func ids(todos []domain.Todo) []uint {
+	var ids []uint
+	for _, todo := range todos {
+		ids = append(ids, todo.ID)
+	}
+	return ids
+}
+
+func equalPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return "<nil>"
+	}
+	return *s
+}
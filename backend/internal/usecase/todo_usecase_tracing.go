@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/rod1kutzyy/OnTrack/internal/domain"
+	"github.com/rod1kutzyy/OnTrack/internal/dto"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/rod1kutzyy/OnTrack/internal/usecase"
+
+type tracingTodoUseCase struct {
+	next   TodoUseCase
+	tracer trace.Tracer
+}
+
+// NewTracingTodoUseCase records a span for every call to next. Repository
+// calls made by next become children of it through the context.
+func NewTracingTodoUseCase(next TodoUseCase) TodoUseCase {
+	return &tracingTodoUseCase{
+		next:   next,
+		tracer: otel.Tracer(tracerName),
+	}
+}
+
+func (uc *tracingTodoUseCase) CreateTodo(ctx context.Context, req dto.CreateTodoRequest) (*domain.Todo, error) {
+	ctx, span := uc.tracer.Start(ctx, "TodoUseCase.CreateTodo")
+	todo, err := uc.next.CreateTodo(ctx, req)
+	endSpan(span, todo, err)
+	return todo, err
+}
+
+func (uc *tracingTodoUseCase) GetTodoByID(ctx context.Context, id uint) (*domain.Todo, error) {
+	ctx, span := uc.tracer.Start(ctx, "TodoUseCase.GetTodoByID", trace.WithAttributes(todoIDAttribute(id)))
+	todo, err := uc.next.GetTodoByID(ctx, id)
+	endSpan(span, nil, err)
+	return todo, err
+}
+
+func (uc *tracingTodoUseCase) GetTodoByUID(ctx context.Context, uid string) (*domain.Todo, error) {
+	ctx, span := uc.tracer.Start(ctx, "TodoUseCase.GetTodoByUID", trace.WithAttributes(attribute.String("todo.uid", uid)))
+	todo, err := uc.next.GetTodoByUID(ctx, uid)
+	endSpan(span, todo, err)
+	return todo, err
+}
+
+func (uc *tracingTodoUseCase) GetAllTodos(ctx context.Context, filter domain.TodoFilter) ([]domain.Todo, int64, error) {
+	ctx, span := uc.tracer.Start(ctx, "TodoUseCase.GetAllTodos", trace.WithAttributes(filterAttributes(filter)...))
+	todos, total, err := uc.next.GetAllTodos(ctx, filter)
+	span.SetAttributes(attribute.Int("todos.returned", len(todos)), attribute.Int64("todos.total", total))
+	endSpan(span, nil, err)
+	return todos, total, err
+}
+
+func (uc *tracingTodoUseCase) UpdateTodo(ctx context.Context, id uint, req dto.UpdateTodoRequest) (*domain.Todo, error) {
+	ctx, span := uc.tracer.Start(ctx, "TodoUseCase.UpdateTodo", trace.WithAttributes(todoIDAttribute(id)))
+	todo, err := uc.next.UpdateTodo(ctx, id, req)
+	endSpan(span, nil, err)
+	return todo, err
+}
+
+func (uc *tracingTodoUseCase) DeleteTodo(ctx context.Context, id uint) error {
+	ctx, span := uc.tracer.Start(ctx, "TodoUseCase.DeleteTodo", trace.WithAttributes(todoIDAttribute(id)))
+	err := uc.next.DeleteTodo(ctx, id)
+	endSpan(span, nil, err)
+	return err
+}
+
+func (uc *tracingTodoUseCase) ToggleTodoComplete(ctx context.Context, id uint) (*domain.Todo, error) {
+	ctx, span := uc.tracer.Start(ctx, "TodoUseCase.ToggleTodoComplete", trace.WithAttributes(todoIDAttribute(id)))
+	todo, err := uc.next.ToggleTodoComplete(ctx, id)
+	endSpan(span, nil, err)
+	return todo, err
+}
+
+func (uc *tracingTodoUseCase) IterateTodos(ctx context.Context, filter domain.TodoFilter, fn func(todo *domain.Todo) error) error {
+	ctx, span := uc.tracer.Start(ctx, "TodoUseCase.IterateTodos", trace.WithAttributes(filterAttributes(filter)...))
+
+	visited := 0
+	err := uc.next.IterateTodos(ctx, filter, func(todo *domain.Todo) error {
+		visited++
+		return fn(todo)
+	})
+
+	span.SetAttributes(attribute.Int("todos.returned", visited))
+	endSpan(span, nil, err)
+	return err
+}
+
+func (uc *tracingTodoUseCase) ImportTodo(ctx context.Context, todo domain.Todo) (*domain.Todo, bool, error) {
+	ctx, span := uc.tracer.Start(ctx, "TodoUseCase.ImportTodo")
+	imported, created, err := uc.next.ImportTodo(ctx, todo)
+	endSpan(span, imported, err)
+	return imported, created, err
+}
+
+// endSpan records err and, for calls that assign it, the ID of the todo.
+func endSpan(span trace.Span, created *domain.Todo, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else if created != nil {
+		span.SetAttributes(todoIDAttribute(created.ID))
+	}
+
+	span.End()
+}
+
+func todoIDAttribute(id uint) attribute.KeyValue {
+	return attribute.Int64("todo.id", int64(id))
+}
+
+func filterAttributes(filter domain.TodoFilter) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		attribute.Int("filter.limit", filter.Limit),
+		attribute.Int("filter.offset", filter.Offset),
+		attribute.Bool("filter.search", filter.Search != ""),
+	}
+	if filter.Completed != nil {
+		attrs = append(attrs, attribute.Bool("filter.completed", *filter.Completed))
+	}
+
+	return attrs
+}
@@ -0,0 +1,201 @@
+package validator_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rod1kutzyy/OnTrack/internal/dto"
+	"github.com/rod1kutzyy/OnTrack/internal/validator"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// fieldTags flattens errors to "field:tag" so that tests do not depend on
+// the wording of messages.
+func fieldTags(errors []dto.ValidationError) []string {
+	var tags []string
+	for _, err := range errors {
+		tags = append(tags, err.Field+":"+err.Tag)
+	}
+	return tags
+}
+
+func TestValidateCreateTodo(t *testing.T) {
+	tests := []struct {
+		name string
+		req  dto.CreateTodoRequest
+		want []string
+	}{
+		{
+			name: "valid",
+			req:  dto.CreateTodoRequest{Title: "Buy milk", Description: ptr("2 litres, semi-skimmed")},
+		},
+		{
+			name: "valid without description",
+			req:  dto.CreateTodoRequest{Title: "Buy milk!"},
+		},
+		{
+			name: "non-latin title",
+			req:  dto.CreateTodoRequest{Title: "Купить молоко"},
+		},
+		{
+			name: "title with some digits",
+			req:  dto.CreateTodoRequest{Title: "Call 911"},
+		},
+		{
+			name: "blank title",
+			req:  dto.CreateTodoRequest{Title: "   "},
+			want: []string{"title:notblank"},
+		},
+		{
+			name: "digits only",
+			req:  dto.CreateTodoRequest{Title: " 12345 "},
+			want: []string{"title:content_quality"},
+		},
+		{
+			name: "mostly punctuation",
+			req:  dto.CreateTodoRequest{Title: "!!!???"},
+			want: []string{"title:content_quality"},
+		},
+		{
+			name: "punctuation at the limit",
+			req:  dto.CreateTodoRequest{Title: "a-bc"},
+		},
+		{
+			name: "description repeats title",
+			req:  dto.CreateTodoRequest{Title: "Buy milk", Description: ptr("  Buy milk ")},
+			want: []string{"description:unique_content"},
+		},
+		{
+			name: "empty description",
+			req:  dto.CreateTodoRequest{Title: "Buy milk", Description: ptr("")},
+		},
+	}
+
+	v := validator.NewTodoValidator()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fieldTags(v.ValidateCreateTodo(tt.req))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ValidateCreateTodo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateUpdateTodo(t *testing.T) {
+	tests := []struct {
+		name string
+		req  dto.UpdateTodoRequest
+		want []string
+	}{
+		{
+			name: "empty request",
+			req:  dto.UpdateTodoRequest{},
+		},
+		{
+			name: "completion only",
+			req:  dto.UpdateTodoRequest{Completed: ptr(true)},
+		},
+		{
+			name: "valid title and description",
+			req:  dto.UpdateTodoRequest{Title: ptr("Buy milk"), Description: ptr("Buy milk")},
+		},
+		{
+			name: "blank title",
+			req:  dto.UpdateTodoRequest{Title: ptr(" \t")},
+			want: []string{"title:notblank", "title:notblank"},
+		},
+		{
+			name: "title too long",
+			req:  dto.UpdateTodoRequest{Title: ptr(strings.Repeat("a", 256))},
+			want: []string{"title:max"},
+		},
+		{
+			name: "long title counted in characters",
+			req:  dto.UpdateTodoRequest{Title: ptr(strings.Repeat("я", 255))},
+		},
+		{
+			name: "title surrounded by spaces",
+			req:  dto.UpdateTodoRequest{Title: ptr("  " + strings.Repeat("a", 255) + "  ")},
+		},
+		{
+			name: "digits only",
+			req:  dto.UpdateTodoRequest{Title: ptr("2024")},
+			want: []string{"title:content_quality"},
+		},
+		{
+			name: "description too long",
+			req:  dto.UpdateTodoRequest{Description: ptr(strings.Repeat("a", 1001))},
+			want: []string{"description:max"},
+		},
+		{
+			name: "several problems",
+			req:  dto.UpdateTodoRequest{Title: ptr("#$%"), Description: ptr(strings.Repeat("ж", 1001))},
+			want: []string{"title:content_quality", "description:max"},
+		},
+	}
+
+	v := validator.NewTodoValidator()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fieldTags(v.ValidateUpdateTodo(tt.req))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ValidateUpdateTodo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter dto.TodoFilterRequest
+		want   []string
+	}{
+		{
+			name:   "valid",
+			filter: dto.TodoFilterRequest{Page: 1, Limit: 10},
+		},
+		{
+			name:   "bounds",
+			filter: dto.TodoFilterRequest{Page: 1, Limit: 100, Search: strings.Repeat("a", 100)},
+		},
+		{
+			name:   "missing page and limit",
+			filter: dto.TodoFilterRequest{},
+			want:   []string{"page:min", "limit:range"},
+		},
+		{
+			name:   "negative page",
+			filter: dto.TodoFilterRequest{Page: -1, Limit: 10},
+			want:   []string{"page:min"},
+		},
+		{
+			name:   "limit too large",
+			filter: dto.TodoFilterRequest{Page: 1, Limit: 101},
+			want:   []string{"limit:range"},
+		},
+		{
+			name:   "search too long",
+			filter: dto.TodoFilterRequest{Page: 1, Limit: 10, Search: strings.Repeat("a", 101)},
+			want:   []string{"search:max"},
+		},
+	}
+
+	v := validator.NewTodoValidator()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fieldTags(v.ValidateFilter(tt.filter))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ValidateFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/rod1kutzyy/OnTrack/internal/dto"
+)
+
+type RestoreResult = dto.RestoreResponse
+
+// Backup streams a JSON backup of all todos. The caller must close the
+// returned reader. It requires a client created with WithToken.
+func (c *Client) Backup(ctx context.Context) (io.ReadCloser, error) {
+	return c.stream(ctx, c.newRequest(http.MethodGet, "/admin/backup", nil))
+}
+
+// Restore restores a backup created by Backup. Without remapIDs the database
+// must be empty, otherwise the error matches ErrDatabaseNotEmpty. Like
+// ImportTodos it is only retried when backup implements io.Seeker and the
+// server answers 429.
+func (c *Client) Restore(ctx context.Context, backup io.Reader, remapIDs bool) (*RestoreResult, error) {
+	if backup == nil {
+		return nil, errNilBody
+	}
+
+	query := url.Values{}
+	if remapIDs {
+		query.Set("remap_ids", "true")
+	}
+
+	r := c.newRequest(http.MethodPost, "/admin/restore", query)
+	r.stream = backup
+	r.contentType = "application/json"
+
+	var result RestoreResult
+	if err := c.do(ctx, r, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
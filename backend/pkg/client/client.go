@@ -0,0 +1,364 @@
+// Package client is a Go client for the OnTrack REST API.
+//
+//	c, err := client.New("http://localhost:8080", client.WithToken(token))
+//	if err != nil {
+//		return err
+//	}
+//
+//	todo, err := c.CreateTodo(ctx, client.CreateTodoRequest{Title: "Buy milk"})
+//	if errors.Is(err, client.ErrValidation) {
+//		...
+//	}
+//
+// Requests are retried with exponential backoff when the server answers 429,
+// and on 5xx responses and network errors when repeating the request is safe.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	apiPrefix        = "/api/v1"
+	defaultUserAgent = "ontrack-go-client"
+	defaultTimeout   = 30 * time.Second
+)
+
+// RetryPolicy controls how failed requests are retried. The wait before the
+// n-th retry is MinBackoff * 2^(n-1) with jitter, capped at MaxBackoff, or the
+// server's Retry-After if that is longer.
+type RetryPolicy struct {
+	MaxRetries int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy is used unless WithRetryPolicy is given.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	MinBackoff: 200 * time.Millisecond,
+	MaxBackoff: 5 * time.Second,
+}
+
+// Client calls the OnTrack API. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	token      string
+	userAgent  string
+	httpClient *http.Client
+	retry      RetryPolicy
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests. The default client
+// has a 30 second timeout, which is too short for large exports and backups.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithToken sends the token as a Bearer token with every request. It is
+// required by the admin endpoints.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithUserAgent sets the User-Agent header.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy. A policy with MaxRetries 0
+// disables retries.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// New returns a client for the server at baseURL, e.g. "https://ontrack.example.com".
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server URL: %w", err)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid server URL %q: must be an absolute http or https URL", baseURL)
+	}
+
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		userAgent:  defaultUserAgent,
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		retry:      DefaultRetryPolicy,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c, nil
+}
+
+// BaseURL returns the server URL the client was created with.
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// Health checks that the server is up.
+func (c *Client) Health(ctx context.Context) error {
+	return c.probe(ctx, "/health")
+}
+
+// Ready checks that the server is ready to serve requests.
+func (c *Client) Ready(ctx context.Context) error {
+	return c.probe(ctx, "/ready")
+}
+
+func (c *Client) probe(ctx context.Context, path string) error {
+	resp, err := c.send(ctx, &request{method: http.MethodGet, url: c.baseURL + path})
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// request describes an API call. Only one of body and stream is set.
+type request struct {
+	method      string
+	url         string
+	query       url.Values
+	body        []byte
+	stream      io.Reader
+	contentType string
+	accept      string
+}
+
+func (c *Client) newRequest(method, path string, query url.Values) *request {
+	return &request{
+		method: method,
+		url:    c.baseURL + apiPrefix + path,
+		query:  query,
+		accept: "application/json",
+	}
+}
+
+func (r *request) setJSON(body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	r.body = data
+	r.contentType = "application/json"
+
+	return nil
+}
+
+// replayable reports whether the body can be sent again.
+func (r *request) replayable() bool {
+	if r.stream == nil {
+		return true
+	}
+
+	_, ok := r.stream.(io.Seeker)
+	return ok
+}
+
+func (r *request) bodyReader() (io.Reader, error) {
+	if r.stream == nil {
+		if r.body == nil {
+			return nil, nil
+		}
+		return bytes.NewReader(r.body), nil
+	}
+
+	if seeker, ok := r.stream.(io.Seeker); ok {
+		if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("failed to rewind request body: %w", err)
+		}
+	}
+
+	// Hide io.Closer, otherwise the transport closes the caller's file.
+	return struct{ io.Reader }{r.stream}, nil
+}
+
+// idempotent reports whether the request may be repeated after the server
+// has possibly processed it.
+func (r *request) idempotent() bool {
+	switch r.method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return r.replayable()
+	}
+
+	return false
+}
+
+// do sends the request and decodes the data of the success envelope into out.
+func (c *Client) do(ctx context.Context, req *request, out any) error {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("unexpected response from server: %w", err)
+	}
+
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		return fmt.Errorf("unexpected response from server: %w", err)
+	}
+
+	return nil
+}
+
+// send sends the request, retrying according to the retry policy, and
+// returns the first successful response. Error responses are returned as
+// *APIError.
+func (c *Client) send(ctx context.Context, req *request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := c.sendOnce(ctx, req)
+
+		var wait time.Duration
+		retry := attempt < c.retry.MaxRetries && req.replayable()
+
+		switch {
+		case err == nil && resp.StatusCode < http.StatusBadRequest:
+			return resp, nil
+		case err == nil:
+			apiErr := newAPIError(resp)
+			resp.Body.Close()
+
+			err = apiErr
+			retry = retry && apiErr.temporary() && (apiErr.StatusCode == http.StatusTooManyRequests || req.idempotent())
+			wait = apiErr.RetryAfter
+		case ctx.Err() != nil:
+			return nil, ctx.Err()
+		default:
+			retry = retry && req.idempotent()
+		}
+
+		if !retry {
+			return nil, err
+		}
+
+		if err := sleep(ctx, max(c.backoff(attempt), wait)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *Client) sendOnce(ctx context.Context, req *request) (*http.Response, error) {
+	body, err := req.bodyReader()
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := req.url
+	if len(req.query) > 0 {
+		endpoint += "?" + req.query.Encode()
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.accept != "" {
+		httpReq.Header.Set("Accept", req.accept)
+	}
+	if req.contentType != "" {
+		httpReq.Header.Set("Content-Type", req.contentType)
+	}
+	if c.userAgent != "" {
+		httpReq.Header.Set("User-Agent", c.userAgent)
+	}
+	if c.token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", req.method, req.url, err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) backoff(attempt int) time.Duration {
+	if c.retry.MinBackoff <= 0 {
+		return 0
+	}
+
+	wait := c.retry.MinBackoff << attempt
+	if wait <= 0 || (c.retry.MaxBackoff > 0 && wait > c.retry.MaxBackoff) {
+		wait = c.retry.MaxBackoff
+	}
+
+	// Full jitter in the upper half keeps clients that failed together from
+	// retrying together.
+	return wait/2 + rand.N(wait/2+1)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0)
+	}
+
+	return 0
+}
+
+func formatID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+var errNilBody = errors.New("body must not be nil")
@@ -0,0 +1,153 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rod1kutzyy/OnTrack/internal/dto"
+)
+
+// maxErrorBody limits how much of an error response is read.
+const maxErrorBody = 64 << 10
+
+// Errors matched by *APIError with errors.Is. The broad errors (ErrBadRequest,
+// ErrConflict, ...) match on the HTTP status, the specific ones on the error
+// code of the response, so a missing todo matches both ErrNotFound and
+// ErrTodoNotFound.
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrServer       = errors.New("server error")
+
+	ErrValidation        = errors.New("validation failed")
+	ErrInvalidID         = errors.New("invalid todo id")
+	ErrInvalidJSON       = errors.New("invalid request body")
+	ErrTodoNotFound      = errors.New("todo not found")
+	ErrUnsupportedFormat = errors.New("unsupported format")
+	ErrInvalidFile       = errors.New("invalid file")
+	ErrInvalidBackup     = errors.New("invalid backup")
+	ErrDatabaseNotEmpty  = errors.New("database is not empty")
+	ErrAdminDisabled     = errors.New("admin API is disabled")
+)
+
+var codeErrors = map[string]error{
+	"VALIDATION_ERROR":   ErrValidation,
+	"INVALID_ID":         ErrInvalidID,
+	"INVALID_JSON":       ErrInvalidJSON,
+	"TODO_NOT_FOUND":     ErrTodoNotFound,
+	"UNSUPPORTED_FORMAT": ErrUnsupportedFormat,
+	"INVALID_FILE":       ErrInvalidFile,
+	"INVALID_BACKUP":     ErrInvalidBackup,
+	"DATABASE_NOT_EMPTY": ErrDatabaseNotEmpty,
+	"ADMIN_DISABLED":     ErrAdminDisabled,
+	"UNAUTHORIZED":       ErrUnauthorized,
+}
+
+// APIError is an error response of the API, see dto.ErrorResponse.
+type APIError struct {
+	StatusCode int
+	// Code is the machine readable error code, e.g. "TODO_NOT_FOUND". It is
+	// empty for errors that have none.
+	Code    string
+	Message string
+	// Details lists the invalid fields of validation errors.
+	Details []FieldError
+	// RetryAfter is the wait requested by the server, if any.
+	RetryAfter time.Duration
+	// RequestID identifies the request in the server logs; quote it when
+	// reporting a problem.
+	RequestID string
+}
+
+func newAPIError(resp *http.Response) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		RequestID:  resp.Header.Get("X-Request-ID"),
+	}
+
+	var body dto.ErrorResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxErrorBody)).Decode(&body); err == nil {
+		apiErr.Code = body.Code
+		apiErr.Message = body.Message
+		if body.RequestID != "" {
+			apiErr.RequestID = body.RequestID
+		}
+
+		// Details is untyped in dto.ErrorResponse; only validation errors
+		// carry a list of fields.
+		if raw, err := json.Marshal(body.Details); err == nil {
+			json.Unmarshal(raw, &apiErr.Details)
+		}
+	}
+
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+
+	return apiErr
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "ontrack: %s (%d", e.Message, e.StatusCode)
+	if e.Code != "" {
+		fmt.Fprintf(&b, " %s", e.Code)
+	}
+	b.WriteString(")")
+
+	for i, detail := range e.Details {
+		if i == 0 {
+			b.WriteString(": ")
+		} else {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "%s: %s", detail.Field, detail.Message)
+	}
+
+	return b.String()
+}
+
+// Is reports whether target is the error matching the status or code of e.
+func (e *APIError) Is(target error) bool {
+	if e.Code != "" && codeErrors[e.Code] == target {
+		return true
+	}
+
+	return statusError(e.StatusCode) == target
+}
+
+func (e *APIError) temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+func statusError(status int) error {
+	switch {
+	case status == http.StatusBadRequest:
+		return ErrBadRequest
+	case status == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case status == http.StatusForbidden:
+		return ErrForbidden
+	case status == http.StatusNotFound:
+		return ErrNotFound
+	case status == http.StatusConflict:
+		return ErrConflict
+	case status == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case status >= http.StatusInternalServerError:
+		return ErrServer
+	}
+
+	return nil
+}
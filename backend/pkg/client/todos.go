@@ -0,0 +1,173 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/rod1kutzyy/OnTrack/internal/dto"
+)
+
+// MaxPageSize is the largest page the server returns.
+const MaxPageSize = 100
+
+// The API types are shared with the server so that both always agree on the
+// wire format.
+type (
+	Todo              = dto.TodoResponse
+	TodoList          = dto.TodoListResponse
+	Pagination        = dto.PaginationResponse
+	CreateTodoRequest = dto.CreateTodoRequest
+	UpdateTodoRequest = dto.UpdateTodoRequest
+	FieldError        = dto.ValidationError
+)
+
+// ListOptions filters and pages todo lists. Zero values use the server
+// defaults.
+type ListOptions struct {
+	Completed *bool
+	Search    string
+	Page      int
+	Limit     int
+}
+
+func (o ListOptions) query() url.Values {
+	query := url.Values{}
+	if o.Completed != nil {
+		query.Set("completed", strconv.FormatBool(*o.Completed))
+	}
+	if o.Search != "" {
+		query.Set("search", o.Search)
+	}
+	if o.Page > 0 {
+		query.Set("page", strconv.Itoa(o.Page))
+	}
+	if o.Limit > 0 {
+		query.Set("limit", strconv.Itoa(o.Limit))
+	}
+
+	return query
+}
+
+// CreateTodo creates a todo. It is not retried on server errors, since the
+// todo may have been created.
+func (c *Client) CreateTodo(ctx context.Context, req CreateTodoRequest) (*Todo, error) {
+	r := c.newRequest(http.MethodPost, "/todos", nil)
+	if err := r.setJSON(req); err != nil {
+		return nil, err
+	}
+
+	var todo Todo
+	if err := c.do(ctx, r, &todo); err != nil {
+		return nil, err
+	}
+
+	return &todo, nil
+}
+
+func (c *Client) GetTodo(ctx context.Context, id uint) (*Todo, error) {
+	var todo Todo
+	if err := c.do(ctx, c.newRequest(http.MethodGet, "/todos/"+formatID(id), nil), &todo); err != nil {
+		return nil, err
+	}
+
+	return &todo, nil
+}
+
+// ListTodos returns a single page of todos.
+func (c *Client) ListTodos(ctx context.Context, opts ListOptions) (*TodoList, error) {
+	var list TodoList
+	if err := c.do(ctx, c.newRequest(http.MethodGet, "/todos", opts.query()), &list); err != nil {
+		return nil, err
+	}
+
+	return &list, nil
+}
+
+// UpdateTodo changes the fields of the todo that are set in req.
+func (c *Client) UpdateTodo(ctx context.Context, id uint, req UpdateTodoRequest) (*Todo, error) {
+	r := c.newRequest(http.MethodPut, "/todos/"+formatID(id), nil)
+	if err := r.setJSON(req); err != nil {
+		return nil, err
+	}
+
+	var todo Todo
+	if err := c.do(ctx, r, &todo); err != nil {
+		return nil, err
+	}
+
+	return &todo, nil
+}
+
+func (c *Client) DeleteTodo(ctx context.Context, id uint) error {
+	return c.do(ctx, c.newRequest(http.MethodDelete, "/todos/"+formatID(id), nil), nil)
+}
+
+// ToggleTodo flips the completion status of a todo. Like CreateTodo it is not
+// retried on server errors; use UpdateTodo to set a specific status.
+func (c *Client) ToggleTodo(ctx context.Context, id uint) (*Todo, error) {
+	var todo Todo
+	if err := c.do(ctx, c.newRequest(http.MethodPatch, "/todos/"+formatID(id)+"/toggle", nil), &todo); err != nil {
+		return nil, err
+	}
+
+	return &todo, nil
+}
+
+// Pages iterates over the pages of a todo list, starting at opts.Page. An
+// unset opts.Limit fetches MaxPageSize todos per page. Iteration stops after
+// the last page or the first error.
+//
+// Pages are fetched by offset, so todos created or deleted during the
+// iteration can shift a todo into a page that was already returned.
+func (c *Client) Pages(ctx context.Context, opts ListOptions) iter.Seq2[*TodoList, error] {
+	return func(yield func(*TodoList, error) bool) {
+		if opts.Page < 1 {
+			opts.Page = 1
+		}
+		if opts.Limit <= 0 {
+			opts.Limit = MaxPageSize
+		}
+
+		for {
+			list, err := c.ListTodos(ctx, opts)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if !yield(list, nil) || !list.Pagination.HasNext {
+				return
+			}
+
+			opts.Page++
+		}
+	}
+}
+
+// Todos iterates over all todos matching opts, fetching pages as needed:
+//
+//	for todo, err := range c.Todos(ctx, client.ListOptions{}) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func (c *Client) Todos(ctx context.Context, opts ListOptions) iter.Seq2[Todo, error] {
+	return func(yield func(Todo, error) bool) {
+		for list, err := range c.Pages(ctx, opts) {
+			if err != nil {
+				yield(Todo{}, err)
+				return
+			}
+
+			for _, todo := range list.Items {
+				if !yield(todo, nil) {
+					return
+				}
+			}
+		}
+	}
+}
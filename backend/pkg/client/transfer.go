@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/rod1kutzyy/OnTrack/internal/dto"
+)
+
+// Formats supported by ExportTodos and ImportTodos.
+const (
+	FormatCSV      = "csv"
+	FormatICS      = "ics"
+	FormatTodoTxt  = "todotxt"
+	FormatMarkdown = "markdown"
+)
+
+var formatContentTypes = map[string]string{
+	FormatCSV:      "text/csv",
+	FormatICS:      "text/calendar",
+	FormatTodoTxt:  "text/plain",
+	FormatMarkdown: "text/markdown",
+}
+
+type (
+	ImportSummary = dto.ImportSummaryResponse
+	ImportRow     = dto.ImportRowResult
+)
+
+// ExportOptions selects the format and the todos of an export. An empty
+// Format exports CSV.
+type ExportOptions struct {
+	Format    string
+	Completed *bool
+	Search    string
+}
+
+// ImportOptions configures an import. An empty Format imports CSV. Mapping
+// maps todo fields (title, description, completed, created_at) to the CSV
+// headers holding them.
+type ImportOptions struct {
+	Format  string
+	DryRun  bool
+	Mapping map[string]string
+}
+
+// ExportTodos streams the todos matching opts in the requested format. The
+// caller must close the returned reader.
+func (c *Client) ExportTodos(ctx context.Context, opts ExportOptions) (io.ReadCloser, error) {
+	query := filterQuery(opts.Completed, opts.Search)
+	if opts.Format != "" {
+		query.Set("format", opts.Format)
+	}
+
+	return c.stream(ctx, c.newRequest(http.MethodGet, "/todos/export", query))
+}
+
+// CalendarFeed streams the todos matching completed and search as an
+// iCalendar feed. The caller must close the returned reader.
+func (c *Client) CalendarFeed(ctx context.Context, completed *bool, search string) (io.ReadCloser, error) {
+	return c.stream(ctx, c.newRequest(http.MethodGet, "/todos/calendar.ics", filterQuery(completed, search)))
+}
+
+// ImportTodos uploads body and reports the outcome per row. The request is
+// only retried when body implements io.Seeker and the server answers 429.
+func (c *Client) ImportTodos(ctx context.Context, body io.Reader, opts ImportOptions) (*ImportSummary, error) {
+	if body == nil {
+		return nil, errNilBody
+	}
+
+	query := url.Values{}
+	if opts.Format != "" {
+		query.Set("format", opts.Format)
+	}
+	if opts.DryRun {
+		query.Set("dry_run", "true")
+	}
+	for field, header := range opts.Mapping {
+		query.Set("mapping["+field+"]", header)
+	}
+
+	r := c.newRequest(http.MethodPost, "/todos/import", query)
+	r.stream = body
+	r.contentType = "application/octet-stream"
+	if contentType, ok := formatContentTypes[opts.Format]; ok {
+		r.contentType = contentType
+	}
+
+	var summary ImportSummary
+	if err := c.do(ctx, r, &summary); err != nil {
+		return nil, err
+	}
+
+	return &summary, nil
+}
+
+// stream sends the request and returns the response body unread.
+func (c *Client) stream(ctx context.Context, r *request) (io.ReadCloser, error) {
+	r.accept = ""
+
+	resp, err := c.send(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+func filterQuery(completed *bool, search string) url.Values {
+	query := url.Values{}
+	if completed != nil {
+		query.Set("completed", strconv.FormatBool(*completed))
+	}
+	if search != "" {
+		query.Set("search", search)
+	}
+
+	return query
+}